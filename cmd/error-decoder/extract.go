@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// base64Token 日志中可能是错误ID的base64片段，标准和URL安全的编码都匹配
+var base64Token = regexp.MustCompile(`[A-Za-z0-9+/_-]{20,}={0,2}`)
+
+// extractMatch 从日志中提取到的一个错误ID
+type extractMatch struct {
+	LineNo int
+	Line   string
+	ID     string
+	Info   *ErrorInfo
+}
+
+// extractErrorIDs 逐行扫描任意文本，返回能成功解码为错误ID的base64片段
+func extractErrorIDs(r io.Reader) ([]extractMatch, error) {
+	var matches []extractMatch
+	scanner := bufio.NewScanner(r)
+	// 日志行可能很长，放宽单行长度限制
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		for _, token := range base64Token.FindAllString(line, -1) {
+			info, err := parseErrorID(token)
+			if err != nil || !activeFilter.keep(info) {
+				continue
+			}
+			matches = append(matches, extractMatch{LineNo: lineNo, Line: line, ID: token, Info: info})
+		}
+	}
+	return matches, scanner.Err()
+}
+
+// processExtract 从日志文本中提取错误ID，输出所在行和解码结果
+func processExtract(r io.Reader, w io.Writer) error {
+	matches, err := extractErrorIDs(r)
+	if err != nil {
+		return fmt.Errorf("读取输入失败: %w", err)
+	}
+
+	color := func(c, text string) string {
+		if *flagNoColor {
+			return text
+		}
+		return c + text + ColorReset
+	}
+
+	for i, m := range matches {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s %s\n", color(ColorYellow, fmt.Sprintf("第 %d 行:", m.LineNo)), strings.TrimSpace(m.Line))
+		if *flagJSON {
+			outputJSON(w, m.Info)
+		} else {
+			outputFormatted(w, m.Info)
+		}
+	}
+	fmt.Fprintf(w, "\n%s\n", color(ColorGreen, fmt.Sprintf("✅ 共找到 %d 个错误ID", len(matches))))
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+)
+
+func TestExtractErrorIDs(t *testing.T) {
+	urlSafe := errors.New(404, "USER_NOT_FOUND", "用户不存在").ID
+	legacy := encodeID("api/order.List", "order.go", 2)
+	logs := strings.Join([]string{
+		"2024/01/02 15:04:05 [INFO] server started on :8080",
+		"2024/01/02 15:04:06 [WARN] gRPC unary error [ID: " + urlSafe + "]: user not found method=/user.v1.User/Get",
+		"2024/01/02 15:04:07 [INFO] GET /api/v1/users/aGVsbG8gd29ybGQgdGhpcyBpcyBub3QgYW4gaWQ= 200",
+		"2024/01/02 15:04:08 [ERROR] HTTP error error_id=" + legacy + " code=500 reason=INTERNAL",
+	}, "\n")
+
+	matches, err := extractErrorIDs(strings.NewReader(logs))
+	if err != nil {
+		t.Fatalf("提取失败: %v", err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("应该提取到2个错误ID，实际: %+v", matches)
+	}
+	if matches[0].ID != urlSafe || matches[0].LineNo != 2 || matches[0].Info.Function != "TestExtractErrorIDs" {
+		t.Errorf("第一个匹配不正确，实际: %+v", matches[0])
+	}
+	if matches[1].ID != legacy || matches[1].LineNo != 4 || matches[1].Info.File != "order.go" {
+		t.Errorf("第二个匹配不正确，实际: %+v", matches[1])
+	}
+
+	*flagNoColor = true
+	t.Cleanup(func() { *flagNoColor = false })
+	var buf bytes.Buffer
+	if err := processExtract(strings.NewReader(logs), &buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "第 2 行: 2024/01/02 15:04:06 [WARN] gRPC unary error") || !strings.Contains(out, "共找到 2 个错误ID") {
+		t.Errorf("输出应该包含所在行和统计，实际:\n%s", out)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// entryFilter 批量解析时按时间窗口和函数名过滤错误ID，所有条件同时满足才保留
+type entryFilter struct {
+	since, until time.Time
+	funcRe       *regexp.Regexp
+}
+
+// activeFilter 由命令行参数构建的过滤器，nil 表示不过滤
+var activeFilter *entryFilter
+
+// newEntryFilter 解析 -since、-until 和 -func-grep，全部为空时返回 nil
+func newEntryFilter(since, until, funcGrep string, now time.Time) (*entryFilter, error) {
+	if since == "" && until == "" && funcGrep == "" {
+		return nil, nil
+	}
+	f := &entryFilter{}
+	var err error
+	if since != "" {
+		if f.since, err = parseTimeBound(since, now); err != nil {
+			return nil, fmt.Errorf("无效的 -since: %w", err)
+		}
+	}
+	if until != "" {
+		if f.until, err = parseTimeBound(until, now); err != nil {
+			return nil, fmt.Errorf("无效的 -until: %w", err)
+		}
+	}
+	if !f.since.IsZero() && !f.until.IsZero() && !f.since.Before(f.until) {
+		return nil, fmt.Errorf("-since (%s) 必须早于 -until (%s)", f.since.Format(time.RFC3339), f.until.Format(time.RFC3339))
+	}
+	if funcGrep != "" {
+		if f.funcRe, err = regexp.Compile(funcGrep); err != nil {
+			return nil, fmt.Errorf("无效的 -func-grep: %w", err)
+		}
+	}
+	return f, nil
+}
+
+// parseTimeBound 解析 RFC3339 时间或相对于 now 的时长，例如 -1h、-30m
+func parseTimeBound(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil && strings.HasPrefix(s, "-") {
+		return now.Add(d), nil
+	}
+	return time.Time{}, fmt.Errorf("%q 既不是 RFC3339 时间也不是相对时长 (如 -1h)", s)
+}
+
+// keep 判断解码后的错误ID是否满足过滤条件：时间窗口为 [since, until)，
+// 函数名按 包名.函数名 匹配，备用ID没有函数名，设置 -func-grep 时不会保留
+func (f *entryFilter) keep(info *ErrorInfo) bool {
+	if f == nil {
+		return true
+	}
+	ts := time.Unix(0, info.Timestamp)
+	if !f.since.IsZero() && ts.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && !ts.Before(f.until) {
+		return false
+	}
+	if f.funcRe != nil && (!info.isFull() || !f.funcRe.MatchString(info.Package+"."+info.Function)) {
+		return false
+	}
+	return true
+}
+
+// keepID 解码错误ID并判断是否保留，无法解析的ID总是保留，以便照常报告错误
+func (f *entryFilter) keepID(id string) bool {
+	if f == nil {
+		return true
+	}
+	info, err := parseErrorID(id)
+	return err != nil || f.keep(info)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// encodeIDAt 构造指定函数和时间的错误ID
+func encodeIDAt(function string, ts time.Time) string {
+	raw := "v1:" + function + "@svc.go:10:" + strconv.FormatInt(ts.UnixNano(), 10) + ":1:100:abcd"
+	return base64.StdEncoding.EncodeToString([]byte(raw))
+}
+
+func TestEntryFilterTimeWindowBoundaries(t *testing.T) {
+	since := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	until := since.Add(time.Hour)
+	f, err := newEntryFilter(since.Format(time.RFC3339), until.Format(time.RFC3339), "", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		ts   time.Time
+		want bool
+	}{
+		{"早于since", since.Add(-time.Nanosecond), false},
+		{"等于since", since, true},
+		{"窗口内", since.Add(30 * time.Minute), true},
+		{"早于until一纳秒", until.Add(-time.Nanosecond), true},
+		{"等于until", until, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := parseErrorID(encodeIDAt("api/user.GetUser", tt.ts))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := f.keep(info); got != tt.want {
+				t.Errorf("时间 %s 的保留结果应该是 %v，实际: %v", tt.ts.Format(time.RFC3339Nano), tt.want, got)
+			}
+		})
+	}
+}
+
+func TestEntryFilterRelativeAndFunc(t *testing.T) {
+	now := time.Date(2024, 1, 2, 16, 0, 0, 0, time.UTC)
+	f, err := newEntryFilter("-1h", "", `^api/user\.`, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.since.Equal(now.Add(-time.Hour)) {
+		t.Errorf("-1h 应该解析为一小时前，实际: %v", f.since)
+	}
+
+	for _, tt := range []struct {
+		id   string
+		want bool
+	}{
+		{encodeIDAt("api/user.GetUser", now.Add(-time.Minute)), true},
+		{encodeIDAt("api/order.List", now.Add(-time.Minute)), false},
+		{encodeIDAt("api/user.GetUser", now.Add(-2*time.Hour)), false},
+		{"not-an-id!", true},
+	} {
+		if got := f.keepID(tt.id); got != tt.want {
+			t.Errorf("ID %s 的保留结果应该是 %v，实际: %v", tt.id, tt.want, got)
+		}
+	}
+
+	for _, args := range [][3]string{
+		{"yesterday", "", ""},
+		{"1h", "", ""},
+		{"", "", "("},
+		{"2024-01-02T16:00:00Z", "2024-01-02T15:00:00Z", ""},
+	} {
+		if _, err := newEntryFilter(args[0], args[1], args[2], now); err == nil {
+			t.Errorf("参数 %v 应该返回错误", args)
+		}
+	}
+	if f, err := newEntryFilter("", "", "", now); f != nil || err != nil {
+		t.Errorf("没有过滤条件时应该返回nil，实际: %v, %v", f, err)
+	}
+}
+
+func TestProcessTableWithFilter(t *testing.T) {
+	now := time.Now()
+	filter, err := newEntryFilter("", "", "GetUser", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	activeFilter = filter
+	t.Cleanup(func() { activeFilter = nil })
+
+	input := strings.Join([]string{
+		encodeIDAt("api/user.GetUser", now),
+		encodeIDAt("api/order.List", now),
+	}, "\n")
+	var buf bytes.Buffer
+	if err := processTable(strings.NewReader(input), &buf, "csv"); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 2 || !strings.Contains(lines[1], "GetUser") {
+		t.Errorf("应该只输出匹配的行，实际:\n%s", buf.String())
+	}
+}
+
+func TestConfigureFilterBatchModes(t *testing.T) {
+	*flagNoColor = true
+	*flagFuncGrep = "GetUser"
+	t.Cleanup(func() {
+		*flagNoColor = false
+		*flagFuncGrep = ""
+		*flagSince = ""
+		activeFilter = nil
+	})
+	if err := configure(time.Now()); err != nil {
+		t.Fatalf("设置过滤器失败: %v", err)
+	}
+
+	now := time.Now()
+	input := strings.Join([]string{
+		encodeIDAt("api/user.GetUser", now),
+		encodeIDAt("api/order.List", now),
+	}, "\n")
+	for name, run := range map[string]func(*bytes.Buffer) error{
+		"timeline": func(buf *bytes.Buffer) error { return processTimeline(strings.NewReader(input), buf, "ascii") },
+		"group-by": func(buf *bytes.Buffer) error { return processGroupBy(strings.NewReader(input), buf, "func") },
+	} {
+		var buf bytes.Buffer
+		if err := run(&buf); err != nil {
+			t.Fatalf("%s 输出失败: %v", name, err)
+		}
+		if out := buf.String(); !strings.Contains(out, "GetUser") || strings.Contains(out, "api/order.List") || !strings.Contains(out, "1 个错误ID") {
+			t.Errorf("%s 应该只包含匹配过滤条件的ID，实际:\n%s", name, out)
+		}
+	}
+
+	*flagSince = "yesterday"
+	if err := configure(time.Now()); err == nil {
+		t.Error("无效的 -since 应该返回错误")
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// unparseableBucket 无法解析的错误ID归入的分组
+const unparseableBucket = "(无法解析)"
+
+// histogramEntry 直方图中的一个分组
+type histogramEntry struct {
+	Key   string
+	Count int
+}
+
+// groupErrorIDs 按函数或文件统计错误ID，按数量降序排列，数量相同时按名称排序
+func groupErrorIDs(ids []string, by string) ([]histogramEntry, error) {
+	if by != "func" && by != "file" {
+		return nil, fmt.Errorf("未知的分组方式 %q，可选: func, file", by)
+	}
+
+	counts := make(map[string]int)
+	for _, id := range ids {
+		info, err := parseErrorID(id)
+		if err != nil {
+			counts[unparseableBucket]++
+			continue
+		}
+		key := entryName(info)
+		if by == "file" && info.isFull() {
+			key = info.File
+		}
+		counts[key]++
+	}
+
+	entries := make([]histogramEntry, 0, len(counts))
+	for key, count := range counts {
+		entries = append(entries, histogramEntry{Key: key, Count: count})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count != entries[j].Count {
+			return entries[i].Count > entries[j].Count
+		}
+		return entries[i].Key < entries[j].Key
+	})
+	return entries, nil
+}
+
+// processGroupBy 读取一批错误ID，按函数或文件输出数量直方图
+func processGroupBy(r io.Reader, w io.Writer, by string) error {
+	ids, err := readErrorIDs(r)
+	if err != nil {
+		return fmt.Errorf("读取输入失败: %w", err)
+	}
+	entries, err := groupErrorIDs(ids, by)
+	if err != nil {
+		return err
+	}
+
+	color := func(c, text string) string {
+		if *flagNoColor {
+			return text
+		}
+		return c + text + ColorReset
+	}
+
+	title := "函数"
+	if by == "file" {
+		title = "文件"
+	}
+	fmt.Fprintf(w, "%s\n", color(ColorBold+ColorCyan, fmt.Sprintf("📊 按%s统计 (%d 个错误ID)", title, len(ids))))
+	fmt.Fprintf(w, "%s\n", strings.Repeat("=", 50))
+
+	maxCount := 0
+	for _, entry := range entries {
+		maxCount = max(maxCount, entry.Count)
+	}
+	for _, entry := range entries {
+		c := ColorGreen
+		if entry.Key == unparseableBucket {
+			c = ColorRed
+		}
+		bar := strings.Repeat("█", (entry.Count*30+maxCount-1)/maxCount)
+		fmt.Fprintf(w, "%6d  %s %s\n", entry.Count, color(ColorYellow, bar), color(c, entry.Key))
+	}
+	return nil
+}
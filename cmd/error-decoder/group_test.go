@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+// encodeID 构造指定函数和文件的错误ID
+func encodeID(function, file string, ts int) string {
+	raw := function + "@" + file + ":10:" + strings.Repeat("1", ts) + ":1:100:abcd"
+	return base64.StdEncoding.EncodeToString([]byte("v1:" + raw))
+}
+
+func TestGroupErrorIDsByFunc(t *testing.T) {
+	ids := []string{
+		encodeID("api/user.GetUser", "user.go", 1),
+		encodeID("api/order.List", "order.go", 2),
+		encodeID("api/user.GetUser", "user.go", 3),
+		encodeID("api/user.GetUser", "user.go", 4),
+		"not-an-id!",
+	}
+
+	entries, err := groupErrorIDs(ids, "func")
+	if err != nil {
+		t.Fatalf("分组失败: %v", err)
+	}
+	want := []histogramEntry{
+		{Key: "api/user.GetUser", Count: 3},
+		{Key: unparseableBucket, Count: 1},
+		{Key: "api/order.List", Count: 1},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("分组数量应该是 %d，实际: %v", len(want), entries)
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("第%d个分组应该是 %v，实际: %v", i, want[i], entries[i])
+		}
+	}
+
+	*flagNoColor = true
+	t.Cleanup(func() { *flagNoColor = false })
+	var buf bytes.Buffer
+	if err := processGroupBy(strings.NewReader(strings.Join(ids, "\n")), &buf, "file"); err != nil {
+		t.Fatalf("输出直方图失败: %v", err)
+	}
+	if !strings.Contains(buf.String(), "user.go") || !strings.Contains(buf.String(), "5 个错误ID") {
+		t.Errorf("按文件分组的输出不正确:\n%s", buf.String())
+	}
+
+	if _, err := groupErrorIDs(ids, "line"); err == nil {
+		t.Error("未知的分组方式应该返回错误")
+	}
+}
@@ -2,8 +2,10 @@ package main
 
 import (
 	"encoding/json"
+	stderrors "errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
@@ -35,20 +37,67 @@ type ErrorInfo struct {
 	ProcessID   int    `json:"process_id"`
 	Random      string `json:"random"`
 	HumanTime   string `json:"human_time"`
+	Version     int    `json:"version"`
+	IsFallback  bool   `json:"is_fallback"`
+	NoCaller    bool   `json:"no_caller,omitempty"`
+	IsCompact   bool   `json:"is_compact,omitempty"`
+	BuildID     string `json:"build_id,omitempty"`
+	TraceID     string `json:"trace_id,omitempty"`
+	InstanceID  string `json:"instance_id,omitempty"`
+	Extra       string `json:"extra,omitempty"`
 	Raw         string `json:"raw"`
+
+	age time.Duration // 解码时距错误ID生成的时长
+}
+
+// isFull 判断是否为包含调用位置等调试字段的完整ID，备用ID和精简ID不包含这些字段
+func (info *ErrorInfo) isFull() bool {
+	return !info.IsFallback && !info.IsCompact
 }
 
 var (
-	flagJSON    = flag.Bool("json", false, "输出JSON格式")
-	flagNoColor = flag.Bool("no-color", false, "禁用颜色输出")
-	flagHelp    = flag.Bool("h", false, "显示帮助信息")
-	flagVersion = flag.Bool("version", false, "显示版本信息")
-	flagBatch   = flag.Bool("batch", false, "批量模式，从stdin读取多个错误ID")
-	flagVerbose = flag.Bool("v", false, "详细输出模式")
+	flagJSON     = flag.Bool("json", false, "输出JSON格式")
+	flagNoColor  = flag.Bool("no-color", false, "禁用颜色输出")
+	flagHelp     = flag.Bool("h", false, "显示帮助信息")
+	flagVersion  = flag.Bool("version", false, "显示版本信息")
+	flagBatch    = flag.Bool("batch", false, "批量模式，从stdin读取多个错误ID")
+	flagVerbose  = flag.Bool("v", false, "详细输出模式")
+	flagTimeline = flag.String("timeline", "", "批量模式下按时间线输出: ascii 或 dot")
+	flagGroupBy  = flag.String("group-by", "", "批量模式下按函数或文件统计: func 或 file")
+	flagCSV      = flag.Bool("csv", false, "以CSV格式输出，每个错误ID一行")
+	flagTSV      = flag.Bool("tsv", false, "以TSV格式输出，每个错误ID一行")
+	flagExtract  = flag.Bool("extract", false, "从stdin读取任意日志文本，提取并解析其中的错误ID")
+	flagSince    = flag.String("since", "", "只保留该时间之后的错误ID: RFC3339 或相对时长 (如 -1h)")
+	flagUntil    = flag.String("until", "", "只保留该时间之前的错误ID: RFC3339 或相对时长 (如 -10m)")
+	flagFuncGrep = flag.String("func-grep", "", "只保留函数名匹配该正则的错误ID")
+	flagTZ       = flag.String("tz", "UTC", "显示时间使用的时区: IANA 名称 (如 Asia/Shanghai) 或 UTC、Local")
 )
 
 const version = "v1.0.0"
 
+// displayLocation 显示时间使用的时区，由 -tz 设置，默认 UTC 以保证输出可复现
+var displayLocation = time.UTC
+
+// 构建时通过 -ldflags 注入，例如:
+//
+//	go build -ldflags "-X main.gitCommit=$(git rev-parse --short HEAD) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var gitCommit, buildDate string
+
+// versionString 返回版本信息，包含构建时注入的提交和构建时间
+func versionString() string {
+	v := "error-decoder " + version
+	if gitCommit != "" {
+		v += " (commit " + gitCommit
+		if buildDate != "" {
+			v += ", built " + buildDate
+		}
+		v += ")"
+	} else if buildDate != "" {
+		v += " (built " + buildDate + ")"
+	}
+	return v
+}
+
 func main() {
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, `%s错误ID解析工具%s %s%s%s
@@ -62,6 +111,16 @@ func main() {
   %s-no-color%s    禁用颜色输出  
   %s-batch%s       批量模式，从stdin读取
   %s-v%s           详细输出模式
+  %s-timeline%s    批量模式下输出时间线 (ascii 或 dot)
+  %s-group-by%s    批量模式下按函数或文件统计数量 (func 或 file)
+  %s-csv%s         以CSV格式输出，每个错误ID一行，便于导入表格
+  %s-tsv%s         以TSV格式输出，便于 awk 等工具处理
+  %s-extract%s     从stdin读取日志文本，提取其中的错误ID并显示所在行
+  %s-since%s       只保留该时间及之后的错误ID (RFC3339 或 -1h 这样的相对时长)
+  %s-until%s       只保留该时间之前的错误ID (不含该时间)
+  %s-func-grep%s   只保留 包名.函数名 匹配该正则的错误ID
+               多个过滤条件同时生效，必须全部满足；无法解析的ID不参与过滤
+  %s-tz%s          显示时间使用的时区，默认 UTC (如 Asia/Shanghai、Local)
   %s-h%s           显示此帮助信息
   %s-version%s     显示版本信息
 
@@ -75,6 +134,21 @@ func main() {
   %s# 批量解析%s
   %secho -e "ID1\nID2\nID3" | ./error-decoder -batch%s
 
+  %s# 按时间线查看一批错误 (DOT 可交给 graphviz 渲染)%s
+  %scat ids.txt | ./error-decoder -batch -timeline dot | dot -Tsvg > errors.svg%s
+
+  %s# 统计哪些函数产生的错误最多%s
+  %scat ids.txt | ./error-decoder -batch -group-by func%s
+
+  %s# 导出为CSV，无法解析的ID在 error 列中说明原因%s
+  %scat ids.txt | ./error-decoder -batch -csv > errors.csv%s
+
+  %s# 直接扫描日志文件中的错误ID%s
+  %sgrep "gRPC unary error" app.log | ./error-decoder -extract%s
+
+  %s# 导出最近一小时内 user 包产生的错误%s
+  %scat ids.txt | ./error-decoder -batch -csv -since -1h -func-grep '^api/user\.'%s
+
 `,
 			ColorBold+ColorCyan, ColorReset, ColorYellow, version, ColorReset,
 			ColorBold, ColorReset,
@@ -87,6 +161,15 @@ func main() {
 			ColorYellow, ColorReset,
 			ColorYellow, ColorReset,
 			ColorYellow, ColorReset,
+			ColorYellow, ColorReset,
+			ColorYellow, ColorReset,
+			ColorYellow, ColorReset,
+			ColorYellow, ColorReset,
+			ColorYellow, ColorReset,
+			ColorYellow, ColorReset,
+			ColorYellow, ColorReset,
+			ColorYellow, ColorReset,
+			ColorYellow, ColorReset,
 			ColorBold, ColorReset,
 			ColorCyan, ColorReset,
 			ColorGreen, ColorReset,
@@ -94,18 +177,75 @@ func main() {
 			ColorGreen, ColorReset,
 			ColorCyan, ColorReset,
 			ColorGreen, ColorReset,
+			ColorCyan, ColorReset,
+			ColorGreen, ColorReset,
+			ColorCyan, ColorReset,
+			ColorGreen, ColorReset,
+			ColorCyan, ColorReset,
+			ColorGreen, ColorReset,
+			ColorCyan, ColorReset,
+			ColorGreen, ColorReset,
+			ColorCyan, ColorReset,
+			ColorGreen, ColorReset,
 		)
 	}
 
 	flag.Parse()
 
+	// 时区和过滤条件对所有模式生效，必须在分派到任何模式之前设置
+	if err := configure(time.Now()); err != nil {
+		fmt.Fprintf(os.Stderr, "%s错误: %v%s\n", ColorRed, err, ColorReset)
+		os.Exit(1)
+	}
+
 	if *flagHelp {
 		flag.Usage()
 		return
 	}
 
 	if *flagVersion {
-		fmt.Printf("error-decoder %s\n", version)
+		fmt.Println(versionString())
+		return
+	}
+
+	if *flagBatch && *flagTimeline != "" {
+		if err := processTimeline(os.Stdin, os.Stdout, *flagTimeline); err != nil {
+			fmt.Fprintf(os.Stderr, "%s错误: %v%s\n", ColorRed, err, ColorReset)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *flagBatch && *flagGroupBy != "" {
+		if err := processGroupBy(os.Stdin, os.Stdout, *flagGroupBy); err != nil {
+			fmt.Fprintf(os.Stderr, "%s错误: %v%s\n", ColorRed, err, ColorReset)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *flagExtract {
+		if err := processExtract(os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "%s错误: %v%s\n", ColorRed, err, ColorReset)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *flagCSV || *flagTSV {
+		format := "csv"
+		if *flagTSV {
+			format = "tsv"
+		}
+		// 批量模式从stdin读取，否则处理命令行参数中的错误ID
+		var input io.Reader = os.Stdin
+		if !*flagBatch {
+			input = strings.NewReader(strings.Join(flag.Args(), "\n"))
+		}
+		if err := processTable(input, os.Stdout, format); err != nil {
+			fmt.Fprintf(os.Stderr, "%s错误: %v%s\n", ColorRed, err, ColorReset)
+			os.Exit(1)
+		}
 		return
 	}
 
@@ -125,11 +265,29 @@ func main() {
 	processErrorID(errorID)
 }
 
+// configure 根据 -tz 设置 displayLocation，根据 -since、-until 和 -func-grep
+// 设置 activeFilter，参数无效时返回错误
+func configure(now time.Time) error {
+	loc, err := time.LoadLocation(*flagTZ)
+	if err != nil {
+		return fmt.Errorf("无效的时区 %q: %w", *flagTZ, err)
+	}
+	displayLocation = loc
+
+	filter, err := newEntryFilter(*flagSince, *flagUntil, *flagFuncGrep, now)
+	if err != nil {
+		return err
+	}
+	activeFilter = filter
+	return nil
+}
+
 func processBatch() {
 	fmt.Printf("%s🔍 批量解析模式 - 等待输入错误ID (每行一个，Ctrl+D结束)%s\n", ColorCyan, ColorReset)
 
+	// 先读取全部输入，再批量解码
+	var ids []string
 	var line string
-	count := 0
 	for {
 		n, err := fmt.Scanln(&line)
 		if err != nil || n == 0 {
@@ -140,10 +298,20 @@ func processBatch() {
 		if line == "" {
 			continue
 		}
+		ids = append(ids, line)
+	}
+
+	infos, errs := parseErrorIDs(ids)
+	count := 0
+	for i := range ids {
+		// 无法解析的ID不参与过滤，保留以便报告错误
+		if errs[i] == nil && !activeFilter.keep(infos[i]) {
+			continue
+		}
 
 		count++
 		fmt.Printf("\n%s=== 错误ID #%d ===%s\n", ColorYellow, count, ColorReset)
-		processErrorID(line)
+		printErrorInfo(infos[i], errs[i])
 	}
 
 	if count > 0 {
@@ -160,89 +328,93 @@ func processErrorID(errorID string) {
 		return
 	}
 
-	info, err := parseErrorID(errorID)
+	printErrorInfo(parseErrorID(errorID))
+}
+
+// printErrorInfo 按输出格式打印解析结果或解析错误
+func printErrorInfo(info *ErrorInfo, err error) {
 	if err != nil {
 		fmt.Printf("%s解析错误: %v%s\n", ColorRed, err, ColorReset)
 		return
 	}
 
 	if *flagJSON {
-		outputJSON(info)
+		outputJSON(os.Stdout, info)
 	} else {
-		outputFormatted(info)
+		outputFormatted(os.Stdout, info)
 	}
 }
 
 func parseErrorID(errorID string) (*ErrorInfo, error) {
 	// 使用我们的errors包解码
-	debugInfo, err := errors.DecodeErrorID(errorID)
-	if err != nil {
-		return nil, fmt.Errorf("无法解码错误ID: %w", err)
-	}
-
-	raw := debugInfo["raw"].(string)
+	return toErrorInfo(errors.DecodeErrorID(errorID))
+}
 
-	// 解析格式: pkg.func@file:line:timestamp:gid:pid:random
-	parts := strings.Split(raw, ":")
-	if len(parts) < 6 {
-		return nil, fmt.Errorf("错误ID格式不正确，期望至少6个部分，实际: %d", len(parts))
+// parseErrorIDs 使用 errors.DecodeErrorIDs 批量解析错误ID，结果与输入顺序一致
+func parseErrorIDs(ids []string) ([]*ErrorInfo, []error) {
+	debugInfos, errs := errors.DecodeErrorIDs(ids)
+	infos := make([]*ErrorInfo, len(ids))
+	for i := range ids {
+		infos[i], errs[i] = toErrorInfo(debugInfos[i], errs[i])
 	}
+	return infos, errs
+}
 
-	// 解析包名和函数名
-	funcPart := parts[0] // pkg.func@file
-	atIndex := strings.LastIndex(funcPart, "@")
-	if atIndex == -1 {
-		return nil, fmt.Errorf("无法找到@分隔符")
+// toErrorInfo 将解码结果转换为命令行输出使用的 ErrorInfo
+func toErrorInfo(debugInfo *errors.ErrorIDInfo, err error) (*ErrorInfo, error) {
+	if stderrors.Is(err, errors.ErrChecksumMismatch) {
+		return nil, fmt.Errorf("错误ID校验失败，ID可能在复制时被截断或修改: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("无法解码错误ID: %w", err)
 	}
 
-	pkgFunc := funcPart[:atIndex]
-	file := funcPart[atIndex+1:]
-
-	// 分离包名和函数名
-	lastDotIndex := strings.LastIndex(pkgFunc, ".")
+	// 分离包名和函数名，备用ID和没有调用位置的ID不包含调用位置
 	var pkg, function string
-	if lastDotIndex != -1 {
-		pkg = pkgFunc[:lastDotIndex]
-		function = pkgFunc[lastDotIndex+1:]
-	} else {
-		pkg = "main"
-		function = pkgFunc
+	if !debugInfo.IsFallback && !debugInfo.IsCompact && !debugInfo.NoCaller {
+		pkg, function = "main", debugInfo.Function
+		if lastDotIndex := strings.LastIndex(debugInfo.Function, "."); lastDotIndex != -1 {
+			pkg = debugInfo.Function[:lastDotIndex]
+			function = debugInfo.Function[lastDotIndex+1:]
+		}
 	}
 
-	// 解析数值
-	line, _ := strconv.Atoi(parts[1])
-	timestamp, _ := strconv.ParseInt(parts[2], 10, 64)
-	goroutineID, _ := strconv.ParseUint(parts[3], 10, 64)
-	processID, _ := strconv.Atoi(parts[4])
-	random := parts[5]
-
 	// 转换时间戳为人类可读格式
-	humanTime := time.Unix(0, timestamp).Format("2006-01-02 15:04:05.000000000")
+	humanTime := debugInfo.Time().In(displayLocation).Format("2006-01-02 15:04:05.000000000 MST")
 
 	return &ErrorInfo{
 		Package:     pkg,
 		Function:    function,
-		File:        file,
-		Line:        line,
-		Timestamp:   timestamp,
-		GoroutineID: goroutineID,
-		ProcessID:   processID,
-		Random:      random,
+		File:        debugInfo.File,
+		Line:        debugInfo.Line,
+		Timestamp:   debugInfo.Timestamp,
+		GoroutineID: debugInfo.GoroutineID,
+		ProcessID:   debugInfo.ProcessID,
+		Random:      debugInfo.RandomSuffix,
 		HumanTime:   humanTime,
-		Raw:         raw,
+		Version:     debugInfo.Version,
+		IsFallback:  debugInfo.IsFallback,
+		NoCaller:    debugInfo.NoCaller,
+		IsCompact:   debugInfo.IsCompact,
+		BuildID:     debugInfo.BuildID,
+		TraceID:     debugInfo.TraceID,
+		InstanceID:  debugInfo.InstanceID,
+		Extra:       debugInfo.Extra,
+		Raw:         debugInfo.Raw,
+		age:         debugInfo.Age(),
 	}, nil
 }
 
-func outputJSON(info *ErrorInfo) {
+func outputJSON(w io.Writer, info *ErrorInfo) {
 	data, err := json.MarshalIndent(info, "", "  ")
 	if err != nil {
-		fmt.Printf("%s生成JSON失败: %v%s\n", ColorRed, err, ColorReset)
+		fmt.Fprintf(w, "%s生成JSON失败: %v%s\n", ColorRed, err, ColorReset)
 		return
 	}
-	fmt.Println(string(data))
+	fmt.Fprintln(w, string(data))
 }
 
-func outputFormatted(info *ErrorInfo) {
+func outputFormatted(w io.Writer, info *ErrorInfo) {
 	// 选择颜色函数
 	color := func(c, text string) string {
 		if *flagNoColor {
@@ -251,48 +423,117 @@ func outputFormatted(info *ErrorInfo) {
 		return c + text + ColorReset
 	}
 
-	fmt.Printf("%s\n", color(ColorBold+ColorCyan, "🔍 错误ID解析结果"))
-	fmt.Printf("%s\n", strings.Repeat("=", 50))
-
-	fmt.Printf("%s %s\n",
-		color(ColorBold, "📦 包名:"),
-		color(ColorGreen, info.Package))
-
-	fmt.Printf("%s %s\n",
-		color(ColorBold, "🔧 函数:"),
-		color(ColorYellow, info.Function))
-
-	fmt.Printf("%s %s:%s\n",
-		color(ColorBold, "📄 位置:"),
-		color(ColorCyan, info.File),
-		color(ColorRed, strconv.Itoa(info.Line)))
+	fmt.Fprintf(w, "%s\n", color(ColorBold+ColorCyan, "🔍 错误ID解析结果"))
+	fmt.Fprintf(w, "%s\n", strings.Repeat("=", 50))
+
+	if info.IsFallback {
+		fmt.Fprintf(w, "%s %s\n",
+			color(ColorBold, "⚠️  类型:"),
+			color(ColorYellow, "备用ID (生成时未能获取调用位置)"))
+	} else if info.IsCompact {
+		fmt.Fprintf(w, "%s %s\n",
+			color(ColorBold, "ℹ️  类型:"),
+			color(ColorYellow, "精简ID (只包含时间和随机值)"))
+	} else if info.NoCaller {
+		fmt.Fprintf(w, "%s %s\n",
+			color(ColorBold, "⚠️  位置:"),
+			color(ColorYellow, "未知 (生成时跳过的栈帧超出了调用栈深度)"))
+	} else {
+		fmt.Fprintf(w, "%s %s\n",
+			color(ColorBold, "📦 包名:"),
+			color(ColorGreen, info.Package))
+
+		fmt.Fprintf(w, "%s %s\n",
+			color(ColorBold, "🔧 函数:"),
+			color(ColorYellow, info.Function))
+
+		fmt.Fprintf(w, "%s %s:%s\n",
+			color(ColorBold, "📄 位置:"),
+			color(ColorCyan, info.File),
+			color(ColorRed, strconv.Itoa(info.Line)))
+	}
 
-	fmt.Printf("%s %s\n",
+	fmt.Fprintf(w, "%s %s\n",
 		color(ColorBold, "⏰ 时间:"),
 		color(ColorPurple, info.HumanTime))
 
-	fmt.Printf("%s %s\n",
-		color(ColorBold, "🧵 协程ID:"),
-		color(ColorBlue, strconv.FormatUint(info.GoroutineID, 10)))
+	if info.isFull() {
+		fmt.Fprintf(w, "%s %s\n",
+			color(ColorBold, "🧵 协程ID:"),
+			color(ColorBlue, strconv.FormatUint(info.GoroutineID, 10)))
+	}
 
-	fmt.Printf("%s %s\n",
-		color(ColorBold, "🆔 进程ID:"),
-		color(ColorBlue, strconv.Itoa(info.ProcessID)))
+	if !info.IsCompact {
+		fmt.Fprintf(w, "%s %s\n",
+			color(ColorBold, "🆔 进程ID:"),
+			color(ColorBlue, strconv.Itoa(info.ProcessID)))
+	}
 
-	fmt.Printf("%s %s\n",
+	fmt.Fprintf(w, "%s %s\n",
 		color(ColorBold, "🎲 随机值:"),
 		color(ColorWhite, info.Random))
 
+	if info.BuildID != "" {
+		fmt.Fprintf(w, "%s %s\n",
+			color(ColorBold, "🏗️  构建:"),
+			color(ColorGreen, info.BuildID))
+	}
+
+	if info.InstanceID != "" {
+		fmt.Fprintf(w, "%s %s\n",
+			color(ColorBold, "🖥️  实例:"),
+			color(ColorGreen, info.InstanceID))
+	}
+
+	if info.TraceID != "" {
+		fmt.Fprintf(w, "%s %s\n",
+			color(ColorBold, "🔗 追踪ID:"),
+			color(ColorGreen, info.TraceID))
+	}
+
+	if info.Extra != "" {
+		fmt.Fprintf(w, "%s %s\n",
+			color(ColorBold, "➕ 附加字段:"),
+			color(ColorWhite, info.Extra))
+	}
+
 	if *flagVerbose {
-		fmt.Printf("\n%s\n", color(ColorBold, "📋 详细信息:"))
-		fmt.Printf("%s %d\n",
+		fmt.Fprintf(w, "\n%s\n", color(ColorBold, "📋 详细信息:"))
+		fmt.Fprintf(w, "%s %d\n",
 			color(ColorBold, "  • 纳秒时间戳:"),
 			info.Timestamp)
-		fmt.Printf("%s %s\n",
+		fmt.Fprintf(w, "%s %s\n",
+			color(ColorBold, "  • 距今:"),
+			formatAge(info.age))
+		fmt.Fprintf(w, "%s %d\n",
+			color(ColorBold, "  • 格式版本:"),
+			info.Version)
+		fmt.Fprintf(w, "%s %s\n",
 			color(ColorBold, "  • 原始数据:"),
 			color(ColorWhite, info.Raw))
 	}
 
-	fmt.Printf("\n%s\n",
+	fmt.Fprintf(w, "\n%s\n",
 		color(ColorGreen+ColorBold, "✅ 解析完成!"))
 }
+
+// formatAge 将时长格式化为 "3分钟前" 形式，只保留最大的单位；
+// 生成ID的主机时钟较快时时长为负，显示为之后的时间
+func formatAge(d time.Duration) string {
+	suffix := "前"
+	if d < 0 {
+		d, suffix = -d, "后"
+	}
+	switch {
+	case d < time.Second:
+		return "刚刚"
+	case d < time.Minute:
+		return fmt.Sprintf("%d秒%s", int(d/time.Second), suffix)
+	case d < time.Hour:
+		return fmt.Sprintf("%d分钟%s", int(d/time.Minute), suffix)
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%d小时%s", int(d/time.Hour), suffix)
+	default:
+		return fmt.Sprintf("%d天%s", int(d/(24*time.Hour)), suffix)
+	}
+}
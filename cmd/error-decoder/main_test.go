@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	stderrors "errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+)
+
+func TestBuildIDDisplay(t *testing.T) {
+	id := base64.StdEncoding.EncodeToString([]byte("v1:GetUser@user_logic.go:25:1640995200123456789:1:12345:a1b2c3d4:b=3f2c1ab"))
+
+	info, err := parseErrorID(id)
+	if err != nil {
+		t.Fatalf("解析错误ID失败: %v", err)
+	}
+	if info.BuildID != "3f2c1ab" {
+		t.Errorf("应该解析出构建标识，实际: %q", info.BuildID)
+	}
+
+	*flagNoColor = true
+	t.Cleanup(func() { *flagNoColor = false })
+
+	var buf bytes.Buffer
+	outputFormatted(&buf, info)
+	if !strings.Contains(buf.String(), "构建: 3f2c1ab") {
+		t.Errorf("输出应该包含构建标识，实际:\n%s", buf.String())
+	}
+}
+
+func TestTraceIDDisplay(t *testing.T) {
+	id := base64.StdEncoding.EncodeToString([]byte("v1:GetUser@user_logic.go:25:1640995200123456789:1:12345:a1b2c3d4:t=4bf92f3577b34da6a3ce929d0e0e4736"))
+
+	info, err := parseErrorID(id)
+	if err != nil {
+		t.Fatalf("解析错误ID失败: %v", err)
+	}
+
+	*flagNoColor = true
+	t.Cleanup(func() { *flagNoColor = false })
+
+	var buf bytes.Buffer
+	outputFormatted(&buf, info)
+	if !strings.Contains(buf.String(), "追踪ID: 4bf92f3577b34da6a3ce929d0e0e4736") {
+		t.Errorf("输出应该包含追踪ID，实际:\n%s", buf.String())
+	}
+}
+
+func TestVersionString(t *testing.T) {
+	if got := versionString(); got != "error-decoder "+version {
+		t.Errorf("未注入构建信息时版本信息不正确: %s", got)
+	}
+
+	gitCommit, buildDate = "3f2c1ab", "2025-06-01T00:00:00Z"
+	t.Cleanup(func() { gitCommit, buildDate = "", "" })
+
+	if got, want := versionString(), "error-decoder "+version+" (commit 3f2c1ab, built 2025-06-01T00:00:00Z)"; got != want {
+		t.Errorf("版本信息不正确\n期望: %s\n实际: %s", want, got)
+	}
+}
+
+func TestChecksumMismatchMessage(t *testing.T) {
+	id := errors.New(404, "NOT_FOUND", "不存在").ID
+	_, err := parseErrorID(id[:len(id)-4])
+	if !stderrors.Is(err, errors.ErrChecksumMismatch) || !strings.Contains(err.Error(), "截断") {
+		t.Errorf("被截断的ID应该提示校验失败，实际: %v", err)
+	}
+}
+
+func TestInstanceIDDisplay(t *testing.T) {
+	id := base64.StdEncoding.EncodeToString([]byte("v1:GetUser@user_logic.go:25:1640995200123456789:1:12345:a1b2c3d4:h=node-3"))
+
+	info, err := parseErrorID(id)
+	if err != nil {
+		t.Fatalf("解析错误ID失败: %v", err)
+	}
+
+	*flagNoColor = true
+	t.Cleanup(func() { *flagNoColor = false })
+
+	var buf bytes.Buffer
+	outputFormatted(&buf, info)
+	if !strings.Contains(buf.String(), "实例: node-3") {
+		t.Errorf("输出应该包含实例标识，实际:\n%s", buf.String())
+	}
+}
+
+func TestHumanTimeZone(t *testing.T) {
+	id := base64.StdEncoding.EncodeToString([]byte("v1:GetUser@user_logic.go:25:1640995200123456789:1:12345:a1b2c3d4"))
+
+	info, err := parseErrorID(id)
+	if err != nil {
+		t.Fatalf("解析错误ID失败: %v", err)
+	}
+	if want := "2022-01-01 00:00:00.123456789 UTC"; info.HumanTime != want {
+		t.Errorf("默认应该使用UTC显示时间 %q，实际: %q", want, info.HumanTime)
+	}
+
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("系统缺少时区数据: %v", err)
+	}
+	displayLocation = loc
+	t.Cleanup(func() { displayLocation = time.UTC })
+
+	info, err = parseErrorID(id)
+	if err != nil {
+		t.Fatalf("解析错误ID失败: %v", err)
+	}
+	if want := "2022-01-01 08:00:00.123456789 CST"; info.HumanTime != want {
+		t.Errorf("Asia/Shanghai 时区应该显示 %q，实际: %q", want, info.HumanTime)
+	}
+}
+
+func TestConfigureTimeZone(t *testing.T) {
+	if _, err := time.LoadLocation("Asia/Shanghai"); err != nil {
+		t.Skipf("系统缺少时区数据: %v", err)
+	}
+	*flagNoColor = true
+	t.Cleanup(func() {
+		*flagNoColor = false
+		*flagTZ = "UTC"
+		displayLocation = time.UTC
+	})
+
+	// -tz 同样作用于时间线等批量模式
+	*flagTZ = "Asia/Shanghai"
+	if err := configure(time.Now()); err != nil {
+		t.Fatalf("设置时区失败: %v", err)
+	}
+	id := base64.StdEncoding.EncodeToString([]byte("v1:GetUser@user_logic.go:25:1640995200123456789:1:12345:a1b2c3d4"))
+	var buf bytes.Buffer
+	if err := processTimeline(strings.NewReader(id), &buf, "ascii"); err != nil {
+		t.Fatalf("输出时间线失败: %v", err)
+	}
+	if !strings.Contains(buf.String(), "2022-01-01 08:00:00.123456789 CST") {
+		t.Errorf("时间线应该使用 -tz 指定的时区，实际:\n%s", buf.String())
+	}
+
+	*flagTZ = "Bogus/Zone"
+	if err := configure(time.Now()); err == nil || !strings.Contains(err.Error(), "Bogus/Zone") {
+		t.Errorf("无效的时区应该返回包含时区名称的错误，实际: %v", err)
+	}
+}
+
+func TestParseErrorIDMatchesDecodeErrorID(t *testing.T) {
+	id := errors.New(404, "NOT_FOUND", "资源未找到").ID
+
+	decoded, err := errors.DecodeErrorID(id)
+	if err != nil {
+		t.Fatalf("解码错误ID失败: %v", err)
+	}
+	info, err := parseErrorID(id)
+	if err != nil {
+		t.Fatalf("解析错误ID失败: %v", err)
+	}
+
+	if info.Function != "TestParseErrorIDMatchesDecodeErrorID" || info.Package != "main" {
+		t.Errorf("CLI应该拆分出包名和函数名，实际: %s.%s", info.Package, info.Function)
+	}
+	if info.File != decoded.File || info.Line != decoded.Line || info.Timestamp != decoded.Timestamp ||
+		info.GoroutineID != decoded.GoroutineID || info.ProcessID != decoded.ProcessID ||
+		info.Random != decoded.RandomSuffix || info.Version != decoded.Version || info.Raw != decoded.Raw {
+		t.Errorf("CLI的字段应该与 DecodeErrorID 一致，CLI: %+v，DecodeErrorID: %+v", info, decoded)
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		age  time.Duration
+		want string
+	}{
+		{500 * time.Millisecond, "刚刚"},
+		{42 * time.Second, "42秒前"},
+		{3*time.Minute + 20*time.Second, "3分钟前"},
+		{5 * time.Hour, "5小时前"},
+		{50 * time.Hour, "2天前"},
+		{-2 * time.Minute, "2分钟后"},
+	}
+	for _, tt := range tests {
+		if got := formatAge(tt.age); got != tt.want {
+			t.Errorf("formatAge(%v) 应该是 %q，实际: %q", tt.age, tt.want, got)
+		}
+	}
+}
+
+func TestCompactIDDisplay(t *testing.T) {
+	id := base64.RawURLEncoding.EncodeToString([]byte("v1:compact:1640995200123456789:a1b2c3d4"))
+
+	info, err := parseErrorID(id)
+	if err != nil {
+		t.Fatalf("解析精简ID失败: %v", err)
+	}
+	if !info.IsCompact || info.Random != "a1b2c3d4" || info.Package != "" || info.Function != "" {
+		t.Errorf("精简ID解析错误: %+v", info)
+	}
+
+	var buf bytes.Buffer
+	outputFormatted(&buf, info)
+	out := buf.String()
+	if !strings.Contains(out, "精简ID") || strings.Contains(out, "协程ID") || strings.Contains(out, "进程ID") {
+		t.Errorf("精简ID应该只显示时间和随机值，实际:\n%s", out)
+	}
+}
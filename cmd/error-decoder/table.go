@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// tableHeader CSV/TSV输出的列
+var tableHeader = []string{
+	"id", "function", "file", "line", "timestamp", "human_time",
+	"goroutine_id", "process_id", "random", "error",
+}
+
+// tableWriter 逐行输出CSV或TSV，表头只写一次
+type tableWriter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+// newTableWriter 创建CSV或TSV输出，format 为 csv 或 tsv
+func newTableWriter(w io.Writer, format string) (*tableWriter, error) {
+	cw := csv.NewWriter(w)
+	switch format {
+	case "csv":
+	case "tsv":
+		cw.Comma = '\t'
+	default:
+		return nil, fmt.Errorf("未知的表格格式 %q，可选: csv, tsv", format)
+	}
+	return &tableWriter{w: cw}, nil
+}
+
+// writeRow 解码错误ID并输出一行，无法解析的ID在 error 列中记录原因
+func (t *tableWriter) writeRow(id string) error {
+	if !t.wroteHeader {
+		if err := t.w.Write(tableHeader); err != nil {
+			return err
+		}
+		t.wroteHeader = true
+	}
+
+	row := make([]string, len(tableHeader))
+	row[0] = id
+	info, err := parseErrorID(id)
+	if err != nil {
+		row[len(row)-1] = err.Error()
+	} else {
+		if info.isFull() {
+			row[1] = info.Package + "." + info.Function
+			row[2] = info.File
+			row[3] = strconv.Itoa(info.Line)
+		}
+		row[4] = strconv.FormatInt(info.Timestamp, 10)
+		row[5] = info.HumanTime
+		row[6] = strconv.FormatUint(info.GoroutineID, 10)
+		row[7] = strconv.Itoa(info.ProcessID)
+		row[8] = info.Random
+	}
+	if err := t.w.Write(row); err != nil {
+		return err
+	}
+	// 逐行刷新，便于在管道中流式处理
+	t.w.Flush()
+	return t.w.Error()
+}
+
+// processTable 从输入中逐行读取错误ID，以CSV或TSV格式流式输出
+func processTable(r io.Reader, w io.Writer, format string) error {
+	tw, err := newTableWriter(w, format)
+	if err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		id := strings.TrimSpace(scanner.Text())
+		if id == "" || !activeFilter.keepID(id) {
+			continue
+		}
+		if err := tw.writeRow(id); err != nil {
+			return fmt.Errorf("写入输出失败: %w", err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("读取输入失败: %w", err)
+	}
+	return nil
+}
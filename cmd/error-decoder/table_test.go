@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+func TestProcessTableMixedIDs(t *testing.T) {
+	input := strings.Join([]string{
+		encodeID("api/user.GetUser", "user.go", 1),
+		"",
+		"not-an-id!",
+		encodeID("api/order.List", "order.go", 2),
+	}, "\n")
+
+	var buf bytes.Buffer
+	if err := processTable(strings.NewReader(input), &buf, "csv"); err != nil {
+		t.Fatalf("输出CSV失败: %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("输出应该是合法的CSV: %v", err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("应该输出表头和3行，实际: %v", rows)
+	}
+	if strings.Join(rows[0], ",") != strings.Join(tableHeader, ",") {
+		t.Errorf("表头不正确，实际: %v", rows[0])
+	}
+
+	errorCol := len(tableHeader) - 1
+	if rows[1][1] != "api/user.GetUser" || rows[1][2] != "user.go" || rows[1][3] != "10" || rows[1][errorCol] != "" {
+		t.Errorf("第一行解析结果不正确，实际: %v", rows[1])
+	}
+	if rows[2][0] != "not-an-id!" || rows[2][1] != "" || rows[2][errorCol] == "" {
+		t.Errorf("无法解析的ID应该在error列中记录原因，实际: %v", rows[2])
+	}
+	if rows[3][1] != "api/order.List" || rows[3][7] != "100" {
+		t.Errorf("无法解析的ID不应该中断后续输出，实际: %v", rows[3])
+	}
+}
+
+func TestProcessTableTSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := processTable(strings.NewReader(encodeID("api/user.GetUser", "user.go", 1)), &buf, "tsv"); err != nil {
+		t.Fatalf("输出TSV失败: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "id\tfunction\tfile") {
+		t.Errorf("TSV应该以制表符分隔，实际:\n%s", buf.String())
+	}
+
+	if err := processTable(strings.NewReader(""), &buf, "xml"); err == nil {
+		t.Error("未知的表格格式应该返回错误")
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// timelineEntry 时间线中的一个错误ID
+type timelineEntry struct {
+	ID   string
+	Info *ErrorInfo
+	Err  error
+}
+
+// readErrorIDs 从输入中按行读取错误ID，忽略空行和不满足过滤条件的ID
+func readErrorIDs(r io.Reader) ([]string, error) {
+	var ids []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && activeFilter.keepID(line) {
+			ids = append(ids, line)
+		}
+	}
+	return ids, scanner.Err()
+}
+
+// processTimeline 读取一批错误ID，按时间排序后以 ascii 或 dot 格式输出
+func processTimeline(r io.Reader, w io.Writer, format string) error {
+	if format != "ascii" && format != "dot" {
+		return fmt.Errorf("未知的时间线格式 %q，可选: ascii, dot", format)
+	}
+
+	ids, err := readErrorIDs(r)
+	if err != nil {
+		return fmt.Errorf("读取输入失败: %w", err)
+	}
+
+	var decoded, failed []timelineEntry
+	for _, id := range ids {
+		info, err := parseErrorID(id)
+		if err != nil {
+			failed = append(failed, timelineEntry{ID: id, Err: err})
+			continue
+		}
+		decoded = append(decoded, timelineEntry{ID: id, Info: info})
+	}
+	sort.SliceStable(decoded, func(i, j int) bool {
+		return decoded[i].Info.Timestamp < decoded[j].Info.Timestamp
+	})
+
+	if format == "dot" {
+		writeTimelineDOT(w, decoded, failed)
+	} else {
+		writeTimelineASCII(w, decoded, failed)
+	}
+	return nil
+}
+
+// writeTimelineASCII 按时间顺序输出错误，并标注与上一个错误的时间间隔
+func writeTimelineASCII(w io.Writer, decoded, failed []timelineEntry) {
+	color := func(c, text string) string {
+		if *flagNoColor {
+			return text
+		}
+		return c + text + ColorReset
+	}
+
+	fmt.Fprintf(w, "%s\n", color(ColorBold+ColorCyan, fmt.Sprintf("🕒 错误时间线 (%d 个错误ID)", len(decoded))))
+	fmt.Fprintf(w, "%s\n", strings.Repeat("=", 50))
+
+	var prev int64
+	for i, entry := range decoded {
+		gap := "+0s"
+		if i > 0 {
+			gap = "+" + time.Duration(entry.Info.Timestamp-prev).String()
+		}
+		prev = entry.Info.Timestamp
+
+		fmt.Fprintf(w, "%s  %-14s %s %s\n",
+			color(ColorPurple, entry.Info.HumanTime),
+			color(ColorYellow, gap),
+			color(ColorGreen, entryName(entry.Info)),
+			color(ColorCyan, entryLocation(entry.Info)))
+	}
+
+	if len(decoded) > 1 {
+		span := time.Duration(decoded[len(decoded)-1].Info.Timestamp - decoded[0].Info.Timestamp)
+		fmt.Fprintf(w, "%s\n", strings.Repeat("-", 50))
+		fmt.Fprintf(w, "总跨度: %s\n", span)
+	}
+
+	if len(failed) > 0 {
+		fmt.Fprintf(w, "\n%s\n", color(ColorRed, fmt.Sprintf("⚠️  %d 个错误ID无法解析:", len(failed))))
+		for _, entry := range failed {
+			fmt.Fprintf(w, "  %s: %v\n", entry.ID, entry.Err)
+		}
+	}
+}
+
+// writeTimelineDOT 输出 Graphviz DOT 图：按函数分组，按时间顺序连线
+func writeTimelineDOT(w io.Writer, decoded, failed []timelineEntry) {
+	fmt.Fprintln(w, "digraph errors {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, "  node [shape=box, fontname=\"monospace\"];")
+
+	// 按函数分组，保持首次出现的顺序
+	var funcs []string
+	groups := make(map[string][]int)
+	for i, entry := range decoded {
+		name := entryName(entry.Info)
+		if _, ok := groups[name]; !ok {
+			funcs = append(funcs, name)
+		}
+		groups[name] = append(groups[name], i)
+	}
+
+	for n, name := range funcs {
+		fmt.Fprintf(w, "  subgraph cluster_%d {\n", n)
+		fmt.Fprintf(w, "    label=%s;\n", dotQuote(name))
+		for _, i := range groups[name] {
+			info := decoded[i].Info
+			label := entryLocation(info) + "\n" + info.HumanTime
+			fmt.Fprintf(w, "    e%d [label=%s];\n", i, dotQuote(label))
+		}
+		fmt.Fprintln(w, "  }")
+	}
+
+	for i := 1; i < len(decoded); i++ {
+		gap := time.Duration(decoded[i].Info.Timestamp - decoded[i-1].Info.Timestamp)
+		fmt.Fprintf(w, "  e%d -> e%d [label=%s];\n", i-1, i, dotQuote("+"+gap.String()))
+	}
+
+	for _, entry := range failed {
+		fmt.Fprintf(w, "  // 无法解析: %s (%v)\n", entry.ID, entry.Err)
+	}
+	fmt.Fprintln(w, "}")
+}
+
+// entryName 时间线中显示的函数名，备用ID和精简ID没有函数信息
+func entryName(info *ErrorInfo) string {
+	if info.IsFallback {
+		return "(备用ID)"
+	}
+	if info.IsCompact {
+		return "(精简ID)"
+	}
+	return info.Package + "." + info.Function
+}
+
+// entryLocation 时间线中显示的调用位置
+func entryLocation(info *ErrorInfo) string {
+	if info.IsFallback {
+		return fmt.Sprintf("(pid %d)", info.ProcessID)
+	}
+	if info.IsCompact {
+		return "(-)"
+	}
+	return fmt.Sprintf("(%s:%d)", info.File, info.Line)
+}
+
+// dotQuote 将文本转换为 DOT 字符串字面量
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+)
+
+func TestProcessTimeline(t *testing.T) {
+	*flagNoColor = true
+	t.Cleanup(func() { *flagNoColor = false })
+
+	base := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	// 输入顺序与时间顺序不同
+	scrambled := strings.Join([]string{
+		encodeIDAt("api/order.List", base.Add(2*time.Minute)),
+		encodeIDAt("api/user.GetUser", base),
+		"",
+		encodeIDAt("api/user.Login", base.Add(time.Minute)),
+	}, "\n")
+
+	tests := []struct {
+		name   string
+		format string
+		want   []string // 按顺序出现在输出中的片段
+	}{
+		{"ASCII按时间排序", "ascii", []string{
+			"错误时间线 (3 个错误ID)",
+			"+0s", "api/user.GetUser (svc.go:10)",
+			"+1m0s", "api/user.Login (svc.go:10)",
+			"+1m0s", "api/order.List (svc.go:10)",
+			"总跨度: 2m0s",
+		}},
+		{"DOT按函数分组并按时间连线", "dot", []string{
+			"digraph errors {",
+			`subgraph cluster_0 {`, `label="api/user.GetUser";`, `e0 [label="(svc.go:10)\n`,
+			`subgraph cluster_1 {`, `label="api/user.Login";`, `e1 [label=`,
+			`subgraph cluster_2 {`, `label="api/order.List";`, `e2 [label=`,
+			`e0 -> e1 [label="+1m0s"];`,
+			`e1 -> e2 [label="+1m0s"];`,
+			"}",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := processTimeline(strings.NewReader(scrambled), &buf, tt.format); err != nil {
+				t.Fatalf("输出时间线失败: %v", err)
+			}
+			out := buf.String()
+			rest := out
+			for _, want := range tt.want {
+				i := strings.Index(rest, want)
+				if i < 0 {
+					t.Fatalf("输出中应该按顺序包含 %q，实际:\n%s", want, out)
+				}
+				rest = rest[i+len(want):]
+			}
+		})
+	}
+
+	if err := processTimeline(strings.NewReader(""), &bytes.Buffer{}, "svg"); err == nil {
+		t.Error("未知的时间线格式应该返回错误")
+	}
+}
+
+func TestProcessTimelineMixedIDs(t *testing.T) {
+	*flagNoColor = true
+	t.Cleanup(func() { *flagNoColor = false })
+
+	base := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	// 没有版本前缀的旧ID按版本0解码
+	v0 := base64.StdEncoding.EncodeToString([]byte("api/legacy.Old@old.go:5:" + strconv.FormatInt(base.UnixNano(), 10) + ":1:100:abcd"))
+	v1 := encodeIDAt("api/user.GetUser", base.Add(time.Second))
+	current := errors.New(500, "INTERNAL", "内部错误").GetID()
+	future := base64.StdEncoding.EncodeToString([]byte("v99:api/user.GetUser@svc.go:10:1:1:100:abcd"))
+	input := strings.Join([]string{current, "not-an-id!", v1, future, v0}, "\n")
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"ascii", []string{
+			"错误时间线 (3 个错误ID)",
+			"api/legacy.Old (old.go:5)",
+			"api/user.GetUser (svc.go:10)",
+			"TestProcessTimelineMixedIDs (timeline_test.go:",
+			"2 个错误ID无法解析:",
+			"not-an-id!: ",
+			future + ": ",
+		}},
+		{"dot", []string{
+			`label="api/legacy.Old";`,
+			`label="api/user.GetUser";`,
+			`TestProcessTimelineMixedIDs";`,
+			`e0 -> e1`, `e1 -> e2`,
+			"// 无法解析: not-an-id! (",
+			"// 无法解析: " + future + " (",
+			"}",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := processTimeline(strings.NewReader(input), &buf, tt.format); err != nil {
+				t.Fatalf("无法解析的ID不应该中断时间线: %v", err)
+			}
+			out := buf.String()
+			rest := out
+			for _, want := range tt.want {
+				i := strings.Index(rest, want)
+				if i < 0 {
+					t.Fatalf("输出中应该按顺序包含 %q，实际:\n%s", want, out)
+				}
+				rest = rest[i+len(want):]
+			}
+		})
+	}
+}
+
+func TestDotQuote(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"api/user.GetUser", `"api/user.GetUser"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{`C:\path`, `"C:\\path"`},
+		{"(user.go:10)\n2024-01-02", `"(user.go:10)\n2024-01-02"`},
+		{`\"`, `"\\\""`},
+		{"", `""`},
+	}
+	for _, tt := range tests {
+		if got := dotQuote(tt.in); got != tt.want {
+			t.Errorf("dotQuote(%q) 应该是 %s，实际: %s", tt.in, tt.want, got)
+		}
+	}
+}
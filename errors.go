@@ -1,33 +1,110 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
 	"strings"
 
 	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/encoding/protowire"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoregistry"
 	"google.golang.org/protobuf/types/descriptorpb"
 
 	errorspb "github.com/honeybbq/go-zero-errors-proto/errors"
 )
 
-// generateFile generates the errors code for a single proto file
-func generateFile(gen *protogen.Plugin, file *protogen.File) {
+// generateFile generates the errors code for a single proto file. registries
+// records the Go packages whose Registry has already been declared, so that
+// it is declared once per package.
+func generateFile(gen *protogen.Plugin, file *protogen.File, opts *options, registries map[protogen.GoImportPath]bool) {
 	if len(file.Enums) == 0 {
 		return
 	}
 
-	filename := file.GeneratedFilenamePrefix + "_errors.pb.go"
-	g := gen.NewGeneratedFile(filename, file.GoImportPath)
+	filename := file.GeneratedFilenamePrefix + opts.errorsSuffix
+	importPath := file.GoImportPath
+	packageName := getGoPackageName(file)
+	if opts.packageSuffix != "" {
+		// 子包位于原包目录下，目录名与包名一致，例如 userv1/userv1errors
+		packageName += opts.packageSuffix
+		dir, base := path.Split(file.GeneratedFilenamePrefix)
+		filename = dir + packageName + "/" + base + opts.errorsSuffix
+		importPath = protogen.GoImportPath(path.Join(string(importPath), packageName))
+	}
+	g := gen.NewGeneratedFile(filename, importPath)
 
 	// Generate file header
-	generateHeader(g, file)
+	generateHeader(g, packageName)
 
 	// Generate errors for each enum
 	for _, enum := range file.Enums {
-		generateEnum(g, enum)
+		generateEnum(g, enum, opts)
+	}
+
+	if !registries[importPath] {
+		registries[importPath] = true
+		generateRegistry(g)
+		if opts.genWiring {
+			generateWiring(gen, path.Join(path.Dir(filename), wiringFilename), importPath, packageName)
+		}
 	}
 }
 
+// wiringFilename 开启 gen_wiring 时每个包生成的注册函数文件名
+const wiringFilename = "errors_wiring.pb.go"
+
+// generateWiring generates the helpers registering the error interceptors on
+// go-zero servers, once per package. go-zero servers accept interceptors and
+// middlewares after construction, unlike *grpc.Server.
+func generateWiring(gen *protogen.Plugin, filename string, importPath protogen.GoImportPath, packageName string) {
+	g := gen.NewGeneratedFile(filename, importPath)
+	g.P("// Code generated by protoc-gen-go-zero-errors. DO NOT EDIT.")
+	g.P()
+	g.P("package ", packageName)
+	g.P()
+	g.P("import (")
+	g.P(`	interceptor "`, interceptorPkgPath, `"`)
+	g.P(`	rest "github.com/zeromicro/go-zero/rest"`)
+	g.P(`	zrpc "github.com/zeromicro/go-zero/zrpc"`)
+	g.P(")")
+	g.P()
+	g.P("// RegisterErrorHandling adds the unary and stream error interceptors to")
+	g.P("// server, so that errors returned by its handlers reach clients with their")
+	g.P("// code, reason and ID. opts configure the interceptors, e.g.")
+	g.P("// interceptor.WithPanicRecovery().")
+	g.P("func RegisterErrorHandling(server *zrpc.RpcServer, opts ...interceptor.Option) {")
+	g.P("	server.AddUnaryInterceptors(interceptor.UnaryServerErrorInterceptor(opts...))")
+	g.P("	server.AddStreamInterceptors(interceptor.StreamServerErrorInterceptor(opts...))")
+	g.P("}")
+	g.P()
+	g.P("// RegisterHTTPErrorHandling sets the go-zero error handler and adds the error")
+	g.P("// middleware to server, so that errors are written as structured JSON with")
+	g.P("// their ID. opts configure both. The error handler is process-wide.")
+	g.P("func RegisterHTTPErrorHandling(server *rest.Server, opts ...interceptor.Option) {")
+	g.P("	interceptor.SetDefaultErrorHandler(opts...)")
+	g.P("	server.Use(interceptor.HTTPErrorMiddlewareWith(opts...))")
+	g.P("}")
+}
+
+// generateRegistry generates the Registry of the package and its Lookup
+// function. Every generated file adds its errors to Registry from init.
+func generateRegistry(g *protogen.GeneratedFile) {
+	g.P("// Registry maps the reason of every error generated into this package to its")
+	g.P("// definition, so that tools such as documentation generators and admin UIs")
+	g.P("// can enumerate the known errors at runtime. It is filled in by init")
+	g.P("// functions and must not be modified.")
+	g.P("var Registry = map[string]*errors.Definition{}")
+	g.P()
+	g.P("// Lookup returns the definition of the error with the given reason.")
+	g.P("func Lookup(reason string) (*errors.Definition, bool) {")
+	g.P("	d, ok := Registry[reason]")
+	g.P("	return d, ok")
+	g.P("}")
+}
+
 // getGoPackageName extracts the correct package name from go_package option
 func getGoPackageName(file *protogen.File) string {
 	// 检查是否有显式指定的包名（格式：path;package）
@@ -49,10 +126,10 @@ func getGoPackageName(file *protogen.File) string {
 }
 
 // generateHeader generates the file header with package and imports
-func generateHeader(g *protogen.GeneratedFile, file *protogen.File) {
+func generateHeader(g *protogen.GeneratedFile, packageName string) {
 	g.P("// Code generated by protoc-gen-go-zero-errors. DO NOT EDIT.")
 	g.P()
-	g.P("package ", getGoPackageName(file))
+	g.P("package ", packageName)
 	g.P()
 	g.P("import (")
 	g.P(`	"fmt"`)
@@ -63,52 +140,201 @@ func generateHeader(g *protogen.GeneratedFile, file *protogen.File) {
 }
 
 // generateEnum generates error functions for an enum
-func generateEnum(g *protogen.GeneratedFile, enum *protogen.Enum) {
+func generateEnum(g *protogen.GeneratedFile, enum *protogen.Enum, opts *options) {
 	// Get default code from enum options
 	defaultCode := getDefaultCode(enum.Desc.Options())
 
+	generateReasons(g, enum, opts)
+
 	// Generate error functions for each enum value
 	for _, value := range enum.Values {
-		generateErrorFunc(g, enum, value, defaultCode)
-		generateIsFunc(g, enum, value)
+		generateErrorFunc(g, enum, value, defaultCode, opts)
+		generateIsFunc(g, enum, value, opts)
 	}
+
+	generateTemplates(g, enum, defaultCode, opts)
+}
+
+// generateReasons generates an exported reason constant for each enum value
+func generateReasons(g *protogen.GeneratedFile, enum *protogen.Enum, opts *options) {
+	g.P("// Reasons declared by ", enum.Desc.Name(), ".")
+	g.P("const (")
+	for _, value := range enum.Values {
+		name := goName(enum, value, opts)
+		if comment := defaultMessage(value); comment != "" {
+			g.P("	// ", name, " ", comment)
+		}
+		g.P("	", name, " = ", strconv.Quote(reasonName(enum, value, opts)))
+	}
+	g.P(")")
+	g.P()
+}
+
+// generateTemplates generates an init function registering an errors.Template
+// for each enum value and adding its definition to Registry
+func generateTemplates(g *protogen.GeneratedFile, enum *protogen.Enum, defaultCode int32, opts *options) {
+	g.P("func init() {")
+	for _, value := range enum.Values {
+		code := getValueCode(value.Desc.Options(), defaultCode)
+		reason := goName(enum, value, opts)
+		// 与 ErrorXxx 的默认消息一致，没有注释时使用原因字符串
+		message := reason
+		if m := defaultMessage(value); m != "" {
+			message = strconv.Quote(m)
+		}
+		g.P("	errors.RegisterTemplate(errors.Template{")
+		g.P("		Code:     ", code, ",")
+		g.P("		Reason:   ", reason, ",")
+		g.P("		Message:  ", message, ",")
+		// 使用枚举的完整名称，不同包中相同的原因注册为不同的模板
+		g.P("		Category: ", strconv.Quote(string(enum.Desc.FullName())), ",")
+		g.P("	})")
+		g.P("	Registry[", reason, "] = errors.Define(", code, ", ", reason, ", ", message, ")")
+	}
+	g.P("}")
+	g.P()
 }
 
 // generateErrorFunc generates xx function
-func generateErrorFunc(g *protogen.GeneratedFile, enum *protogen.Enum, value *protogen.EnumValue, defaultCode int32) {
+func generateErrorFunc(g *protogen.GeneratedFile, enum *protogen.Enum, value *protogen.EnumValue, defaultCode int32, opts *options) {
 	// Get custom code or use default
 	code := getValueCode(value.Desc.Options(), defaultCode)
 
-	// Get comment from proto
-	comment := getValueComment(value)
+	// Get comment from proto, joined into a single line
+	comment := defaultMessage(value)
 
 	// Generate function name
-	funcName := "Error" + camelCase(string(value.Desc.Name()))
+	reason := goName(enum, value, opts)
+	funcName := "Error" + reason
 
 	// Generate function
 	if comment != "" {
 		g.P("// ", funcName, " ", comment)
 	}
 	g.P("func ", funcName, "(format string, args ...interface{}) *errors.Error {")
-	g.P(`	return errors.New(`, code, `, "`, value.Desc.Name(), `", fmt.Sprintf(format, args...))`)
+	g.P(`	if format == "" {`)
+	if message := defaultMessage(value); message != "" {
+		g.P(`		return errors.New(`, code, `, `, reason, `, `, strconv.Quote(message), `)`)
+	} else {
+		g.P(`		return errors.New(`, code, `, `, reason, `, `, reason, `)`)
+	}
+	g.P(`	}`)
+	g.P(`	return errors.New(`, code, `, `, reason, `, fmt.Sprintf(format, args...))`)
 	g.P("}")
 	g.P()
 }
 
 // generateIsFunc generates IsXxx function
-func generateIsFunc(g *protogen.GeneratedFile, enum *protogen.Enum, value *protogen.EnumValue) {
+func generateIsFunc(g *protogen.GeneratedFile, enum *protogen.Enum, value *protogen.EnumValue, opts *options) {
 	// Generate function name
-	funcName := "Is" + camelCase(string(value.Desc.Name()))
+	reason := goName(enum, value, opts)
+	funcName := "Is" + reason
 
 	// Generate function
-	g.P("// ", funcName, " determines if err is an error which indicates a ", value.Desc.Name(), " error.")
+	g.P("// ", funcName, " determines if err is an error which indicates a ", reasonName(enum, value, opts), " error.")
 	g.P("// It supports wrapped errors.")
 	g.P("func ", funcName, "(err error) bool {")
-	g.P(`	return errors.Reason(err) == "`, value.Desc.Name(), `"`)
+	g.P(`	return errors.Reason(err) == `, reason)
 	g.P("}")
 	g.P()
 }
 
+// reasonName returns the reason of an enum value: the (errors.reason) option
+// if set, otherwise valueName
+func reasonName(enum *protogen.Enum, value *protogen.EnumValue, opts *options) string {
+	if reason := getValueReason(value.Desc.Options()); reason != "" {
+		return reason
+	}
+	return valueName(enum, value, opts)
+}
+
+// goName returns the name of the Go identifiers generated for an enum value,
+// e.g. UserNotFound in ErrorUserNotFound. It is derived from valueName, not
+// from the (errors.reason) option, so that the reason can stay stable when
+// the value is renamed and vice versa
+func goName(enum *protogen.Enum, value *protogen.EnumValue, opts *options) string {
+	return camelCase(valueName(enum, value, opts))
+}
+
+// valueName returns the name of an enum value, without the enum type prefix
+// when strip_enum_prefix is set
+func valueName(enum *protogen.Enum, value *protogen.EnumValue, opts *options) string {
+	name := string(value.Desc.Name())
+	if !opts.stripEnumPrefix {
+		return name
+	}
+	// 枚举类型名 UserError 对应的前缀为 USER_ERROR_，去掉前缀后不能为空
+	prefix := upperSnakeCase(string(enum.Desc.Name())) + "_"
+	if strings.HasPrefix(name, prefix) && len(name) > len(prefix) {
+		return name[len(prefix):]
+	}
+	return name
+}
+
+// checkDuplicateReasons reports enum values of the files to generate that
+// resolve to the same reason, after strip_enum_prefix, within a Go package:
+// their reason constants and Registry entries would collide. It also reports
+// values with different reasons, e.g. set with (errors.reason), whose Go
+// names are the same, since their ErrorXxx and IsXxx functions would collide
+func checkDuplicateReasons(files []*protogen.File, opts *options) error {
+	type site struct {
+		kind   string // "reason" 或 "Go name"
+		name   string
+		reason string // 第一个值的原因，原因也相同时只报告重复的原因
+		values []string
+	}
+	var conflicts []*site
+	sites := make(map[protogen.GoImportPath]map[string]*site)
+	add := func(path protogen.GoImportPath, kind, name, reason, value string) {
+		key := kind + ":" + name
+		s := sites[path][key]
+		if s == nil {
+			s = &site{kind: kind, name: name, reason: reason}
+			sites[path][key] = s
+		} else if kind == "Go name" && reason == s.reason {
+			return
+		}
+		s.values = append(s.values, value)
+		if len(s.values) == 2 {
+			conflicts = append(conflicts, s)
+		}
+	}
+	for _, f := range files {
+		if !f.Generate {
+			continue
+		}
+		if sites[f.GoImportPath] == nil {
+			sites[f.GoImportPath] = make(map[string]*site)
+		}
+		for _, enum := range f.Enums {
+			for _, value := range enum.Values {
+				reason := reasonName(enum, value, opts)
+				desc := fmt.Sprintf("%s (%s)", value.Desc.FullName(), valueLocation(f, value))
+				add(f.GoImportPath, "reason", reason, reason, desc)
+				add(f.GoImportPath, "Go name", goName(enum, value, opts), reason, desc)
+			}
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(conflicts))
+	for _, s := range conflicts {
+		msgs = append(msgs, fmt.Sprintf("duplicate %s %q: %s", s.kind, s.name, strings.Join(s.values, ", ")))
+	}
+	return errors.New(strings.Join(msgs, "; "))
+}
+
+// valueLocation returns the source location of an enum value as file:line:column
+func valueLocation(f *protogen.File, value *protogen.EnumValue) string {
+	loc := f.Desc.SourceLocations().ByDescriptor(value.Desc)
+	// 没有源码信息时只返回文件名
+	if loc.StartLine == 0 && loc.StartColumn == 0 && len(loc.Path) == 0 {
+		return f.Desc.Path()
+	}
+	return fmt.Sprintf("%s:%d:%d", f.Desc.Path(), loc.StartLine+1, loc.StartColumn+1)
+}
+
 // getDefaultCode extracts default_code from enum options
 func getDefaultCode(opts proto.Message) int32 {
 	if opts == nil {
@@ -145,6 +371,48 @@ func getValueCode(opts proto.Message, defaultCode int32) int32 {
 	return defaultCode
 }
 
+// reasonFieldNumber is the field number of the (errors.reason) extension
+const reasonFieldNumber = 1110
+
+// getValueReason extracts the (errors.reason) option from enum value options
+func getValueReason(opts proto.Message) string {
+	valueOpts, ok := opts.(*descriptorpb.EnumValueOptions)
+	if !ok || valueOpts == nil {
+		return ""
+	}
+	m := valueOpts.ProtoReflect()
+	if xt, err := protoregistry.GlobalTypes.FindExtensionByNumber(m.Descriptor().FullName(), reasonFieldNumber); err == nil {
+		if reason, ok := proto.GetExtension(valueOpts, xt).(string); ok {
+			return reason
+		}
+		return ""
+	}
+
+	// 依赖的 errorspb 版本没有注册该扩展时，扩展作为未知字段保留，直接解析
+	var reason string
+	for b := m.GetUnknown(); len(b) > 0; {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return ""
+		}
+		b = b[n:]
+		if num == reasonFieldNumber && typ == protowire.BytesType {
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return ""
+			}
+			reason = string(v) // 重复出现时以最后一个为准
+			b = b[n:]
+			continue
+		}
+		if n = protowire.ConsumeFieldValue(num, typ, b); n < 0 {
+			return ""
+		}
+		b = b[n:]
+	}
+	return reason
+}
+
 // getValueComment extracts comment from enum value
 func getValueComment(value *protogen.EnumValue) string {
 	if value.Comments.Leading != "" {
@@ -156,6 +424,27 @@ func getValueComment(value *protogen.EnumValue) string {
 	return ""
 }
 
+// defaultMessage returns the message used when ErrorXxx is called with an
+// empty format: the value comment joined into a single line
+func defaultMessage(value *protogen.EnumValue) string {
+	// 多行注释合并为一行，去掉每行首尾的空白
+	return strings.Join(strings.Fields(getValueComment(value)), " ")
+}
+
+// upperSnakeCase converts CamelCase to UPPER_SNAKE_CASE
+func upperSnakeCase(s string) string {
+	isUpper := func(c byte) bool { return c >= 'A' && c <= 'Z' }
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		// 在单词边界处加下划线，连续的大写字母（如 HTTPError）视为一个单词
+		if i > 0 && isUpper(s[i]) && (!isUpper(s[i-1]) || (i+1 < len(s) && !isUpper(s[i+1]) && s[i+1] != '_')) && s[i-1] != '_' {
+			b.WriteByte('_')
+		}
+		b.WriteByte(s[i])
+	}
+	return strings.ToUpper(b.String())
+}
+
 // camelCase converts snake_case to CamelCase
 func camelCase(s string) string {
 	if s == "" {
@@ -172,3 +461,6 @@ func camelCase(s string) string {
 }
 
 const errorsPkgPath = "github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+
+// interceptorPkgPath 生成的注册函数引用的拦截器包
+const interceptorPkgPath = "github.com/honeybbq/protoc-gen-go-zero-errors/interceptor"
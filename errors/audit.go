@@ -0,0 +1,86 @@
+package errors
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// Reserved metadata keys read by AuditRecord.
+const (
+	MetadataKeyTenant  = "tenant"
+	MetadataKeyService = "service"
+	MetadataKeyTraceID = "trace_id"
+)
+
+// AuditRecord is a fixed-schema view of an Error for audit logging.
+type AuditRecord struct {
+	// Timestamp is when the error was created, decoded from its ID. It falls
+	// back to the time AuditRecord was called when the ID cannot be decoded.
+	Timestamp time.Time
+	Code      int32
+	Reason    string
+	Message   string
+	ID        string
+	// Origin is the function@file:line that created the error, decoded from
+	// its ID. It is empty for fallback IDs and IDs that cannot be decoded.
+	Origin      string
+	Tenant      string
+	ServiceName string
+	TraceID     string
+}
+
+// auditRecordJSON AuditRecord 的JSON结构，字段名保持稳定
+type auditRecordJSON struct {
+	Timestamp   string `json:"timestamp"`
+	Code        int32  `json:"code"`
+	Reason      string `json:"reason"`
+	Message     string `json:"message"`
+	ID          string `json:"id,omitempty"`
+	Origin      string `json:"origin,omitempty"`
+	Tenant      string `json:"tenant,omitempty"`
+	ServiceName string `json:"service_name,omitempty"`
+	TraceID     string `json:"trace_id,omitempty"`
+}
+
+// AuditRecord returns the audit record for the error. Tenant, ServiceName and
+// TraceID are read from the MetadataKeyTenant, MetadataKeyService and
+// MetadataKeyTraceID metadata keys.
+func (e *Error) AuditRecord() AuditRecord {
+	id := e.id()
+	rec := AuditRecord{
+		Code:        e.Code,
+		Reason:      e.Reason,
+		Message:     e.Message,
+		ID:          id,
+		Tenant:      e.Metadata[MetadataKeyTenant],
+		ServiceName: e.Metadata[MetadataKeyService],
+		TraceID:     e.Metadata[MetadataKeyTraceID],
+	}
+
+	if info, err := DecodeErrorID(id); id != "" && err == nil {
+		rec.Timestamp = time.Unix(0, info.Timestamp)
+		if !info.IsFallback && !info.IsCompact {
+			rec.Origin = info.Function + "@" + info.File + ":" + strconv.Itoa(info.Line)
+		}
+	} else {
+		rec.Timestamp = time.Now()
+	}
+	return rec
+}
+
+// MarshalJSON implements json.Marshaler. The timestamp is written in UTC using
+// RFC 3339 with nanoseconds; empty optional fields are omitted.
+func (r AuditRecord) MarshalJSON() ([]byte, error) {
+	return json.Marshal(auditRecordJSON{
+		Timestamp:   r.Timestamp.UTC().Format(time.RFC3339Nano),
+		Code:        r.Code,
+		Reason:      r.Reason,
+		Message:     r.Message,
+		ID:          r.ID,
+		Origin:      r.Origin,
+		Tenant:      r.Tenant,
+		ServiceName: r.ServiceName,
+		TraceID:     r.TraceID,
+	})
+}
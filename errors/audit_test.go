@@ -0,0 +1,46 @@
+package errors
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditRecord(t *testing.T) {
+	before := time.Now()
+	err := New(403, "ACCESS_DENIED", "无权访问").WithMetadata(map[string]string{
+		MetadataKeyTenant:  "acme",
+		MetadataKeyService: "billing",
+		MetadataKeyTraceID: "trace-123",
+	})
+
+	rec := err.AuditRecord()
+
+	if rec.Code != 403 || rec.Reason != "ACCESS_DENIED" || rec.Message != "无权访问" || rec.ID != err.ID {
+		t.Errorf("状态字段应该来自错误，实际: %+v", rec)
+	}
+	if rec.Tenant != "acme" || rec.ServiceName != "billing" || rec.TraceID != "trace-123" {
+		t.Errorf("保留的metadata字段应该被读取，实际: %+v", rec)
+	}
+	if rec.Timestamp.Before(before.Add(-time.Second)) || rec.Timestamp.After(time.Now()) {
+		t.Errorf("时间戳应该从错误ID解码，实际: %v", rec.Timestamp)
+	}
+	if !strings.Contains(rec.Origin, "TestAuditRecord@") || !strings.Contains(rec.Origin, "audit_test.go:") {
+		t.Errorf("来源应该指向创建错误的位置，实际: %s", rec.Origin)
+	}
+
+	data, marshalErr := json.Marshal(rec)
+	if marshalErr != nil {
+		t.Fatalf("序列化失败: %v", marshalErr)
+	}
+	var fields map[string]any
+	if unmarshalErr := json.Unmarshal(data, &fields); unmarshalErr != nil {
+		t.Fatalf("反序列化失败: %v", unmarshalErr)
+	}
+	for _, key := range []string{"timestamp", "code", "reason", "message", "id", "origin", "tenant", "service_name", "trace_id"} {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("JSON应该包含字段 %s，实际: %s", key, data)
+		}
+	}
+}
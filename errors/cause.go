@@ -0,0 +1,71 @@
+package errors
+
+import (
+	stderrors "errors"
+
+	"google.golang.org/protobuf/protoadapt"
+
+	errorspb "github.com/honeybbq/go-zero-errors-proto/errors"
+)
+
+// maxCauseDepth 通过gRPC传递的cause链的最大层数，更深的cause会被丢弃
+const maxCauseDepth = 8
+
+// remoteCause 从gRPC错误详情还原的非 *Error 类型的cause，只保留消息
+type remoteCause struct {
+	msg  string
+	next error
+}
+
+func (c *remoteCause) Error() string { return c.msg }
+
+func (c *remoteCause) Unwrap() error { return c.next }
+
+// Causes returns the cause chain of err, excluding err itself, from the
+// outermost cause to the root. Layers that are *Error keep their Status; other
+// layers only carry their message. The chain is capped at the same depth as
+// the one transmitted by GRPCStatus.
+func Causes(err error) []Status {
+	if err == nil {
+		return nil
+	}
+	var causes []Status
+	for c := stderrors.Unwrap(err); c != nil && len(causes) < maxCauseDepth; c = stderrors.Unwrap(c) {
+		if se, ok := c.(*Error); ok {
+			causes = append(causes, se.Status)
+		} else {
+			causes = append(causes, Status{Message: c.Error()})
+		}
+	}
+	return causes
+}
+
+// causeDetails 将cause链转换为gRPC错误详情：*Error 保留完整状态，其他错误只保留消息
+func causeDetails(cause error) []protoadapt.MessageV1 {
+	var details []protoadapt.MessageV1
+	for c := cause; c != nil && len(details) < maxCauseDepth; c = stderrors.Unwrap(c) {
+		if se, ok := c.(*Error); ok {
+			details = append(details, se.statusDetail())
+		} else {
+			details = append(details, &errorspb.Status{Message: c.Error()})
+		}
+	}
+	return details
+}
+
+// rebuildCause 从gRPC错误详情还原cause链，是 causeDetails 的逆过程
+func rebuildCause(statuses []*errorspb.Status) error {
+	var next error
+	for i := len(statuses) - 1; i >= 0; i-- {
+		d := statuses[i]
+		if d.Code == 0 && d.Reason == "" && len(d.Metadata) == 0 {
+			next = &remoteCause{msg: d.Message, next: next}
+			continue
+		}
+		ce := &Error{}
+		applyStatusDetail(ce, d)
+		ce.cause = next
+		next = ce
+	}
+	return next
+}
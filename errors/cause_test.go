@@ -0,0 +1,106 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// invokeFailing 启动进程内gRPC服务器，返回调用一个总是失败的方法得到的错误
+func invokeFailing(t *testing.T, serverErr error) error {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(grpc.UnknownServiceHandler(func(any, grpc.ServerStream) error {
+		return serverErr
+	}))
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn.Invoke(context.Background(), "/test.Service/Fail", &emptypb.Empty{}, &emptypb.Empty{})
+}
+
+func TestCauseChainOverGRPC(t *testing.T) {
+	dbErr := InternalServer("DB_ERROR", "数据库错误").WithCause(stderrors.New("connection refused"))
+	serverErr := NotFound("USER_NOT_FOUND", "用户不存在").
+		WithCause(fmt.Errorf("load user: %w", dbErr))
+
+	got := FromError(invokeFailing(t, serverErr))
+	if got.Reason != "USER_NOT_FOUND" || got.ID != serverErr.ID {
+		t.Fatalf("错误本身应该被还原, 实际: %v", got)
+	}
+
+	wrapper := Unwrap(got)
+	if wrapper == nil || wrapper.Error() != fmt.Sprintf("load user: %v", dbErr) {
+		t.Fatalf("第一层cause应该保留消息, 实际: %v", wrapper)
+	}
+
+	var remoteDB *Error
+	if !stderrors.As(wrapper, &remoteDB) {
+		t.Fatal("errors.As应该在客户端找到*Error类型的cause")
+	}
+	if remoteDB.Code != 500 || remoteDB.Reason != "DB_ERROR" || remoteDB.ID != dbErr.ID {
+		t.Errorf("*Error类型的cause应该保留code、reason和ID, 实际: %v", remoteDB)
+	}
+	if root := Unwrap(remoteDB); root == nil || root.Error() != "connection refused" {
+		t.Errorf("最底层的cause应该被还原, 实际: %v", root)
+	}
+	if !stderrors.Is(got, InternalServer("DB_ERROR", "")) {
+		t.Error("errors.Is应该匹配客户端还原的cause")
+	}
+}
+
+func TestCauseChainDepthCapped(t *testing.T) {
+	var err error = stderrors.New("root")
+	for i := 0; i < maxCauseDepth*2; i++ {
+		err = fmt.Errorf("layer %d: %w", i, err)
+	}
+
+	got := FromError(InternalServer("DEEP", "深层错误").WithCause(err).GRPCStatus().Err())
+
+	depth := 0
+	for c := Unwrap(got); c != nil; c = Unwrap(c) {
+		depth++
+	}
+	if depth != maxCauseDepth {
+		t.Errorf("传递的cause链应该被限制为 %d 层, 实际: %d", maxCauseDepth, depth)
+	}
+}
+
+func TestCausesRoundTrip(t *testing.T) {
+	root := InternalServer("DB_ERROR", "数据库错误")
+	mid := ServiceUnavailable("REPO_UNAVAILABLE", "仓储不可用").WithCause(root)
+	top := BadRequest("CREATE_FAILED", "创建失败").WithCause(mid)
+
+	want := []Status{
+		{Code: 503, Reason: "REPO_UNAVAILABLE", Message: "仓储不可用"},
+		{Code: 500, Reason: "DB_ERROR", Message: "数据库错误"},
+	}
+	got := Causes(FromError(top.GRPCStatus().Err()))
+	if len(got) != len(want) {
+		t.Fatalf("cause链应该有 %d 层, 实际: %v", len(want), got)
+	}
+	for i := range want {
+		if got[i].Code != want[i].Code || got[i].Reason != want[i].Reason || got[i].Message != want[i].Message {
+			t.Errorf("第%d层cause应该是 %v, 实际: %v", i, want[i], got[i])
+		}
+	}
+
+	if Causes(BadRequest("NO_CAUSE", "")) != nil {
+		t.Error("没有cause时应该返回nil")
+	}
+}
@@ -0,0 +1,44 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// ErrChecksumMismatch is returned by DecodeErrorID when the checksum of an ID
+// does not match its payload, which usually means the ID was truncated or
+// mangled while being copied.
+var ErrChecksumMismatch = stderrors.New("error ID checksum mismatch")
+
+// checksumVersion 从该版本开始ID末尾带有校验字段
+const checksumVersion = 2
+
+// checksumField 校验字段的前缀
+const checksumField = "c="
+
+// idChecksum 计算ID载荷的校验值：FNV-1a 32位哈希的低字节
+func idChecksum(body string) string {
+	h := fnv.New32a()
+	h.Write([]byte(body))
+	return fmt.Sprintf("%02x", byte(h.Sum32()))
+}
+
+// appendChecksum 在ID载荷末尾追加校验字段
+func appendChecksum(body string) string {
+	return body + ":" + checksumField + idChecksum(body)
+}
+
+// verifyChecksum 校验并去掉ID载荷末尾的校验字段，返回不含校验字段的载荷
+func verifyChecksum(raw string) (string, error) {
+	i := strings.LastIndex(raw, ":"+checksumField)
+	if i < 0 || strings.Contains(raw[i+1:], ":") {
+		return raw, fmt.Errorf("%w: checksum field is missing", ErrChecksumMismatch)
+	}
+	body, sum := raw[:i], raw[i+1+len(checksumField):]
+	if want := idChecksum(body); sum != want {
+		return body, fmt.Errorf("%w: got %q, want %q", ErrChecksumMismatch, sum, want)
+	}
+	return body, nil
+}
@@ -0,0 +1,44 @@
+package errors
+
+import (
+	"encoding/base64"
+	stderrors "errors"
+	"strings"
+	"testing"
+)
+
+func TestErrorIDChecksum(t *testing.T) {
+	id := New(404, "NOT_FOUND", "不存在").ID
+	raw, err := decodeID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(raw), ":"+checksumField) {
+		t.Fatalf("新生成的ID应该包含校验字段, 实际: %s", raw)
+	}
+	if _, err := DecodeErrorID(id); err != nil {
+		t.Fatalf("完整的ID应该通过校验: %v", err)
+	}
+
+	// 截断的ID
+	truncated := encodeID(raw[:len(raw)-5])
+	if _, err := DecodeErrorID(truncated); !stderrors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("截断的ID应该返回ErrChecksumMismatch, 实际: %v", err)
+	}
+
+	// 被修改的ID
+	tampered := []byte(string(raw))
+	tampered[len("v2:")] ^= 1
+	if _, err := DecodeErrorID(encodeID(tampered)); !stderrors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("被修改的ID应该返回ErrChecksumMismatch, 实际: %v", err)
+	}
+}
+
+func TestErrorIDWithoutChecksumV1(t *testing.T) {
+	// 版本1的ID没有校验字段，仍然可以解码
+	v1 := base64.StdEncoding.EncodeToString([]byte("v1:GetUser@user_logic.go:25:1640995200123456789:1:12345:a1b2c3d4"))
+	info, err := DecodeErrorID(v1)
+	if err != nil || info.Version != 1 || info.Function != "GetUser" || info.RandomSuffix != "a1b2c3d4" {
+		t.Errorf("版本1的ID应该可以解码, 实际: %+v, %v", info, err)
+	}
+}
@@ -0,0 +1,92 @@
+package errors
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc/codes"
+)
+
+// 状态码映射的覆盖表。读取走 atomic，写入时加锁复制，适合在 init 中设置
+var (
+	codeMapMu  sync.Mutex
+	httpToGRPC atomic.Pointer[map[int]codes.Code]
+	grpcToHTTP atomic.Pointer[map[codes.Code]int]
+)
+
+// SetHTTPToGRPCMapping replaces the overrides used by ToGRPCCode. Codes that
+// are not in m keep the default mapping; a nil or empty m removes all
+// overrides. It is safe for concurrent use but is meant to be called during
+// initialization.
+func SetHTTPToGRPCMapping(m map[int]codes.Code) {
+	codeMapMu.Lock()
+	defer codeMapMu.Unlock()
+	httpToGRPC.Store(copyMapping(m))
+}
+
+// SetGRPCToHTTPMapping replaces the overrides used by ToHTTPCode, and thus by
+// FromError for plain gRPC status errors. Codes that are not in m keep the
+// default mapping; a nil or empty m removes all overrides.
+func SetGRPCToHTTPMapping(m map[codes.Code]int) {
+	codeMapMu.Lock()
+	defer codeMapMu.Unlock()
+	grpcToHTTP.Store(copyMapping(m))
+}
+
+// RegisterHTTPToGRPC overrides the gRPC code ToGRPCCode returns for httpCode,
+// keeping the other overrides.
+func RegisterHTTPToGRPC(httpCode int, grpcCode codes.Code) {
+	codeMapMu.Lock()
+	defer codeMapMu.Unlock()
+	httpToGRPC.Store(withMapping(httpToGRPC.Load(), httpCode, grpcCode))
+}
+
+// RegisterGRPCToHTTP overrides the HTTP code ToHTTPCode returns for grpcCode,
+// keeping the other overrides.
+func RegisterGRPCToHTTP(grpcCode codes.Code, httpCode int) {
+	codeMapMu.Lock()
+	defer codeMapMu.Unlock()
+	grpcToHTTP.Store(withMapping(grpcToHTTP.Load(), grpcCode, httpCode))
+}
+
+// httpToGRPCOverride 查找HTTP状态码的覆盖映射
+func httpToGRPCOverride(code int) (codes.Code, bool) {
+	if m := httpToGRPC.Load(); m != nil {
+		c, ok := (*m)[code]
+		return c, ok
+	}
+	return 0, false
+}
+
+// grpcToHTTPOverride 查找gRPC状态码的覆盖映射
+func grpcToHTTPOverride(code codes.Code) (int, bool) {
+	if m := grpcToHTTP.Load(); m != nil {
+		c, ok := (*m)[code]
+		return c, ok
+	}
+	return 0, false
+}
+
+// copyMapping 复制映射表，空表返回nil表示没有覆盖
+func copyMapping[K comparable, V any](m map[K]V) *map[K]V {
+	if len(m) == 0 {
+		return nil
+	}
+	c := make(map[K]V, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return &c
+}
+
+// withMapping 返回添加了一项映射的新表，不修改原表
+func withMapping[K comparable, V any](old *map[K]V, k K, v V) *map[K]V {
+	c := make(map[K]V)
+	if old != nil {
+		for key, value := range *old {
+			c[key] = value
+		}
+	}
+	c[k] = v
+	return &c
+}
@@ -0,0 +1,55 @@
+package errors
+
+import (
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCToHTTPMappingOverride(t *testing.T) {
+	SetGRPCToHTTPMapping(map[codes.Code]int{codes.FailedPrecondition: http.StatusPreconditionFailed})
+	defer SetGRPCToHTTPMapping(nil)
+
+	if got := ToHTTPCode(codes.FailedPrecondition); got != http.StatusPreconditionFailed {
+		t.Errorf("FailedPrecondition应该映射为412, 实际: %d", got)
+	}
+	if got := ToHTTPCode(codes.NotFound); got != http.StatusNotFound {
+		t.Errorf("未覆盖的状态码应该使用默认映射, 实际: %d", got)
+	}
+
+	converted := FromError(status.Error(codes.FailedPrecondition, "余额不足"))
+	if converted.Code != http.StatusPreconditionFailed {
+		t.Errorf("FromError应该使用覆盖的映射, 实际: %d", converted.Code)
+	}
+
+	SetGRPCToHTTPMapping(nil)
+	if got := ToHTTPCode(codes.FailedPrecondition); got != http.StatusBadRequest {
+		t.Errorf("重置后应该恢复默认映射, 实际: %d", got)
+	}
+}
+
+func TestRegisterCodeMapping(t *testing.T) {
+	defer SetHTTPToGRPCMapping(nil)
+	defer SetGRPCToHTTPMapping(nil)
+
+	RegisterHTTPToGRPC(http.StatusPreconditionFailed, codes.FailedPrecondition)
+	RegisterHTTPToGRPC(http.StatusUnprocessableEntity, codes.InvalidArgument)
+	RegisterGRPCToHTTP(codes.OutOfRange, http.StatusUnprocessableEntity)
+
+	if got := ToGRPCCode(http.StatusPreconditionFailed); got != codes.FailedPrecondition {
+		t.Errorf("412应该映射为FailedPrecondition, 实际: %v", got)
+	}
+	if got := ToGRPCCode(http.StatusUnprocessableEntity); got != codes.InvalidArgument {
+		t.Errorf("逐个注册不应该覆盖之前的注册, 实际: %v", got)
+	}
+	if got := ToHTTPCode(codes.OutOfRange); got != http.StatusUnprocessableEntity {
+		t.Errorf("OutOfRange应该映射为422, 实际: %d", got)
+	}
+
+	st := New(http.StatusPreconditionFailed, "VERSION_MISMATCH", "版本不匹配").GRPCStatus()
+	if st.Code() != codes.FailedPrecondition {
+		t.Errorf("GRPCStatus应该使用覆盖的映射, 实际: %v", st.Code())
+	}
+}
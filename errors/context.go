@@ -0,0 +1,132 @@
+package errors
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ContextMetadataFunc extracts request-scoped values, such as trace or request
+// IDs, from a context to be added to error metadata.
+type ContextMetadataFunc func(ctx context.Context) map[string]string
+
+var (
+	contextMetadataMu    sync.RWMutex
+	contextMetadataFuncs []ContextMetadataFunc
+)
+
+// RegisterContextMetadata adds f to the functions consulted by FromContext.
+// Register them during initialisation.
+func RegisterContextMetadata(f ContextMetadataFunc) {
+	if f == nil {
+		return
+	}
+	contextMetadataMu.Lock()
+	defer contextMetadataMu.Unlock()
+	contextMetadataFuncs = append(contextMetadataFuncs, f)
+}
+
+// FromContext is like FromError but also merges the metadata extracted from ctx
+// by the functions registered with RegisterContextMetadata. Metadata already
+// present on the error wins over extracted values.
+func FromContext(ctx context.Context, err error) *Error {
+	se := FromError(err)
+	if se == nil || ctx == nil {
+		return se
+	}
+
+	contextMetadataMu.RLock()
+	defer contextMetadataMu.RUnlock()
+	for _, f := range contextMetadataFuncs {
+		se = se.WithDefaultMetadata(f(ctx))
+	}
+	return se
+}
+
+// WithDefaultMetadata returns a copy of the error with the entries of md whose
+// keys are not already present in its metadata. It returns the error itself
+// when there is nothing to add.
+func (e *Error) WithDefaultMetadata(md map[string]string) *Error {
+	var err *Error
+	for k, v := range md {
+		if _, exists := e.Metadata[k]; exists {
+			continue
+		}
+		if err == nil {
+			err = Clone(e)
+		}
+		err.Metadata[k] = v
+	}
+	if err == nil {
+		return e
+	}
+	return err
+}
+
+var traceIDFunc atomic.Pointer[func(ctx context.Context) string]
+
+// SetTraceIDFunc sets the function NewContext uses to find the trace ID of a
+// context, such as the one installed by the errors/errorsotel package.
+// Passing nil disables trace ID embedding.
+func SetTraceIDFunc(f func(ctx context.Context) string) {
+	if f == nil {
+		traceIDFunc.Store(nil)
+		return
+	}
+	traceIDFunc.Store(&f)
+}
+
+// NewContext is like New but, when a trace ID function is set with
+// SetTraceIDFunc and ctx carries a trace ID, embeds the trace ID in the error
+// ID so that DecodeErrorID can report it.
+func NewContext(ctx context.Context, code int, reason, message string) *Error {
+	e := &Error{
+		Status: Status{
+			Code:    int32(code),
+			Reason:  reason,
+			Message: message,
+			ID:      generateErrorID(2), // skip NewContext and the caller
+		},
+		stack: captureStack(2),
+	}
+	e.attachTraceID(ctx)
+	return e
+}
+
+// attachTraceID 设置了追踪ID函数且上下文带有追踪ID时，将其追加到错误ID末尾
+func (e *Error) attachTraceID(ctx context.Context) {
+	f := traceIDFunc.Load()
+	if f == nil || ctx == nil {
+		return
+	}
+	if traceID := (*f)(ctx); traceID != "" {
+		e.ID = appendTraceID(e.id(), traceID)
+	}
+}
+
+// appendTraceID 在默认格式的错误ID末尾追加追踪ID字段，备用ID、精简ID和自定义生成器的ID保持不变
+func appendTraceID(id, traceID string) string {
+	if id == "" || traceID == "" {
+		return id
+	}
+	decoded, err := decodeID(id)
+	if err != nil {
+		return id
+	}
+	raw := string(decoded)
+	info, err := decodeRawErrorID(raw)
+	if err != nil || info.IsFallback || info.IsCompact {
+		return id
+	}
+	// 校验字段必须在最后，追加字段前先去掉，追加后重新计算
+	if info.Version >= checksumVersion {
+		raw = raw[:strings.LastIndex(raw, ":"+checksumField)]
+	}
+	// 与构建标识相同，追踪ID中的冒号会破坏ID格式
+	raw += ":" + traceIDField + strings.ReplaceAll(traceID, ":", "_")
+	if info.Version >= checksumVersion {
+		raw = appendChecksum(raw)
+	}
+	return encodeID([]byte(raw))
+}
@@ -0,0 +1,68 @@
+package errors
+
+import (
+	"context"
+	"testing"
+)
+
+type requestIDKey struct{}
+
+func TestFromContext(t *testing.T) {
+	RegisterContextMetadata(func(ctx context.Context) map[string]string {
+		if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+			return map[string]string{"request_id": id, "user_id": "from-ctx"}
+		}
+		return nil
+	})
+	t.Cleanup(func() {
+		contextMetadataMu.Lock()
+		contextMetadataFuncs = nil
+		contextMetadataMu.Unlock()
+	})
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-1")
+	origin := NotFound("USER_NOT_FOUND", "用户不存在").WithMetadata(map[string]string{"user_id": "42"})
+
+	got := FromContext(ctx, origin)
+	if got.Metadata["request_id"] != "req-1" {
+		t.Errorf("上下文中的值应该写入metadata, 实际: %v", got.Metadata)
+	}
+	if got.Metadata["user_id"] != "42" {
+		t.Errorf("已有的metadata应该优先, 实际: %v", got.Metadata)
+	}
+	if _, ok := origin.Metadata["request_id"]; ok {
+		t.Error("不应该修改原始错误")
+	}
+
+	if plain := FromContext(context.Background(), origin); plain != origin {
+		t.Error("没有可合并的值时应该返回原错误")
+	}
+	if FromContext(ctx, nil) != nil {
+		t.Error("nil错误应该返回nil")
+	}
+}
+
+func TestNewContextEmbedsTraceID(t *testing.T) {
+	SetTraceIDFunc(func(ctx context.Context) string {
+		id, _ := ctx.Value(requestIDKey{}).(string)
+		return id
+	})
+	t.Cleanup(func() { SetTraceIDFunc(nil) })
+
+	err := NewContext(context.WithValue(context.Background(), requestIDKey{}, "4bf92f3577b34da6a3ce929d0e0e4736"), 500, "INTERNAL", "内部错误")
+	info, decodeErr := DecodeErrorID(err.ID)
+	if decodeErr != nil {
+		t.Fatalf("解码错误ID失败: %v", decodeErr)
+	}
+	if info.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("错误ID应该包含追踪ID, 实际: %q", info.TraceID)
+	}
+	if info.Function != "TestNewContextEmbedsTraceID" {
+		t.Errorf("错误ID应该指向调用者, 实际: %s", info.Function)
+	}
+
+	plain := NewContext(context.Background(), 500, "INTERNAL", "内部错误")
+	if info, _ := DecodeErrorID(plain.ID); info.TraceID != "" {
+		t.Errorf("没有追踪ID时不应该追加字段, 实际: %q", info.TraceID)
+	}
+}
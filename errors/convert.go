@@ -0,0 +1,68 @@
+package errors
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Converter converts a foreign error type into an *Error. It returns nil when
+// it does not recognise err. The returned Error does not need an ID or cause:
+// FromError generates the ID and keeps err as the cause when they are unset.
+type Converter func(err error) *Error
+
+var (
+	convertersMu sync.RWMutex
+	converters   []Converter
+)
+
+// RegisterConverter adds c to the converters consulted by FromError for errors
+// that are neither an *Error nor a gRPC status. Converters run in registration
+// order and the first non-nil result wins. Register converters during
+// initialisation, e.g. by importing a package such as errors/gozero.
+func RegisterConverter(c Converter) {
+	if c == nil {
+		return
+	}
+	convertersMu.Lock()
+	defer convertersMu.Unlock()
+	converters = append(converters, c)
+}
+
+// convertForeign 依次调用已注册的转换器，均无法识别时返回nil
+func convertForeign(err error) *Error {
+	convertersMu.RLock()
+	defer convertersMu.RUnlock()
+	for _, c := range converters {
+		if appErr := c(err); appErr != nil {
+			return appErr
+		}
+	}
+	return nil
+}
+
+// unknownStatus FromError 无法识别错误时使用的 code 和 reason
+type unknownStatus struct {
+	code   int32
+	reason string
+}
+
+// unknownError 通过 SetUnknownError 设置的兜底状态，nil 表示 UnknownCode 和 UnknownReason
+var unknownError atomic.Pointer[unknownStatus]
+
+// SetUnknownError sets the code and reason FromError gives to errors it does
+// not recognise: errors that are neither an *Error nor a gRPC status, are
+// not recognised by a registered Converter and carry no context or HTTP
+// status. Code and Reason report the same values for such errors. The
+// defaults are UnknownCode and UnknownReason; SetUnknownError(UnknownCode,
+// UnknownReason) restores them.
+func SetUnknownError(code int, reason string) {
+	unknownError.Store(&unknownStatus{code: int32(code), reason: reason})
+}
+
+// currentUnknownError 返回当前的兜底 code 和 reason
+func currentUnknownError() (int32, string) {
+	if u := unknownError.Load(); u != nil {
+		return u.code, u.reason
+	}
+	return UnknownCode, UnknownReason
+}
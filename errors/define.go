@@ -0,0 +1,70 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+)
+
+// Definition is a catalog entry for an error: a code, reason and default
+// message declared once at package scope with Define. It carries no ID;
+// New and Newf create the occurrences returned to callers, each with its own
+// ID and stack recorded at the call site:
+//
+//	var ErrUserNotFound = errors.Define(404, "USER_NOT_FOUND", "user not found")
+//
+//	return ErrUserNotFound.New(ctx)
+//
+// A Definition is an error itself, so occurrences can be checked with
+// errors.Is(err, ErrUserNotFound), which compares code and reason.
+type Definition struct {
+	code    int32
+	reason  string
+	message string
+}
+
+// Define declares an error with the given code, reason and default message.
+func Define(code int, reason, message string) *Definition {
+	return &Definition{code: int32(code), reason: reason, message: message}
+}
+
+// Code returns the code of the definition.
+func (d *Definition) Code() int32 { return d.code }
+
+// Reason returns the reason of the definition.
+func (d *Definition) Reason() string { return d.reason }
+
+// Message returns the default message of the definition.
+func (d *Definition) Message() string { return d.message }
+
+// Error implements the error interface so that a Definition can be the
+// target of errors.Is.
+func (d *Definition) Error() string {
+	return fmt.Sprintf("error: code = %d reason = %s message = %s", d.code, d.reason, d.message)
+}
+
+// New returns an occurrence of the definition with the default message. As
+// with NewContext, the trace ID of ctx is embedded in the error ID when a
+// trace ID function is set; ctx may be nil.
+func (d *Definition) New(ctx context.Context) *Error {
+	return d.newError(ctx, d.message)
+}
+
+// Newf is like New but formats the default message with args, as Newf does.
+func (d *Definition) Newf(ctx context.Context, args ...any) *Error {
+	return d.newError(ctx, fmt.Sprintf(d.message, args...))
+}
+
+// newError 创建一次错误实例，ID和调用栈指向 New/Newf 的调用者
+func (d *Definition) newError(ctx context.Context, message string) *Error {
+	e := &Error{
+		Status: Status{
+			Code:    d.code,
+			Reason:  d.reason,
+			Message: message,
+		},
+		stack: captureStack(3),
+	}
+	e.initID(3) // skip newError, New/Newf and the caller
+	e.attachTraceID(ctx)
+	return e
+}
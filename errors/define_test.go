@@ -0,0 +1,62 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+var errDefinedNotFound = Define(404, "USER_NOT_FOUND", "用户 %s 不存在")
+
+func TestDefinitionNew(t *testing.T) {
+	first := errDefinedNotFound.New(context.Background())
+	second := errDefinedNotFound.New(context.Background())
+
+	if first.ID == "" || second.ID == "" || first.ID == second.ID {
+		t.Fatalf("每次实例化应该生成新的错误ID，实际: %q %q", first.ID, second.ID)
+	}
+	a, b := first.Status, second.Status
+	a.ID, b.ID = "", ""
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("两次实例化应该只有ID不同，实际: %+v != %+v", a, b)
+	}
+
+	info, err := DecodeErrorID(first.ID)
+	if err != nil {
+		t.Fatalf("错误ID应该可以解码: %v", err)
+	}
+	if info.Function != "TestDefinitionNew" || info.File != "define_test.go" {
+		t.Errorf("错误ID应该指向调用者，实际: %s@%s:%d", info.Function, info.File, info.Line)
+	}
+
+	if got := errDefinedNotFound.Newf(context.Background(), "42"); got.Message != "用户 42 不存在" || got.Code != 404 {
+		t.Errorf("Newf 应该格式化默认消息，实际: %+v", got.Status)
+	}
+}
+
+func TestDefinitionIs(t *testing.T) {
+	err := fmt.Errorf("query: %w", errDefinedNotFound.New(context.Background()))
+	if !Is(err, errDefinedNotFound) {
+		t.Error("实例应该与错误定义匹配")
+	}
+	if Is(NotFound("ORDER_NOT_FOUND", "订单不存在"), errDefinedNotFound) {
+		t.Error("reason不同的错误不应该与错误定义匹配")
+	}
+	if !Is(NotFound("USER_NOT_FOUND", "用户不存在"), errDefinedNotFound) {
+		t.Error("code和reason相同的错误应该与错误定义匹配")
+	}
+}
+
+func TestDefinitionNewContext(t *testing.T) {
+	SetTraceIDFunc(func(context.Context) string { return "trace-1" })
+	t.Cleanup(func() { SetTraceIDFunc(nil) })
+
+	info, err := DecodeErrorID(errDefinedNotFound.New(context.Background()).ID)
+	if err != nil {
+		t.Fatalf("错误ID应该可以解码: %v", err)
+	}
+	if info.TraceID != "trace-1" || info.Function != "TestDefinitionNewContext" {
+		t.Errorf("错误ID应该包含追踪ID并指向调用者，实际: %+v", info)
+	}
+}
@@ -0,0 +1,44 @@
+package errors
+
+import "sync/atomic"
+
+// metadataKeyDomain gRPC metadata中传递错误的域
+const metadataKeyDomain = "domain"
+
+// defaultDomain 没有通过 WithDomain 设置域的错误使用的域
+var defaultDomain atomic.Pointer[string]
+
+// SetDefaultDomain sets the domain, typically the name of the service such as
+// "user-service", reported by GetDomain for errors without a domain of their
+// own. Errors received from other services keep the domain they were sent
+// with. An empty domain, the default, leaves such errors without a domain.
+func SetDefaultDomain(domain string) {
+	defaultDomain.Store(&domain)
+}
+
+// currentDefaultDomain 返回当前设置的默认域
+func currentDefaultDomain() string {
+	if domain := defaultDomain.Load(); domain != nil {
+		return *domain
+	}
+	return ""
+}
+
+// WithDomain returns a copy of the error in the given domain, which
+// namespaces its reason, e.g. to tell the NOT_FOUND of one service from
+// another's.
+func (e *Error) WithDomain(domain string) *Error {
+	err := Clone(e)
+	err.Domain = domain
+	return err
+}
+
+// GetDomain returns the domain of the error: the one set with WithDomain or
+// received with the error, the default set with SetDefaultDomain otherwise.
+// The domain is sent with GRPCStatus and written by MarshalJSON.
+func (e *Error) GetDomain() string {
+	if e.Domain != "" {
+		return e.Domain
+	}
+	return currentDefaultDomain()
+}
@@ -0,0 +1,65 @@
+package errors
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDefaultDomain(t *testing.T) {
+	err := NotFound("NOT_FOUND", "用户不存在")
+	if err.GetDomain() != "" {
+		t.Errorf("默认不应该有域，实际: %q", err.GetDomain())
+	}
+
+	SetDefaultDomain("user-service")
+	t.Cleanup(func() { SetDefaultDomain("") })
+
+	if err.GetDomain() != "user-service" {
+		t.Errorf("应该使用默认域，实际: %q", err.GetDomain())
+	}
+	got := FromError(err.GRPCStatus().Err())
+	if got.Domain != "user-service" || got.Metadata[metadataKeyDomain] != "" {
+		t.Errorf("域应该通过gRPC传递且不留在metadata中，实际: %q %v", got.Domain, got.Metadata)
+	}
+
+	data, _ := json.Marshal(err)
+	var body map[string]any
+	if jsonErr := json.Unmarshal(data, &body); jsonErr != nil || body["domain"] != "user-service" {
+		t.Errorf("JSON应该包含默认域，实际: %s", data)
+	}
+}
+
+func TestWithDomain(t *testing.T) {
+	SetDefaultDomain("user-service")
+	t.Cleanup(func() { SetDefaultDomain("") })
+
+	local := NotFound("NOT_FOUND", "用户不存在")
+	remote := NotFound("NOT_FOUND", "订单不存在").WithDomain("order-service")
+	if remote.GetDomain() != "order-service" || local.Domain != "" {
+		t.Errorf("WithDomain 应该覆盖默认域且不修改原错误，实际: %q %q", remote.GetDomain(), local.Domain)
+	}
+
+	// 接收方的默认域不应该覆盖发送方的域
+	got := FromError(remote.GRPCStatus().Err())
+	if got.GetDomain() != "order-service" {
+		t.Errorf("接收的错误应该保留发送方的域，实际: %q", got.GetDomain())
+	}
+
+	SetErrorInfoDomain("example.com")
+	t.Cleanup(func() { SetErrorInfoDomain("") })
+	if info := errorInfoDetail(remote.statusDetail()); info.Domain != "order-service" {
+		t.Errorf("ErrorInfo 应该优先使用错误自身的域，实际: %q", info.Domain)
+	}
+
+	if Is(remote, local) {
+		t.Error("域不同的错误不应该匹配")
+	}
+	if !Is(remote, NotFound("NOT_FOUND", "").WithDomain("order-service")) {
+		t.Error("域、code和reason都相同的错误应该匹配")
+	}
+
+	SetDefaultDomain("")
+	if !Is(remote, NotFound("NOT_FOUND", "未找到")) {
+		t.Error("只有一方有域时应该只比较code和reason")
+	}
+}
@@ -0,0 +1,47 @@
+package errors
+
+import (
+	"sync/atomic"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+
+	errorspb "github.com/honeybbq/go-zero-errors-proto/errors"
+)
+
+// errorInfoDomain 附加 google.rpc.ErrorInfo 时使用的域，为空时不附加
+var errorInfoDomain atomic.Pointer[string]
+
+// SetErrorInfoDomain makes GRPCStatus additionally attach a standard
+// google.rpc.ErrorInfo detail with the error's reason, the given domain
+// (typically the service name, e.g. "user.example.com") and the metadata sent
+// with the errors detail, including error_id. Clients in other languages can
+// read it with the google.rpc protos, while Go clients keep using the errors
+// detail through FromError. An empty domain, the default, stops attaching it.
+func SetErrorInfoDomain(domain string) {
+	errorInfoDomain.Store(&domain)
+}
+
+// currentErrorInfoDomain 返回当前设置的 ErrorInfo 域
+func currentErrorInfoDomain() string {
+	if domain := errorInfoDomain.Load(); domain != nil {
+		return *domain
+	}
+	return ""
+}
+
+// errorInfoDetail 根据错误详情构建 google.rpc.ErrorInfo，未设置域时返回nil；
+// 错误自身的域（见 WithDomain）优先于设置的域
+func errorInfoDetail(d *errorspb.Status) *errdetails.ErrorInfo {
+	domain := currentErrorInfoDomain()
+	if domain == "" {
+		return nil
+	}
+	if v := d.Metadata[metadataKeyDomain]; v != "" {
+		domain = v
+	}
+	return &errdetails.ErrorInfo{
+		Reason:   d.Reason,
+		Domain:   domain,
+		Metadata: d.Metadata,
+	}
+}
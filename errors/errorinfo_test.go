@@ -0,0 +1,41 @@
+package errors
+
+import (
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+func TestSetErrorInfoDomain(t *testing.T) {
+	err := NotFound("USER_NOT_FOUND", "用户不存在").WithMetadata(map[string]string{"user_id": "42"})
+
+	if st := err.GRPCStatus(); len(st.Details()) != 1 {
+		t.Fatalf("默认不应该附加 ErrorInfo，实际: %v", st.Details())
+	}
+
+	SetErrorInfoDomain("user.example.com")
+	t.Cleanup(func() { SetErrorInfoDomain("") })
+
+	st := err.GRPCStatus()
+	var info *errdetails.ErrorInfo
+	for _, d := range st.Details() {
+		if v, ok := d.(*errdetails.ErrorInfo); ok {
+			info = v
+		}
+	}
+	if info == nil {
+		t.Fatalf("应该附加 google.rpc.ErrorInfo，实际: %v", st.Details())
+	}
+	if info.Reason != "USER_NOT_FOUND" || info.Domain != "user.example.com" {
+		t.Errorf("ErrorInfo 的 reason 和 domain 不正确: %v", info)
+	}
+	if info.Metadata["user_id"] != "42" || info.Metadata["error_id"] != err.ID {
+		t.Errorf("ErrorInfo 应该包含metadata和错误ID，实际: %v", info.Metadata)
+	}
+
+	// Go 客户端仍然通过专有详情还原错误
+	got := FromError(st.Err())
+	if got.Reason != "USER_NOT_FOUND" || got.ID != err.ID || got.Metadata["user_id"] != "42" {
+		t.Errorf("FromError 应该继续使用 errorspb.Status，实际: %+v", got.Status)
+	}
+}
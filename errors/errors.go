@@ -1,13 +1,12 @@
 package errors
 
 import (
-	"crypto/rand"
-	"encoding/base64"
+	"context"
 	stderrors "errors"
 	"fmt"
 	"net/http"
 	"os"
-	"path/filepath"
+	"reflect"
 	"runtime"
 	"strconv"
 	"strings"
@@ -15,6 +14,7 @@ import (
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
 	"google.golang.org/protobuf/types/known/anypb"
 
 	errorspb "github.com/honeybbq/go-zero-errors-proto/errors"
@@ -31,10 +31,21 @@ const (
 	UnknownCode = 500
 	// UnknownReason is unknown reason for error info.
 	UnknownReason = ""
+	// ContextCanceledReason is the reason FromError gives to context.Canceled.
+	ContextCanceledReason = "CONTEXT_CANCELED"
+	// DeadlineExceededReason is the reason FromError gives to
+	// context.DeadlineExceeded.
+	DeadlineExceededReason = "DEADLINE_EXCEEDED"
 	// SupportPackageIsVersion1 this constant should not be referenced by any other code.
 	SupportPackageIsVersion1 = true
 )
 
+// CurrentIDVersion is the version of the layout written by the error ID
+// generator. It is encoded as a "v<N>:" prefix in front of the payload so that
+// DecodeErrorID can keep decoding IDs produced by older layouts. IDs without
+// a prefix are treated as version 0.
+const CurrentIDVersion = 2
+
 // Status represents the error status
 type Status struct {
 	Code     int32             `json:"code,omitempty"`
@@ -42,12 +53,40 @@ type Status struct {
 	Message  string            `json:"message,omitempty"`
 	Metadata map[string]string `json:"metadata,omitempty"`
 	ID       string            `json:"id,omitempty"` // 错误ID，用于追踪
+	// Retryable reports whether the caller may retry. It is only meaningful
+	// when set explicitly with WithRetryable; use IsRetryable to also apply
+	// the defaults derived from Code.
+	Retryable bool `json:"retryable,omitempty"`
+	// Severity classifies the error for alerting. It is only meaningful when
+	// set explicitly with WithSeverity; use the Severity function to also
+	// apply the defaults derived from Code.
+	Severity SeverityLevel `json:"severity,omitempty"`
+	// Domain namespaces Reason, e.g. "user-service". Use GetDomain to also
+	// apply the default set with SetDefaultDomain.
+	Domain string `json:"domain,omitempty"`
+	// HTTPStatus is the HTTP status code sent for the error when it differs
+	// from Code, e.g. for a business code such as 40401. Use StatusCode to
+	// get the status that applies.
+	HTTPStatus int `json:"http_status,omitempty"`
+}
+
+// StatusCoder is implemented by errors that carry an HTTP status code, such as
+// client errors wrapping an *http.Response. FromError uses the status code of
+// the first StatusCoder found in the chain as the Code of the converted Error.
+type StatusCoder interface {
+	StatusCode() int
 }
 
 // Error is a status error.
 type Error struct {
 	Status
 	cause error
+	stack []uintptr // 创建时的调用栈，仅在 SetStackCaptureDepth 开启时记录
+	lazy  *lazyID   // 延迟生成ID时记录的创建现场，见 SetLazyIDGeneration
+	kind  *Kind     // 错误类别，见 WithKind
+
+	retryableSet bool // Retryable 是否被显式设置
+	pooled       bool // 来自 AcquireError 且尚未释放
 }
 
 // getGoroutineID 获取当前goroutine ID
@@ -90,14 +129,14 @@ func generateRandomSuffix() (result string) {
 	defer func() {
 		if r := recover(); r != nil {
 			// 发生 panic 时返回简单的时间戳
-			result = fmt.Sprintf("%x", time.Now().UnixNano()&0xFFFFFFFF)
+			result = fmt.Sprintf("%x", idNow().UnixNano()&0xFFFFFFFF)
 		}
 	}()
 
-	buf := make([]byte, 4)
-	if _, err := rand.Read(buf); err != nil {
+	buf := make([]byte, currentRandomSuffixBytes())
+	if err := readRandom(buf); err != nil {
 		// 如果随机数生成失败，使用时间戳作为后备
-		return fmt.Sprintf("%x", time.Now().UnixNano()&0xFFFFFFFF)
+		return fmt.Sprintf("%x", idNow().UnixNano()&0xFFFFFFFF)
 	}
 	return fmt.Sprintf("%x", buf)
 }
@@ -112,13 +151,12 @@ func generateErrorID(skip int) string {
 		}
 	}()
 
-	// 使用内部函数尝试生成完整的错误ID
-	if id := tryGenerateErrorID(skip + 1); id != "" {
-		return id
+	if !IDGenerationEnabled() {
+		return ""
 	}
 
-	// 如果内部函数失败，返回备用ID
-	return generateFallbackErrorID()
+	// 通过当前配置的生成器生成，默认生成器在失败时会返回备用ID
+	return currentIDGenerator().Generate(skip)
 }
 
 // tryGenerateErrorID 尝试生成错误ID，如果失败返回空字符串
@@ -130,53 +168,84 @@ func tryGenerateErrorID(skip int) (result string) {
 		}
 	}()
 
-	return generateErrorIDInternal(skip)
+	return generateErrorIDInternal(skip + 1)
 }
 
 // generateErrorIDInternal 内部实现，包含实际的ID生成逻辑
 func generateErrorIDInternal(skip int) string {
-	// 完整版本 - 包含详细信息
-	// 获取调用者信息
-	pc, file, line, ok := runtime.Caller(skip)
-	var filename, funcName string
+	// 获取调用者信息，skip 只是起点，之后跳过本包内的栈帧
+	var pcs [callerSearchDepth]uintptr
+	n := runtime.Callers(skip+1, pcs[:])
+	funcName, filename, line := callerSite(pcs[:n])
 
-	if !ok {
-		filename = "unknown"
-		funcName = "unknown"
-		line = 0
-	} else {
-		// 文件名 - 只保留文件名，不要完整路径
-		filename = filepath.Base(file)
-
-		// 函数信息 - 简化处理
-		fn := runtime.FuncForPC(pc)
-		if fn != nil {
-			fullName := fn.Name()
-			// 只保留函数名部分，去掉包路径
-			if lastDot := findLastDot(fullName); lastDot >= 0 && lastDot < len(fullName)-1 {
-				funcName = fullName[lastDot+1:]
-			} else if lastDot == len(fullName)-1 {
-				// 如果点在最后，使用完整名称
-				funcName = fullName
-			} else {
-				funcName = fullName
-			}
-		} else {
-			funcName = "unknown"
+	// 获取关键debug信息
+	timestamp := idNow().UnixNano()
+	var goroutineID uint64
+	if !goroutineIDDisabled.Load() {
+		goroutineID = getGoroutineID()
+	}
+	return formatErrorID(funcName, filename, line, timestamp, goroutineID)
+}
+
+// callerSearchDepth 查找包外调用者时最多检查的栈帧数
+const callerSearchDepth = 8
+
+// packagePrefix 本包函数全名的前缀，用于识别包内的栈帧
+var packagePrefix = reflect.TypeOf(Error{}).PkgPath() + "."
+
+// callerSite 返回 pcs 中第一个不属于本包的栈帧的函数名、文件名和行号。
+// 不依赖固定的 skip 层数，因此经过便利函数或内联后仍指向用户代码；
+// 本包的测试文件视为用户代码。全部属于本包时使用第一个栈帧
+func callerSite(pcs []uintptr) (funcName, filename string, line int) {
+	// skip 超出调用栈深度时记录标记，避免与名为 unknown 的文件混淆
+	if len(pcs) == 0 {
+		return NoCallerMarker, NoCallerMarker, 0
+	}
+	frames := runtime.CallersFrames(pcs)
+	frame, more := frames.Next()
+	for first := frame; isPackageFrame(frame); {
+		if !more {
+			frame = first
+			break
 		}
+		frame, more = frames.Next()
 	}
 
-	// 获取关键debug信息
-	timestamp := time.Now().UnixNano()
-	goroutineID := getGoroutineID()
+	funcName, filename = "unknown", "unknown"
+	if frame.Function != "" {
+		funcName = shortFuncName(frame.Function)
+	}
+	if frame.File != "" {
+		filename = idFileName(frame.File)
+	}
+	return funcName, filename, frame.Line
+}
+
+// isPackageFrame 判断栈帧是否属于本包（不含子包和测试文件）
+func isPackageFrame(frame runtime.Frame) bool {
+	return strings.HasPrefix(frame.Function, packagePrefix) && !strings.HasSuffix(frame.File, "_test.go")
+}
+
+// shortFuncName 只保留函数名部分，去掉包路径
+func shortFuncName(fullName string) string {
+	if lastDot := findLastDot(fullName); lastDot >= 0 && lastDot < len(fullName)-1 {
+		return fullName[lastDot+1:]
+	}
+	// 如果点在最后或没有点，使用完整名称
+	return fullName
+}
+
+// formatErrorID 按默认生成器的格式拼接并编码ID
+func formatErrorID(funcName, filename string, line int, timestamp int64, goroutineID uint64) string {
 	pid := os.Getpid()
 	randomSuffix := generateRandomSuffix()
 
 	// 使用更高效的字符串构建 - 简化格式
-	// 格式: func@file:line:timestamp:gid:pid:random
+	// 格式: v2:func@file:line:timestamp:gid:pid:random[:b=build][:h=instance]:c=checksum
 	var builder strings.Builder
 	builder.Grow(128) // 预分配容量
 
+	builder.WriteString(idVersionPrefix)
 	builder.WriteString(funcName)
 	builder.WriteByte('@')
 	builder.WriteString(filename)
@@ -191,24 +260,78 @@ func generateErrorIDInternal(skip int) string {
 	builder.WriteByte(':')
 	builder.WriteString(randomSuffix)
 
-	// Base64编码
-	return base64.StdEncoding.EncodeToString([]byte(builder.String()))
+	// 可选的附加字段，格式为 :key=value
+	if buildID := currentBuildID(); buildID != "" {
+		builder.WriteString(":" + buildIDField)
+		builder.WriteString(buildID)
+	}
+	if instance := currentInstanceID(); instance != "" {
+		builder.WriteString(":" + instanceIDField)
+		builder.WriteString(instance)
+	}
+
+	// 校验字段必须是最后一个字段，用于发现被截断的ID
+	// Base64编码，见 SetIDEncoding
+	return encodeID([]byte(appendChecksum(builder.String())))
 }
 
 // generateFallbackErrorID 生成一个简单的备用错误ID
 func generateFallbackErrorID() string {
 	// 使用最基本的信息生成ID，避免复杂操作
-	timestamp := time.Now().UnixNano()
+	timestamp := idNow().UnixNano()
 	pid := os.Getpid()
 
 	// 使用简单的随机字节，避免复杂操作
 	randomBytes := make([]byte, 4)
-	rand.Read(randomBytes) // crypto/rand.Read 不会返回错误
+	_ = readRandom(randomBytes) // 读取失败时使用零值，备用ID仍然有效
 	randomNum := int64(randomBytes[0])<<24 | int64(randomBytes[1])<<16 | int64(randomBytes[2])<<8 | int64(randomBytes[3])
 
-	// 格式: fallback:timestamp:pid:random
-	fallbackID := fmt.Sprintf("fallback:%d:%d:%d", timestamp, pid, randomNum)
-	return base64.StdEncoding.EncodeToString([]byte(fallbackID))
+	// 格式: v2:fallback:timestamp:pid:random:c=checksum
+	fallbackID := fmt.Sprintf("%s%s%d:%d:%d", idVersionPrefix, fallbackIDPrefix, timestamp, pid, randomNum)
+	return encodeID([]byte(appendChecksum(fallbackID)))
+}
+
+// generateCompactErrorID 生成只包含时间戳和随机后缀的ID，见 CompactProfile
+func generateCompactErrorID() string {
+	// 格式: v2:compact:timestamp:random:c=checksum
+	compactID := idVersionPrefix + compactIDPrefix + strconv.FormatInt(idNow().UnixNano(), 10) + ":" + generateRandomSuffix()
+	return encodeID([]byte(appendChecksum(compactID)))
+}
+
+// buildIDField 构建标识附加字段的前缀
+const buildIDField = "b="
+
+// instanceIDField 实例标识附加字段的前缀
+const instanceIDField = "h="
+
+// traceIDField 追踪ID附加字段的前缀
+const traceIDField = "t="
+
+// NoCallerMarker is recorded as the function and file of an ID when the
+// generator was asked to skip more frames than the stack has, so that such
+// IDs cannot be mistaken for errors created in a file named "unknown".
+// DecodeErrorID reports them with ErrorIDInfo.NoCaller set.
+const NoCallerMarker = "__nocaller__"
+
+// fallbackIDPrefix 备用ID载荷的前缀
+const fallbackIDPrefix = "fallback:"
+
+// compactIDPrefix 精简ID载荷的前缀
+const compactIDPrefix = "compact:"
+
+// idVersionPrefix 当前版本的ID前缀
+var idVersionPrefix = "v" + strconv.Itoa(CurrentIDVersion) + ":"
+
+// splitIDVersion 拆分ID的版本前缀，没有前缀的旧格式视为版本0
+func splitIDVersion(raw string) (int, string) {
+	if len(raw) > 1 && raw[0] == 'v' {
+		if i := strings.IndexByte(raw, ':'); i > 1 {
+			if version, err := strconv.Atoi(raw[1:i]); err == nil && version > 0 {
+				return version, raw[i+1:]
+			}
+		}
+	}
+	return 0, raw
 }
 
 // findLastSlash 找到最后一个斜杠的位置
@@ -232,33 +355,111 @@ type ErrorIDInfo struct {
 	RandomSuffix  string `json:"random_suffix"`  // 随机后缀
 	TimeFormatted string `json:"time_formatted"` // 格式化的时间
 	Raw           string `json:"raw"`            // 原始解码信息
+	Version       int    `json:"version"`        // ID格式版本，0表示无版本前缀的旧格式
+	IsFallback    bool   `json:"is_fallback"`    // 是否为备用ID，备用ID不包含函数、文件和行号
+	NoCaller      bool   `json:"no_caller"`      // 生成时没有找到调用位置，函数和文件为 NoCallerMarker
+	IsCompact     bool   `json:"is_compact"`     // 是否为精简ID，只包含时间戳和随机后缀，见 CompactProfile
+	BuildID       string `json:"build_id"`       // 生成ID的构建标识，见 SetBuildID
+	TraceID       string `json:"trace_id"`       // 生成ID时的追踪ID，见 NewContext
+	InstanceID    string `json:"instance_id"`    // 生成ID的实例标识，见 SetInstanceID
+	Extra         string `json:"extra"`          // 无法识别的附加字段，保留原样以兼容更新的格式
 }
 
-// DecodeErrorID 解码错误ID，返回结构化信息
+// Time returns the time the ID was generated, in the local time zone.
+func (info *ErrorIDInfo) Time() time.Time {
+	return time.Unix(0, info.Timestamp)
+}
+
+// Age returns how long ago the ID was generated. It is negative when the ID
+// comes from a host whose clock is ahead of this one.
+func (info *ErrorIDInfo) Age() time.Duration {
+	return time.Since(info.Time())
+}
+
+// DecodeErrorID decodes an ID produced by the default generator into an
+// ErrorIDInfo, the canonical decoded form used by the error-decoder CLI and
+// AuditRecord; Raw holds the decoded payload. IDs in either base64 alphabet,
+// with or without padding, are accepted.
 func DecodeErrorID(encodedID string) (*ErrorIDInfo, error) {
-	decoded, err := base64.StdEncoding.DecodeString(encodedID)
+	decoded, err := decodeID(encodedID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode error ID: %w", err)
 	}
 
-	raw := string(decoded)
-	info := &ErrorIDInfo{Raw: raw}
+	return decodeRawErrorID(string(decoded))
+}
+
+// DecodeErrorIDs decodes a batch of IDs, as DecodeErrorID would one by one,
+// for log processors that decode many IDs at a time. The results are in the
+// order of ids: errs[i] is the error DecodeErrorID would return for ids[i] and
+// infos[i] the ErrorIDInfo, nil when the ID is not valid base64. The decode
+// buffer is reused across IDs and the ErrorIDInfo values are allocated
+// together, so a batch allocates less than the equivalent loop.
+func DecodeErrorIDs(ids []string) ([]*ErrorIDInfo, []error) {
+	infos := make([]*ErrorIDInfo, len(ids))
+	errs := make([]error, len(ids))
+	slab := make([]ErrorIDInfo, len(ids))
+	var src, scratch []byte
+	for i, id := range ids {
+		src = append(src[:0], id...)
+		decoded, err := decodeIDInto(scratch, src)
+		if err != nil {
+			errs[i] = fmt.Errorf("failed to decode error ID: %w", err)
+			continue
+		}
+		scratch = decoded
+		infos[i], errs[i] = decodeRawErrorIDInto(&slab[i], string(decoded))
+	}
+	return infos, errs
+}
+
+// decodeRawErrorID 解析已经解码的原始ID载荷
+func decodeRawErrorID(raw string) (*ErrorIDInfo, error) {
+	return decodeRawErrorIDInto(new(ErrorIDInfo), raw)
+}
+
+// decodeRawErrorIDInto 与 decodeRawErrorID 相同，但将结果写入 info
+func decodeRawErrorIDInto(info *ErrorIDInfo, raw string) (*ErrorIDInfo, error) {
+	version, payload := splitIDVersion(raw)
+	*info = ErrorIDInfo{Raw: raw, Version: version}
+	if version > CurrentIDVersion {
+		return info, fmt.Errorf("unsupported error ID version %d", version)
+	}
+
+	// 版本2起末尾带有校验字段，校验失败说明ID被截断或修改
+	if version >= checksumVersion {
+		body, err := verifyChecksum(raw)
+		if err != nil {
+			return info, err
+		}
+		_, payload = splitIDVersion(body)
+	}
+
+	if strings.HasPrefix(payload, fallbackIDPrefix) {
+		return decodeFallbackErrorID(info, payload[len(fallbackIDPrefix):])
+	}
+	if strings.HasPrefix(payload, compactIDPrefix) {
+		return decodeCompactErrorID(info, payload[len(compactIDPrefix):])
+	}
 
-	// 解析格式: func@file:line:timestamp:gid:pid:random
-	parts := strings.Split(raw, ":")
+	// 各版本去掉校验字段后的载荷格式相同: func@file:line:timestamp:gid:pid:random[:key=value...]
+	// 前五个字段是固定位置的，最后一部分整体交给 decodeTrailingFields，其中的值可以包含冒号
+	parts := strings.SplitN(payload, ":", 6)
 	if len(parts) < 6 {
 		return info, fmt.Errorf("invalid error ID format, expected at least 6 parts, got %d", len(parts))
 	}
 
 	// 解析函数名和文件名 (func@file 格式)
 	funcFilePart := parts[0]
-	if atIndex := strings.LastIndex(funcFilePart, "@"); atIndex >= 0 {
+	// 函数名不含 @，完整路径可能含有 @（如模块缓存中的 mod@v1.0.0），因此按第一个 @ 切分
+	if atIndex := strings.Index(funcFilePart, "@"); atIndex >= 0 {
 		info.Function = funcFilePart[:atIndex]
 		info.File = funcFilePart[atIndex+1:]
 	} else {
 		info.Function = "unknown"
 		info.File = funcFilePart
 	}
+	info.NoCaller = info.Function == NoCallerMarker && info.File == NoCallerMarker
 
 	// 解析行号
 	if line, err := strconv.Atoi(parts[1]); err == nil {
@@ -282,30 +483,129 @@ func DecodeErrorID(encodedID string) (*ErrorIDInfo, error) {
 		info.ProcessID = pid
 	}
 
-	// 随机后缀
-	if len(parts) > 5 {
-		info.RandomSuffix = parts[5]
+	// 随机后缀和可选的附加字段
+	decodeTrailingFields(info, parts[5])
+
+	return info, nil
+}
+
+// decodeTrailingFields 解析随机后缀及其后的 key=value 附加字段。
+// 不像 key=value 的片段属于前一个字段的值，因此随机后缀和字段值可以包含冒号；
+// 无法识别的字段连同其值按原样保存在 Extra 中
+func decodeTrailingFields(info *ErrorIDInfo, rest string) {
+	var (
+		field  *string // 当前片段所属的已知字段，nil 表示属于最后一个无法识别的字段
+		extras []string
+	)
+	for i, segment := range strings.Split(rest, ":") {
+		switch {
+		case i == 0:
+			info.RandomSuffix = segment
+			field = &info.RandomSuffix
+		case strings.HasPrefix(segment, buildIDField):
+			info.BuildID = segment[len(buildIDField):]
+			field = &info.BuildID
+		case strings.HasPrefix(segment, traceIDField):
+			info.TraceID = segment[len(traceIDField):]
+			field = &info.TraceID
+		case strings.HasPrefix(segment, instanceIDField):
+			info.InstanceID = segment[len(instanceIDField):]
+			field = &info.InstanceID
+		case isFieldSegment(segment):
+			extras = append(extras, segment)
+			field = nil
+		case field != nil:
+			*field += ":" + segment
+		default:
+			extras[len(extras)-1] += ":" + segment
+		}
 	}
+	info.Extra = strings.Join(extras, ":")
+}
+
+// isFieldSegment 判断片段是否为 key=value 形式的附加字段，key 由小写字母组成
+func isFieldSegment(segment string) bool {
+	key, _, ok := strings.Cut(segment, "=")
+	if !ok || key == "" {
+		return false
+	}
+	for _, c := range key {
+		if c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeFallbackErrorID 解析备用ID的载荷: timestamp:pid:random
+func decodeFallbackErrorID(info *ErrorIDInfo, payload string) (*ErrorIDInfo, error) {
+	info.IsFallback = true
+
+	// 随机数是最后一个字段，限制切分次数以容忍其中的冒号
+	parts := strings.SplitN(payload, ":", 3)
+	if len(parts) != 3 {
+		return info, fmt.Errorf("invalid fallback error ID format, expected 3 parts, got %d", len(parts))
+	}
+
+	if timestamp, err := strconv.ParseInt(parts[0], 10, 64); err == nil {
+		info.Timestamp = timestamp
+		info.TimeFormatted = time.Unix(0, timestamp).Format("2006-01-02 15:04:05.000")
+	}
+	if pid, err := strconv.Atoi(parts[1]); err == nil {
+		info.ProcessID = pid
+	}
+	info.RandomSuffix = parts[2]
+
+	return info, nil
+}
+
+// decodeCompactErrorID 解析精简ID的载荷: timestamp:random
+func decodeCompactErrorID(info *ErrorIDInfo, payload string) (*ErrorIDInfo, error) {
+	info.IsCompact = true
+
+	timestamp, random, ok := strings.Cut(payload, ":")
+	if !ok {
+		return info, fmt.Errorf("invalid compact error ID format, expected 2 parts")
+	}
+	if ts, err := strconv.ParseInt(timestamp, 10, 64); err == nil {
+		info.Timestamp = ts
+		info.TimeFormatted = time.Unix(0, ts).Format("2006-01-02 15:04:05.000")
+	}
+	info.RandomSuffix = random
 
 	return info, nil
 }
 
 // Error implements the error interface.
 func (e *Error) Error() string {
-	if e.ID != "" {
+	if id := e.id(); id != "" {
 		return fmt.Sprintf("error: id = %s code = %d reason = %s message = %s metadata = %v cause = %v",
-			e.ID, e.Code, e.Reason, e.Message, e.Metadata, e.cause)
+			id, e.Code, e.Reason, e.Message, e.RedactedMetadata(), e.cause)
 	}
 	return fmt.Sprintf("error: code = %d reason = %s message = %s metadata = %v cause = %v",
-		e.Code, e.Reason, e.Message, e.Metadata, e.cause)
+		e.Code, e.Reason, e.Message, e.RedactedMetadata(), e.cause)
 }
 
 // Unwrap provides compatibility for Go 1.13 error chains.
 func (e *Error) Unwrap() error { return e.cause }
 
-// Is matches each error in the chain with the target value.
+// Is matches each error in the chain with the target value. If both errors
+// have a kind set with WithKind, they match when the kinds are the same;
+// otherwise they match when both Code and Reason are equal and, if both have
+// a domain, see GetDomain, the domains are equal too. A *Definition target
+// matches when Code and Reason are equal.
 func (e *Error) Is(err error) bool {
+	// 错误定义没有类别，只比较 code 和 reason
+	if d := new(Definition); stderrors.As(err, &d) {
+		return d.code == e.Code && d.reason == e.Reason
+	}
 	if se := new(Error); stderrors.As(err, &se) {
+		if se.kind != nil && e.kind != nil {
+			return se.kind == e.kind
+		}
+		if a, b := se.GetDomain(), e.GetDomain(); a != "" && b != "" && a != b {
+			return false
+		}
 		return se.Code == e.Code && se.Reason == e.Reason
 	}
 	return false
@@ -318,6 +618,28 @@ func (e *Error) WithCause(cause error) *Error {
 	return err
 }
 
+// WithMessage returns a copy of the error with its message replaced by msg.
+// The code, reason, ID, metadata and cause are kept.
+func (e *Error) WithMessage(msg string) *Error {
+	err := Clone(e)
+	err.Message = msg
+	return err
+}
+
+// WithMessagef is WithMessage(fmt.Sprintf(format, a...)).
+func (e *Error) WithMessagef(format string, a ...any) *Error {
+	return e.WithMessage(fmt.Sprintf(format, a...))
+}
+
+// WithCode returns a copy of the error with its code replaced by code, e.g.
+// to report a downstream 404 as a 502 from a gateway. The reason, message,
+// ID, metadata and cause are kept.
+func (e *Error) WithCode(code int) *Error {
+	err := Clone(e)
+	err.Code = int32(code)
+	return err
+}
+
 // WithMetadata with an MD formed by the mapping of key, value.
 func (e *Error) WithMetadata(md map[string]string) *Error {
 	err := Clone(e)
@@ -325,6 +647,24 @@ func (e *Error) WithMetadata(md map[string]string) *Error {
 	return err
 }
 
+// WithMetadataKV returns a copy of the error with key set to value in a copy
+// of its metadata. Unlike WithMetadata, the other keys are kept.
+func (e *Error) WithMetadataKV(key, value string) *Error {
+	err := Clone(e)
+	err.Metadata[key] = value
+	return err
+}
+
+// AppendMetadata returns a copy of the error whose metadata is a copy of the
+// existing metadata merged with md. Keys in md win on conflict.
+func (e *Error) AppendMetadata(md map[string]string) *Error {
+	err := Clone(e)
+	for k, v := range md {
+		err.Metadata[k] = v
+	}
+	return err
+}
+
 // WithID sets a custom error ID. If not called, a default ID will be generated.
 func (e *Error) WithID(id string) *Error {
 	err := Clone(e)
@@ -334,71 +674,139 @@ func (e *Error) WithID(id string) *Error {
 
 // GetID returns the error ID, generating one if it doesn't exist
 func (e *Error) GetID() string {
-	if e.ID == "" {
-		e.ID = generateErrorID(3) // skip GetID, caller, and the method that called GetID
+	if id := e.id(); id != "" {
+		return id
 	}
+	e.ID = generateErrorID(3) // skip GetID, caller, and the method that called GetID
 	return e.ID
 }
 
 // GRPCStatus returns the Status represented by se.
 func (e *Error) GRPCStatus() *status.Status {
 	// 确保有错误ID
-	if e.ID == "" {
+	if e.id() == "" {
 		e.ID = generateErrorID(3)
 	}
 
-	// 将错误ID添加到metadata中传递给gRPC
+	detail := e.statusDetail()
+	details := append([]protoadapt.MessageV1{detail}, causeDetails(e.cause)...)
+	// 为其他语言的客户端附加标准的 google.rpc.ErrorInfo
+	if info := errorInfoDetail(detail); info != nil {
+		details = append(details, info)
+	}
+	s, _ := status.New(e.GRPCCode(), e.Message).WithDetails(details...)
+	return s
+}
+
+// statusDetail 将错误转换为gRPC错误详情，错误ID等字段通过metadata传递
+func (e *Error) statusDetail() *errorspb.Status {
+	// 关闭ID生成时可能没有ID
 	metadata := make(map[string]string)
-	if e.Metadata != nil {
-		for k, v := range e.Metadata {
-			metadata[k] = v
-		}
+	for k, v := range e.RedactedMetadata() {
+		metadata[k] = v
+	}
+	if id := e.id(); id != "" {
+		metadata["error_id"] = id
+	}
+	if e.retryableSet {
+		metadata[metadataKeyRetryable] = strconv.FormatBool(e.Retryable)
+	}
+	if e.Severity != SeverityUnspecified {
+		metadata[metadataKeySeverity] = e.Severity.String()
+	}
+	if domain := e.GetDomain(); domain != "" {
+		metadata[metadataKeyDomain] = domain
+	}
+	if e.HTTPStatus != 0 {
+		metadata[metadataKeyHTTPStatus] = strconv.Itoa(e.HTTPStatus)
 	}
-	metadata["error_id"] = e.ID
 
-	s, _ := status.New(ToGRPCCode(int(e.Code)), e.Message).WithDetails(&errorspb.Status{
+	return &errorspb.Status{
 		Code:     e.Code,
 		Reason:   e.Reason,
 		Message:  e.Message,
 		Metadata: metadata,
-	})
-	return s
+	}
 }
 
 // New returns an error object for the code, reason, message.
 func New(code int, reason, message string) *Error {
-	return &Error{
+	e := &Error{
 		Status: Status{
 			Code:    int32(code),
 			Reason:  reason,
 			Message: message,
-			ID:      generateErrorID(2), // skip New and the caller
 		},
+		stack: captureStack(2),
 	}
+	e.initID(2) // skip New and the caller
+	return e
 }
 
 // Newf New(code, reason, fmt.Sprintf(format, a...))
 func Newf(code int, reason, format string, a ...any) *Error {
-	return &Error{
+	e := &Error{
 		Status: Status{
 			Code:    int32(code),
 			Reason:  reason,
 			Message: fmt.Sprintf(format, a...),
-			ID:      generateErrorID(2), // skip Newf and the caller
 		},
+		stack: captureStack(2),
 	}
+	e.initID(2) // skip Newf and the caller
+	return e
 }
 
 // Errorf returns an error object for the code, message and error info.
 func Errorf(code int, reason, format string, a ...any) error {
-	return &Error{
+	e := &Error{
 		Status: Status{
 			Code:    int32(code),
 			Reason:  reason,
 			Message: fmt.Sprintf(format, a...),
-			ID:      generateErrorID(2), // skip Errorf and the caller
 		},
+		stack: captureStack(2),
 	}
+	e.initID(2) // skip Errorf and the caller
+	return e
+}
+
+// Wrap returns an error object for the code, reason and message whose cause
+// is err, like fmt.Errorf("%w", err) but structured. The ID refers to the
+// caller of Wrap. It returns nil if err is nil.
+func Wrap(err error, code int, reason, message string) *Error {
+	if err == nil {
+		return nil
+	}
+	e := &Error{
+		Status: Status{
+			Code:    int32(code),
+			Reason:  reason,
+			Message: message,
+		},
+		cause: err,
+		stack: captureStack(2),
+	}
+	e.initID(2) // skip Wrap and the caller
+	return e
+}
+
+// Wrapf Wrap(err, code, reason, fmt.Sprintf(format, a...))
+func Wrapf(err error, code int, reason, format string, a ...any) *Error {
+	if err == nil {
+		return nil
+	}
+	e := &Error{
+		Status: Status{
+			Code:    int32(code),
+			Reason:  reason,
+			Message: fmt.Sprintf(format, a...),
+		},
+		cause: err,
+		stack: captureStack(2),
+	}
+	e.initID(2) // skip Wrapf and the caller
+	return e
 }
 
 // Clone deep clone error to a new error.
@@ -410,37 +818,91 @@ func Clone(err *Error) *Error {
 	for k, v := range err.Metadata {
 		metadata[k] = v
 	}
-	return &Error{
-		cause: err.cause,
-		Status: Status{
-			Code:     err.Code,
-			Reason:   err.Reason,
-			Message:  err.Message,
-			Metadata: metadata,
-			ID:       err.ID, // 保持原有ID
-		},
+	ret := &Error{
+		Status:       err.Status, // 保持原有ID
+		cause:        err.cause,
+		stack:        err.stack,
+		lazy:         err.lazy,
+		kind:         err.kind,
+		retryableSet: err.retryableSet,
+	}
+	ret.Metadata = metadata
+	return ret
+}
+
+// CloneWithNewID is like Clone but gives the copy its own ID and stack trace,
+// recorded at the caller, as New would. Use it to derive independent errors
+// from a package-level template error, so that errors returned by different
+// requests do not share the template's ID.
+func CloneWithNewID(err *Error) *Error {
+	if err == nil {
+		return nil
 	}
+	ret := Clone(err)
+	ret.ID = ""
+	ret.lazy = nil
+	ret.stack = captureStack(2)
+	ret.initID(2) // skip CloneWithNewID and the caller
+	return ret
 }
 
 // FromError try to convert an error to *Error.
-// It supports wrapped errors.
+// It supports wrapped errors. Unless a registered Converter recognises it,
+// a context.Canceled error becomes a 499 with ContextCanceledReason and a
+// context.DeadlineExceeded error a 504 with DeadlineExceededReason. Other
+// unrecognised errors get UnknownCode and UnknownReason, see SetUnknownError.
 func FromError(err error) *Error {
 	if err == nil {
 		return nil
 	}
 	if se := new(Error); stderrors.As(err, &se) {
 		// 如果已经是我们的错误类型，确保有ID
-		if se.ID == "" {
+		if se.id() == "" {
 			se.ID = generateErrorID(3)
 		}
 		return se
 	}
 	gs, ok := status.FromError(err)
 	if !ok {
+		// 优先使用注册的转换器识别第三方错误类型
+		if ret := convertForeign(err); ret != nil {
+			if ret.ID == "" {
+				ret.ID = generateErrorID(2)
+			}
+			if ret.cause == nil {
+				ret.cause = err
+			}
+			return ret
+		}
+		// 上下文取消和超时不是服务端错误，分别映射为499和504
+		if code, reason, ok := contextErrorStatus(err); ok {
+			return &Error{
+				Status: Status{
+					Code:    int32(code),
+					Reason:  reason,
+					Message: err.Error(),
+					ID:      generateErrorID(2),
+				},
+				cause: err,
+			}
+		}
+		// 下游HTTP错误携带状态码时，直接沿用该状态码
+		if sc, ok := statusCoderFrom(err); ok {
+			return &Error{
+				Status: Status{
+					Code:    int32(sc.StatusCode()),
+					Reason:  UnknownReason,
+					Message: err.Error(),
+					ID:      generateErrorID(2),
+				},
+				cause: err,
+			}
+		}
+		code, reason := currentUnknownError()
 		return &Error{
 			Status: Status{
-				Code:    UnknownCode,
-				Reason:  UnknownReason,
+				Code:    code,
+				Reason:  reason,
 				Message: err.Error(),
 				ID:      generateErrorID(2),
 			},
@@ -454,42 +916,92 @@ func FromError(err error) *Error {
 			ID:      generateErrorID(2),
 		},
 	}
+	// 第一个 errorspb.Status 是错误本身，其余的是按顺序排列的cause链
+	var statuses []*errorspb.Status
 	for _, detail := range gs.Details() {
 		switch d := detail.(type) {
 		case *errorspb.Status:
-			ret.Code = d.Code
-			ret.Reason = d.Reason
-			ret.Message = d.Message
-			ret.Metadata = d.Metadata
-			// 从gRPC metadata中提取错误ID
-			if d.Metadata != nil && d.Metadata["error_id"] != "" {
-				ret.ID = d.Metadata["error_id"]
-				// 从返回的metadata中移除error_id，避免重复
-				delete(d.Metadata, "error_id")
-				ret.Metadata = d.Metadata
-			}
-			return ret
+			statuses = append(statuses, d)
 		case *anypb.Any:
 			if s := new(errorspb.Status); d.MessageIs(s) {
 				_ = d.UnmarshalTo(s)
-				ret.Code = s.Code
-				ret.Reason = s.Reason
-				ret.Message = s.Message
-				ret.Metadata = s.Metadata
-				// 从gRPC metadata中提取错误ID
-				if s.Metadata != nil && s.Metadata["error_id"] != "" {
-					ret.ID = s.Metadata["error_id"]
-					// 从返回的metadata中移除error_id，避免重复
-					delete(s.Metadata, "error_id")
-					ret.Metadata = s.Metadata
-				}
-				return ret
+				statuses = append(statuses, s)
 			}
 		}
 	}
+	if len(statuses) > 0 {
+		applyStatusDetail(ret, statuses[0])
+		ret.cause = rebuildCause(statuses[1:])
+		return ret
+	}
+	// 没有错误详情时记录原始gRPC状态码，避免多个状态码映射到同一HTTP状态码后丢失
+	ret.Metadata = map[string]string{MetadataKeyGRPCCode: gs.Code().String()}
 	return ret
 }
 
+// contextErrorStatus 识别（可能被包装的）context.Canceled 和 context.DeadlineExceeded
+func contextErrorStatus(err error) (code int, reason string, ok bool) {
+	switch {
+	case stderrors.Is(err, context.Canceled):
+		return 499, ContextCanceledReason, true
+	case stderrors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, DeadlineExceededReason, true
+	}
+	return 0, "", false
+}
+
+// applyStatusDetail 将gRPC错误详情中的状态写入ret，并提取通过metadata传递的字段
+func applyStatusDetail(ret *Error, d *errorspb.Status) {
+	ret.Code = d.Code
+	ret.Reason = d.Reason
+	ret.Message = d.Message
+	ret.Metadata = d.Metadata
+	if d.Metadata == nil {
+		return
+	}
+	// 从gRPC metadata中提取错误ID，并从返回的metadata中移除，避免重复
+	if id := d.Metadata["error_id"]; id != "" {
+		ret.ID = id
+		delete(d.Metadata, "error_id")
+	}
+	if v, ok := d.Metadata[metadataKeyRetryable]; ok {
+		if retryable, err := strconv.ParseBool(v); err == nil {
+			ret.Retryable = retryable
+			ret.retryableSet = true
+		}
+		delete(d.Metadata, metadataKeyRetryable)
+	}
+	if v, ok := d.Metadata[metadataKeySeverity]; ok {
+		if severity, err := ParseSeverity(v); err == nil {
+			ret.Severity = severity
+		}
+		delete(d.Metadata, metadataKeySeverity)
+	}
+	if v, ok := d.Metadata[metadataKeyDomain]; ok {
+		ret.Domain = v
+		delete(d.Metadata, metadataKeyDomain)
+	}
+	if v, ok := d.Metadata[metadataKeyHTTPStatus]; ok {
+		if status, err := strconv.Atoi(v); err == nil {
+			ret.HTTPStatus = status
+		}
+		delete(d.Metadata, metadataKeyHTTPStatus)
+	}
+}
+
+// statusCoderFrom finds the first StatusCoder in err's chain that reports an
+// HTTP error status (4xx or 5xx).
+func statusCoderFrom(err error) (StatusCoder, bool) {
+	var sc StatusCoder
+	if !stderrors.As(err, &sc) {
+		return nil, false
+	}
+	if code := sc.StatusCode(); code < 400 || code > 599 {
+		return nil, false
+	}
+	return sc, true
+}
+
 // ID returns the error ID for a particular error.
 // It supports wrapped errors.
 func ID(err error) string {
@@ -525,6 +1037,34 @@ func Reason(err error) string {
 	return FromError(err).Reason
 }
 
+// Metadata returns a copy of the metadata of an error, or nil if it has none.
+// It supports wrapped errors. The values are not redacted; see
+// RedactedMetadata for values safe to display.
+func Metadata(err error) map[string]string {
+	if err == nil {
+		return nil
+	}
+	metadata := FromError(err).Metadata
+	if len(metadata) == 0 {
+		return nil
+	}
+	ret := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		ret[k] = v
+	}
+	return ret
+}
+
+// MetadataValue returns the metadata value of an error for key.
+// It supports wrapped errors.
+func MetadataValue(err error, key string) (string, bool) {
+	if err == nil {
+		return "", false
+	}
+	v, ok := FromError(err).Metadata[key]
+	return v, ok
+}
+
 // As finds the first error in err's chain that matches target, and if so, sets
 // target to that error value and returns true.
 func As(err error, target any) bool { return stderrors.As(err, target) }
@@ -532,11 +1072,38 @@ func As(err error, target any) bool { return stderrors.As(err, target) }
 // Is reports whether any error in err's chain matches target.
 func Is(err, target error) bool { return stderrors.Is(err, target) }
 
+// Matches reports whether any *Error in err's chain has the given code and
+// reason, ignoring ID, message and metadata. It is the canonical way to test
+// for a known error: comparing with == never matches, since each occurrence
+// has its own ID, and unlike Is it needs no target error. A nil err never
+// matches.
+func Matches(err error, code int, reason string) bool {
+	if err == nil {
+		return false
+	}
+	return stderrors.Is(err, &Definition{code: int32(code), reason: reason})
+}
+
 // Unwrap returns the result of calling the Unwrap method on err, if err's
 // type contains an Unwrap method returning error.
 // Otherwise, Unwrap returns nil.
 func Unwrap(err error) error { return stderrors.Unwrap(err) }
 
+// Cause returns the root cause of err: the last error reached by repeatedly
+// calling Unwrap, such as the driver error wrapped by an *Error. It returns err
+// itself when err has no cause, and nil for a nil err. Like Unwrap, it stops
+// at errors joined with errors.Join, whose Unwrap returns []error.
+func Cause(err error) error {
+	for err != nil {
+		next := stderrors.Unwrap(err)
+		if next == nil {
+			return err
+		}
+		err = next
+	}
+	return nil
+}
+
 //
 // Convenience constructors that match go-kratos API
 //
@@ -566,6 +1133,16 @@ func Conflict(reason, message string) *Error {
 	return New(409, reason, message)
 }
 
+// UnprocessableEntity new UnprocessableEntity error that is mapped to a 422 response.
+func UnprocessableEntity(reason, message string) *Error {
+	return New(422, reason, message)
+}
+
+// TooManyRequests new TooManyRequests error that is mapped to a 429 response.
+func TooManyRequests(reason, message string) *Error {
+	return New(429, reason, message)
+}
+
 // InternalServer new InternalServer error that is mapped to a 500 response.
 func InternalServer(reason, message string) *Error {
 	return New(500, reason, message)
@@ -593,59 +1170,81 @@ func ClientClosed(reason, message string) *Error {
 // IsBadRequest determines if err is an error which indicates a BadRequest error.
 // It supports wrapped errors.
 func IsBadRequest(err error) bool {
-	return Code(err) == 400
+	return statusCode(err) == 400
 }
 
 // IsUnauthorized determines if err is an error which indicates an Unauthorized error.
 // It supports wrapped errors.
 func IsUnauthorized(err error) bool {
-	return Code(err) == 401
+	return statusCode(err) == 401
 }
 
 // IsForbidden determines if err is an error which indicates a Forbidden error.
 // It supports wrapped errors.
 func IsForbidden(err error) bool {
-	return Code(err) == 403
+	return statusCode(err) == 403
 }
 
 // IsNotFound determines if err is an error which indicates an NotFound error.
 // It supports wrapped errors.
 func IsNotFound(err error) bool {
-	return Code(err) == 404
+	return statusCode(err) == 404
 }
 
 // IsConflict determines if err is an error which indicates a Conflict error.
 // It supports wrapped errors.
 func IsConflict(err error) bool {
-	return Code(err) == 409
+	return statusCode(err) == 409
+}
+
+// IsUnprocessableEntity determines if err is an error which indicates an UnprocessableEntity error.
+// It supports wrapped errors.
+func IsUnprocessableEntity(err error) bool {
+	return statusCode(err) == 422
+}
+
+// IsTooManyRequests determines if err is an error which indicates a TooManyRequests error.
+// It supports wrapped errors.
+func IsTooManyRequests(err error) bool {
+	return statusCode(err) == 429
 }
 
 // IsInternalServer determines if err is an error which indicates an Internal error.
 // It supports wrapped errors.
 func IsInternalServer(err error) bool {
-	return Code(err) == 500
+	return statusCode(err) == 500
 }
 
 // IsServiceUnavailable determines if err is an error which indicates an Unavailable error.
 // It supports wrapped errors.
 func IsServiceUnavailable(err error) bool {
-	return Code(err) == 503
+	return statusCode(err) == 503
 }
 
 // IsGatewayTimeout determines if err is an error which indicates a GatewayTimeout error.
 // It supports wrapped errors.
 func IsGatewayTimeout(err error) bool {
-	return Code(err) == 504
+	return statusCode(err) == 504
 }
 
 // IsClientClosed determines if err is an error which indicates a IsClientClosed error.
 // It supports wrapped errors.
 func IsClientClosed(err error) bool {
-	return Code(err) == 499
+	return statusCode(err) == 499
 }
 
-// ToGRPCCode converts an HTTP error code into the corresponding gRPC response status.
+// ToGRPCCode converts an HTTP error code into the corresponding gRPC response
+// status. Overrides set with SetHTTPToGRPCMapping or RegisterHTTPToGRPC take
+// precedence over the default table.
 func ToGRPCCode(code int) codes.Code {
+	if c, ok := httpToGRPCOverride(code); ok {
+		return c
+	}
+	return defaultGRPCCode(code)
+}
+
+// defaultGRPCCode HTTP状态码到gRPC状态码的默认映射
+func defaultGRPCCode(code int) codes.Code {
 	switch code {
 	case http.StatusOK:
 		return codes.OK
@@ -659,6 +1258,8 @@ func ToGRPCCode(code int) codes.Code {
 		return codes.NotFound
 	case http.StatusConflict:
 		return codes.Aborted
+	case http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
 	case http.StatusTooManyRequests:
 		return codes.ResourceExhausted
 	case http.StatusInternalServerError:
@@ -673,8 +1274,18 @@ func ToGRPCCode(code int) codes.Code {
 	return codes.Unknown
 }
 
-// ToHTTPCode converts a gRPC error code into the corresponding HTTP response status.
+// ToHTTPCode converts a gRPC error code into the corresponding HTTP response
+// status. Overrides set with SetGRPCToHTTPMapping or RegisterGRPCToHTTP take
+// precedence over the default table.
 func ToHTTPCode(code codes.Code) int {
+	if c, ok := grpcToHTTPOverride(code); ok {
+		return c
+	}
+	return defaultHTTPCode(code)
+}
+
+// defaultHTTPCode gRPC状态码到HTTP状态码的默认映射
+func defaultHTTPCode(code codes.Code) int {
 	switch code {
 	case codes.OK:
 		return http.StatusOK
@@ -719,17 +1330,19 @@ func ToHTTPCode(code codes.Code) int {
 // and generate Go code (enums for reasons, helper functions like IsXXX, ErrorXXX)
 // that utilizes the Error struct and mechanisms defined in this package.
 
-// IsClientError 检查是否为客户端错误
+// IsClientError 检查是否为客户端错误，按 StatusCode 判断
 func (e *Error) IsClientError() bool {
-	return e.Code >= 400 && e.Code < 500
+	status := e.StatusCode()
+	return status >= 400 && status < 500
 }
 
-// IsServerError 检查是否为服务器错误
+// IsServerError 检查是否为服务器错误，按 StatusCode 判断
 func (e *Error) IsServerError() bool {
-	return e.Code >= 500
+	return e.StatusCode() >= 500
 }
 
-// IsAuthError 检查是否为认证/授权错误
+// IsAuthError 检查是否为认证/授权错误，按 StatusCode 判断
 func (e *Error) IsAuthError() bool {
-	return e.Code == 401 || e.Code == 403
+	status := e.StatusCode()
+	return status == 401 || status == 403
 }
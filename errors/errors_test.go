@@ -1,10 +1,17 @@
 package errors
 
 import (
+	"context"
 	"encoding/base64"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
+
+	"google.golang.org/grpc/codes"
 )
 
 func TestErrorID(t *testing.T) {
@@ -52,12 +59,18 @@ func TestErrorIDDecoding(t *testing.T) {
 		t.Errorf("解码错误ID失败: %v", decodeErr)
 	}
 
-	if debugInfo["raw"] == "" {
+	if debugInfo.Raw == "" {
 		t.Error("解码后的原始信息不应该为空")
 	}
+	if debugInfo.Function != "TestErrorIDDecoding" || debugInfo.File != "errors_test.go" || debugInfo.Line == 0 {
+		t.Errorf("结构化字段应该指向创建位置，实际: %s@%s:%d", debugInfo.Function, debugInfo.File, debugInfo.Line)
+	}
+	if debugInfo.Version != CurrentIDVersion || debugInfo.ProcessID != os.Getpid() || debugInfo.RandomSuffix == "" {
+		t.Errorf("结构化字段解析错误: %+v", debugInfo)
+	}
 
 	// 验证解码信息包含预期的组件
-	rawInfo := debugInfo["raw"].(string)
+	rawInfo := debugInfo.Raw
 	if !strings.Contains(rawInfo, "errors_test.go") {
 		t.Errorf("解码信息应该包含文件名，实际: %s", rawInfo)
 	}
@@ -67,6 +80,65 @@ func TestErrorIDDecoding(t *testing.T) {
 	}
 }
 
+func TestErrorIDVersion(t *testing.T) {
+	// 新生成的ID带有当前版本前缀
+	err := New(400, "VERSIONED", "带版本的错误ID")
+	info, decodeErr := DecodeErrorID(err.ID)
+	if decodeErr != nil {
+		t.Fatalf("解码错误ID失败: %v", decodeErr)
+	}
+	if info.Version != CurrentIDVersion {
+		t.Errorf("ID版本应该是 %d，实际: %d", CurrentIDVersion, info.Version)
+	}
+	if info.Function != "TestErrorIDVersion" || info.File != "errors_test.go" {
+		t.Errorf("解码的调用位置不正确: %s@%s", info.Function, info.File)
+	}
+
+	// 没有版本前缀的旧格式ID仍然可以解码
+	legacy := base64.StdEncoding.EncodeToString([]byte("GetUser@user_logic.go:25:1640995200123456789:1:12345:a1b2c3d4"))
+	info, decodeErr = DecodeErrorID(legacy)
+	if decodeErr != nil {
+		t.Fatalf("解码旧格式错误ID失败: %v", decodeErr)
+	}
+	if info.Version != 0 || info.Function != "GetUser" || info.Line != 25 || info.RandomSuffix != "a1b2c3d4" {
+		t.Errorf("旧格式错误ID解码结果不正确: %+v", info)
+	}
+
+	// 未知的更高版本应该返回错误
+	future := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("v%d:whatever", CurrentIDVersion+1)))
+	if _, decodeErr = DecodeErrorID(future); decodeErr == nil {
+		t.Error("未知版本的错误ID应该解码失败")
+	}
+}
+
+func TestDecodeFallbackErrorID(t *testing.T) {
+	id := generateFallbackErrorID()
+
+	info, err := DecodeErrorID(id)
+	if err != nil {
+		t.Fatalf("备用ID应该可以解码: %v", err)
+	}
+	if !info.IsFallback {
+		t.Error("备用ID应该标记为IsFallback")
+	}
+	if info.Version != CurrentIDVersion {
+		t.Errorf("备用ID版本应该是 %d，实际: %d", CurrentIDVersion, info.Version)
+	}
+	if info.Timestamp == 0 || info.ProcessID != os.Getpid() || info.RandomSuffix == "" {
+		t.Errorf("备用ID的时间戳、进程ID和随机值应该被解析，实际: %+v", info)
+	}
+	if info.Function != "" || info.File != "" || info.Line != 0 {
+		t.Errorf("备用ID不应该包含调用位置，实际: %+v", info)
+	}
+
+	// 无版本前缀的旧备用ID
+	legacy := base64.StdEncoding.EncodeToString([]byte("fallback:1640995200123456789:42:123456"))
+	info, err = DecodeErrorID(legacy)
+	if err != nil || !info.IsFallback || info.ProcessID != 42 || info.Version != 0 {
+		t.Errorf("旧格式备用ID解码结果不正确: %+v, %v", info, err)
+	}
+}
+
 func TestErrorIDWithConvenienceFunctions(t *testing.T) {
 	// 测试便利函数是否正确生成错误ID
 	testCases := []struct {
@@ -79,6 +151,8 @@ func TestErrorIDWithConvenienceFunctions(t *testing.T) {
 		{"Forbidden", func() *Error { return Forbidden("FORBIDDEN", "禁止访问") }, 403},
 		{"NotFound", func() *Error { return NotFound("NOT_FOUND", "未找到") }, 404},
 		{"Conflict", func() *Error { return Conflict("CONFLICT", "冲突") }, 409},
+		{"UnprocessableEntity", func() *Error { return UnprocessableEntity("INVALID_FIELD", "字段校验失败") }, 422},
+		{"TooManyRequests", func() *Error { return TooManyRequests("RATE_LIMITED", "请求过于频繁") }, 429},
 		{"InternalServer", func() *Error { return InternalServer("INTERNAL", "内部错误") }, 500},
 	}
 
@@ -102,6 +176,194 @@ func TestErrorIDWithConvenienceFunctions(t *testing.T) {
 	}
 }
 
+func TestWithMetadataKV(t *testing.T) {
+	original := NotFound("USER_NOT_FOUND", "用户不存在").WithMetadata(map[string]string{"user_id": "42"})
+	annotated := original.WithMetadataKV("tenant", "acme")
+
+	if annotated.Metadata["tenant"] != "acme" || annotated.Metadata["user_id"] != "42" {
+		t.Errorf("WithMetadataKV应该保留已有的metadata并添加新键，实际: %v", annotated.Metadata)
+	}
+	if _, ok := original.Metadata["tenant"]; ok || len(original.Metadata) != 1 {
+		t.Errorf("原错误的metadata不应该被修改，实际: %v", original.Metadata)
+	}
+	if annotated.ID != original.ID {
+		t.Error("WithMetadataKV应该保留错误ID")
+	}
+
+	// metadata为nil时也能添加
+	if got := (&Error{Status: Status{Code: 500}}).WithMetadataKV("k", "v"); got.Metadata["k"] != "v" {
+		t.Errorf("nil metadata应该被分配，实际: %v", got.Metadata)
+	}
+}
+
+func TestMetadataAccessors(t *testing.T) {
+	origin := NotFound("USER_NOT_FOUND", "用户不存在").WithMetadataKV("user_id", "42")
+	err := fmt.Errorf("load profile: %w", origin)
+
+	if v, ok := MetadataValue(err, "user_id"); !ok || v != "42" {
+		t.Errorf("应该从包装的错误中取得metadata, 实际: %q %v", v, ok)
+	}
+	if _, ok := MetadataValue(err, "missing"); ok {
+		t.Error("不存在的key应该返回false")
+	}
+	metadata := Metadata(err)
+	if metadata["user_id"] != "42" {
+		t.Errorf("Metadata应该返回包装错误的metadata, 实际: %v", metadata)
+	}
+	metadata["user_id"] = "changed"
+	if origin.Metadata["user_id"] != "42" {
+		t.Error("修改返回的map不应该影响原错误")
+	}
+
+	if Metadata(nil) != nil {
+		t.Error("nil错误的Metadata应该返回nil")
+	}
+	if v, ok := MetadataValue(nil, "user_id"); ok || v != "" {
+		t.Errorf("nil错误的MetadataValue应该返回空值, 实际: %q %v", v, ok)
+	}
+}
+
+func TestWithMessage(t *testing.T) {
+	cause := stderrors.New("connection refused")
+	original := InternalServer("DB_ERROR", "数据库错误").
+		WithMetadata(map[string]string{"table": "users"}).
+		WithCause(cause)
+
+	rewritten := original.WithMessagef("查询用户 %d 失败", 42)
+	if rewritten.Message != "查询用户 42 失败" {
+		t.Errorf("WithMessagef应该替换消息，实际: %s", rewritten.Message)
+	}
+	if rewritten.ID != original.ID || rewritten.Code != original.Code || rewritten.Reason != original.Reason {
+		t.Errorf("替换消息后应该保留ID、code和reason，实际: %+v", rewritten.Status)
+	}
+	if rewritten.Metadata["table"] != "users" || !stderrors.Is(rewritten, cause) {
+		t.Error("替换消息后应该保留metadata和cause")
+	}
+	if original.Message != "数据库错误" {
+		t.Errorf("原错误的消息不应该被修改，实际: %s", original.Message)
+	}
+	if got := original.WithMessage("数据库不可用"); got.Message != "数据库不可用" || got.ID != original.ID {
+		t.Errorf("WithMessage应该替换消息并保留ID，实际: %+v", got.Status)
+	}
+}
+
+func TestWithCode(t *testing.T) {
+	cause := stderrors.New("record not found")
+	downstream := NotFound("USER_NOT_FOUND", "用户不存在").
+		WithMetadata(map[string]string{"user_id": "42"}).
+		WithCause(cause)
+
+	upstream := downstream.WithCode(502)
+	if upstream.Code != 502 {
+		t.Errorf("WithCode应该替换code，实际: %d", upstream.Code)
+	}
+	if upstream.GetID() != downstream.GetID() {
+		t.Errorf("替换code后应该保留ID，期望: %s, 实际: %s", downstream.ID, upstream.ID)
+	}
+	if upstream.Reason != "USER_NOT_FOUND" || upstream.Message != "用户不存在" {
+		t.Errorf("替换code后应该保留reason和message，实际: %+v", upstream.Status)
+	}
+	if upstream.Metadata["user_id"] != "42" || !stderrors.Is(upstream, cause) {
+		t.Error("替换code后应该保留metadata和cause")
+	}
+	if downstream.Code != 404 {
+		t.Errorf("原错误的code不应该被修改，实际: %d", downstream.Code)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	cause := stderrors.New("connection refused")
+
+	for name, err := range map[string]*Error{
+		"Wrap":  Wrap(cause, 503, "DB_UNAVAILABLE", "数据库不可用"),
+		"Wrapf": Wrapf(cause, 503, "DB_UNAVAILABLE", "数据库 %s 不可用", "users"),
+	} {
+		if stderrors.Unwrap(err) != cause || !stderrors.Is(err, cause) {
+			t.Errorf("%s: errors.Unwrap应该返回原始cause", name)
+		}
+		if err.Code != 503 || err.Reason != "DB_UNAVAILABLE" {
+			t.Errorf("%s: code和reason不正确，实际: %+v", name, err.Status)
+		}
+		info, decodeErr := DecodeErrorID(err.ID)
+		if decodeErr != nil {
+			t.Fatalf("%s: 错误ID应该可以解码: %v", name, decodeErr)
+		}
+		if info.Function != "TestWrap" || info.File != "errors_test.go" {
+			t.Errorf("%s: 错误ID应该指向调用者，实际: %s@%s:%d", name, info.Function, info.File, info.Line)
+		}
+	}
+
+	if Wrap(nil, 500, "X", "x") != nil || Wrapf(nil, 500, "X", "%s", "x") != nil {
+		t.Error("包装nil应该返回nil")
+	}
+}
+
+// errTemplateNotFound 模拟包级别的模板错误
+var errTemplateNotFound = NotFound("USER_NOT_FOUND", "用户不存在").WithMetadataKV("service", "user")
+
+func TestCloneWithNewID(t *testing.T) {
+	clone := CloneWithNewID(errTemplateNotFound)
+	if clone.ID == "" || clone.ID == errTemplateNotFound.ID {
+		t.Fatalf("副本应该有新的错误ID，实际: %q", clone.ID)
+	}
+	info, err := DecodeErrorID(clone.ID)
+	if err != nil {
+		t.Fatalf("新的错误ID应该可以解码: %v", err)
+	}
+	if info.Function != "TestCloneWithNewID" || info.File != "errors_test.go" {
+		t.Errorf("新的错误ID应该指向调用者，实际: %s@%s:%d", info.Function, info.File, info.Line)
+	}
+	if clone.Code != 404 || clone.Reason != "USER_NOT_FOUND" || clone.Message != "用户不存在" || clone.Metadata["service"] != "user" {
+		t.Errorf("副本应该保留code、reason、message和metadata，实际: %+v", clone.Status)
+	}
+	if !stderrors.Is(clone, errTemplateNotFound) {
+		t.Error("副本应该与模板错误匹配")
+	}
+	if CloneWithNewID(nil) != nil {
+		t.Error("克隆nil应该返回nil")
+	}
+}
+
+func TestAppendMetadata(t *testing.T) {
+	original := InternalServer("DB_ERROR", "数据库错误").WithMetadata(map[string]string{"table": "users", "op": "select"})
+	merged := original.AppendMetadata(map[string]string{"op": "update", "tenant": "acme"})
+
+	want := map[string]string{"table": "users", "op": "update", "tenant": "acme"}
+	if !reflect.DeepEqual(merged.Metadata, want) {
+		t.Errorf("合并后的metadata应该是 %v，实际: %v", want, merged.Metadata)
+	}
+	if original.Metadata["op"] != "select" || len(original.Metadata) != 2 {
+		t.Errorf("原错误的metadata不应该被修改，实际: %v", original.Metadata)
+	}
+
+	// 接收者和参数的metadata都可以为nil
+	empty := &Error{Status: Status{Code: 500}}
+	if got := empty.AppendMetadata(nil); got.Metadata == nil || len(got.Metadata) != 0 {
+		t.Errorf("两个nil map合并应该得到空metadata，实际: %v", got.Metadata)
+	}
+	if got := empty.AppendMetadata(map[string]string{"k": "v"}); got.Metadata["k"] != "v" {
+		t.Errorf("nil metadata应该被分配，实际: %v", got.Metadata)
+	}
+}
+
+func TestUnprocessableEntityAndTooManyRequests(t *testing.T) {
+	validation := fmt.Errorf("wrap: %w", UnprocessableEntity("INVALID_FIELD", "字段校验失败"))
+	if !IsUnprocessableEntity(validation) || IsTooManyRequests(validation) {
+		t.Error("IsUnprocessableEntity应该识别包装的422错误")
+	}
+	if got := UnprocessableEntity("INVALID_FIELD", "字段校验失败").GRPCStatus().Code(); got != codes.InvalidArgument {
+		t.Errorf("422应该映射为InvalidArgument，实际: %v", got)
+	}
+
+	limited := TooManyRequests("RATE_LIMITED", "请求过于频繁")
+	if !IsTooManyRequests(limited) || IsUnprocessableEntity(limited) {
+		t.Error("IsTooManyRequests应该识别429错误")
+	}
+	if got := limited.GRPCStatus().Code(); got != codes.ResourceExhausted {
+		t.Errorf("429应该映射为ResourceExhausted，实际: %v", got)
+	}
+}
+
 func TestErrorIDWithMetadata(t *testing.T) {
 	// 测试带有元数据的错误ID处理
 	err := New(500, "DB_ERROR", "数据库错误").
@@ -148,12 +410,49 @@ func TestErrorIDFromError(t *testing.T) {
 	}
 }
 
+func TestIDEncodingAlphabets(t *testing.T) {
+	// "???" 和 "~~~" 在标准编码中产生 / 和 +，在URL安全编码中产生 _ 和 -
+	raw := "v1:Get???~~~@user_logic.go:25:1640995200123456789:1:12345:a1b2c3d4"
+	std := base64.StdEncoding.EncodeToString([]byte(raw))
+	urlSafe := base64.RawURLEncoding.EncodeToString([]byte(raw))
+	if !strings.ContainsAny(std, "+/") || std == urlSafe {
+		t.Fatalf("测试数据应该在两种编码中不同: %s / %s", std, urlSafe)
+	}
+
+	for name, id := range map[string]string{"标准编码": std, "URL安全编码": urlSafe} {
+		info, err := DecodeErrorID(id)
+		if err != nil {
+			t.Fatalf("%s的ID应该可以解码: %v", name, err)
+		}
+		if info.Function != "Get???~~~" || info.Raw != raw {
+			t.Errorf("%s的ID解码结果不正确: %+v", name, info)
+		}
+	}
+
+	if got := encodeID([]byte(raw)); got != urlSafe {
+		t.Errorf("默认应该使用URL安全编码, 实际: %s", got)
+	}
+
+	SetIDEncoding(base64.StdEncoding)
+	defer SetIDEncoding(nil)
+	if got := encodeID([]byte(raw)); got != std {
+		t.Errorf("SetIDEncoding应该固定使用标准编码, 实际: %s", got)
+	}
+	id := New(400, "BAD", "无效请求").ID
+	if _, err := base64.StdEncoding.DecodeString(id); err != nil {
+		t.Errorf("固定标准编码后生成的ID应该是标准编码: %v", err)
+	}
+	if _, err := DecodeErrorID(urlSafe); err != nil {
+		t.Errorf("固定标准编码后仍应该能解码URL安全编码的ID: %v", err)
+	}
+}
+
 func TestErrorIDBase64Encoding(t *testing.T) {
-	// 测试错误ID确实是base64编码的
+	// 测试错误ID确实是URL安全的base64编码
 	err := New(200, "OK", "成功")
 
 	// 尝试解码base64
-	decoded, decodeErr := base64.StdEncoding.DecodeString(err.ID)
+	decoded, decodeErr := base64.RawURLEncoding.DecodeString(err.ID)
 	if decodeErr != nil {
 		t.Errorf("错误ID应该是有效的base64编码: %v", decodeErr)
 	}
@@ -223,6 +522,146 @@ func TestGetIDGeneration(t *testing.T) {
 	}
 }
 
+// fakeHTTPError 模拟携带HTTP状态码的下游错误
+type fakeHTTPError struct {
+	code int
+}
+
+func (e *fakeHTTPError) Error() string   { return fmt.Sprintf("http status %d", e.code) }
+func (e *fakeHTTPError) StatusCode() int { return e.code }
+
+func TestFromErrorStatusCoder(t *testing.T) {
+	cause := &fakeHTTPError{code: 502}
+	err := FromError(fmt.Errorf("call downstream: %w", cause))
+
+	if err.Code != 502 {
+		t.Errorf("应该沿用下游的HTTP状态码，期望: 502，实际: %d", err.Code)
+	}
+	if err.ID == "" {
+		t.Error("转换后的错误应该有错误ID")
+	}
+	var target *fakeHTTPError
+	if !stderrors.As(err, &target) || target != cause {
+		t.Error("转换后的错误应该保留原始cause")
+	}
+
+	// 非错误状态码不应被采用
+	if code := FromError(&fakeHTTPError{code: 302}).Code; code != UnknownCode {
+		t.Errorf("非错误状态码应该回退到UnknownCode，实际: %d", code)
+	}
+}
+
+func TestDecodeErrorIDTrailingColons(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		random  string
+		buildID string
+		traceID string
+		extra   string
+	}{
+		{"随机后缀包含冒号", "v2:Get@user.go:12:1700000000000000000:7:42:a1b2:c3d4", "a1b2:c3d4", "", "", ""},
+		{"附加字段值包含冒号", "v2:Get@user.go:12:1700000000000000000:7:42:a1b2:b=v1:2:t=abc", "a1b2", "v1:2", "abc", ""},
+		{"无法识别的字段", "v2:Get@user.go:12:1700000000000000000:7:42:a1b2:r=USER:NOT:FOUND:t=abc", "a1b2", "", "abc", "r=USER:NOT:FOUND"},
+		{"多个无法识别的字段", "v2:Get@user.go:12:1700000000000000000:7:42:a1b2:x=1:y=2", "a1b2", "", "", "x=1:y=2"},
+		{"旧版本格式", "v1:Get@user.go:12:1700000000000000000:7:42:a1b2:c3d4", "a1b2:c3d4", "", "", ""},
+		{"备用ID", "v2:fallback:1700000000000000000:42:12:34", "12:34", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := tt.raw
+			if strings.HasPrefix(raw, "v2:") {
+				raw = appendChecksum(raw)
+			}
+			info, err := DecodeErrorID(encodeID([]byte(raw)))
+			if err != nil {
+				t.Fatalf("应该可以解码: %v", err)
+			}
+			if info.ProcessID != 42 || info.Timestamp != 1700000000000000000 {
+				t.Errorf("固定位置的字段解析错误: %+v", info)
+			}
+			if !info.IsFallback && (info.Function != "Get" || info.Line != 12 || info.GoroutineID != 7) {
+				t.Errorf("固定位置的字段解析错误: %+v", info)
+			}
+			if info.RandomSuffix != tt.random || info.BuildID != tt.buildID || info.TraceID != tt.traceID || info.Extra != tt.extra {
+				t.Errorf("末尾字段解析错误，实际: random=%q build=%q trace=%q extra=%q",
+					info.RandomSuffix, info.BuildID, info.TraceID, info.Extra)
+			}
+		})
+	}
+}
+
+func TestErrorIDInfoTime(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 30, 0, 123456789, time.UTC)
+	SetClock(func() time.Time { return created })
+	t.Cleanup(func() { SetClock(nil) })
+
+	info, err := DecodeErrorID(New(500, "TIME", "time").ID)
+	if err != nil {
+		t.Fatalf("错误ID应该可以解码: %v", err)
+	}
+	if !info.Time().Equal(created) {
+		t.Errorf("Time 应该返回生成ID的时间 %v，实际: %v", created, info.Time())
+	}
+
+	first := info.Age()
+	time.Sleep(time.Millisecond)
+	second := info.Age()
+	if first <= 0 || second <= first {
+		t.Errorf("Age 应该为正并随时间增长，实际: %v %v", first, second)
+	}
+}
+
+func TestDecodeErrorIDs(t *testing.T) {
+	valid := New(404, "USER_NOT_FOUND", "用户不存在").ID
+	other := New(500, "INTERNAL", "内部错误").ID
+	ids := []string{valid, "!!!无效的ID!!!", other, valid[:len(valid)-4]}
+
+	infos, errs := DecodeErrorIDs(ids)
+	if len(infos) != len(ids) || len(errs) != len(ids) {
+		t.Fatalf("结果数量应该与输入一致，实际: %d %d", len(infos), len(errs))
+	}
+	for i, id := range ids {
+		want, wantErr := DecodeErrorID(id)
+		if (errs[i] == nil) != (wantErr == nil) {
+			t.Errorf("第%d个ID的错误应该与 DecodeErrorID 一致，实际: %v, 期望: %v", i, errs[i], wantErr)
+		}
+		if !reflect.DeepEqual(infos[i], want) {
+			t.Errorf("第%d个ID的解码结果应该与 DecodeErrorID 一致，实际: %+v, 期望: %+v", i, infos[i], want)
+		}
+	}
+	if infos[0].Raw == infos[2].Raw {
+		t.Error("复用缓冲区不应该影响已返回的结果")
+	}
+}
+
+func TestFromErrorContextErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		code   int32
+		reason string
+	}{
+		{"canceled", fmt.Errorf("query users: %w", context.Canceled), 499, ContextCanceledReason},
+		{"deadline", fmt.Errorf("query users: %w", context.DeadlineExceeded), 504, DeadlineExceededReason},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := FromError(tt.err)
+			if err.Code != tt.code || err.Reason != tt.reason {
+				t.Errorf("应该转换为 %d %s，实际: %d %s", tt.code, tt.reason, err.Code, err.Reason)
+			}
+			if err.ID == "" || !stderrors.Is(err, tt.err) {
+				t.Error("转换后的错误应该有错误ID并保留原始cause")
+			}
+		})
+	}
+
+	if !IsClientClosed(context.Canceled) || !IsGatewayTimeout(context.DeadlineExceeded) {
+		t.Error("上下文错误应该被识别为499和504")
+	}
+}
+
 // Benchmark测试
 func BenchmarkErrorIDGeneration(b *testing.B) {
 	for i := 0; i < b.N; i++ {
@@ -238,3 +677,96 @@ func BenchmarkErrorIDDecoding(b *testing.B) {
 		DecodeErrorID(err.ID)
 	}
 }
+
+func benchmarkDecodeIDs(b *testing.B) []string {
+	ids := make([]string, 64)
+	for i := range ids {
+		ids[i] = New(400, "BENCH", "基准测试错误").ID
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	return ids
+}
+
+func BenchmarkErrorIDDecodingLoop(b *testing.B) {
+	ids := benchmarkDecodeIDs(b)
+	for i := 0; i < b.N; i++ {
+		for _, id := range ids {
+			_, _ = DecodeErrorID(id)
+		}
+	}
+}
+
+func BenchmarkDecodeErrorIDs(b *testing.B) {
+	ids := benchmarkDecodeIDs(b)
+	for i := 0; i < b.N; i++ {
+		_, _ = DecodeErrorIDs(ids)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	notFound := NotFound("USER_NOT_FOUND", "用户不存在").WithMetadataKV("user_id", "42")
+	wrapped := fmt.Errorf("load profile: %w", fmt.Errorf("query: %w", notFound))
+
+	if !Matches(wrapped, 404, "USER_NOT_FOUND") {
+		t.Error("包装后的错误应该按 code 和 reason 匹配")
+	}
+	if !Matches(InternalServer("LOAD_FAILED", "加载失败").WithCause(notFound), 404, "USER_NOT_FOUND") {
+		t.Error("原因链中的错误应该匹配")
+	}
+	if Matches(wrapped, 404, "ORDER_NOT_FOUND") || Matches(wrapped, 400, "USER_NOT_FOUND") {
+		t.Error("code 或 reason 不同时不应该匹配")
+	}
+	if Matches(nil, 404, "USER_NOT_FOUND") || Matches(nil, 200, UnknownReason) {
+		t.Error("nil 错误不应该匹配")
+	}
+	if Matches(fmt.Errorf("plain"), UnknownCode, UnknownReason) {
+		t.Error("非 *Error 的错误不应该匹配")
+	}
+}
+
+func TestCause(t *testing.T) {
+	driverErr := stderrors.New("pq: duplicate key value")
+	dbErr := Wrap(driverErr, 409, "DUPLICATE_KEY", "记录已存在")
+	chain := fmt.Errorf("create user: %w", InternalServer("CREATE_FAILED", "创建失败").WithCause(dbErr))
+
+	if got := Cause(chain); got != driverErr {
+		t.Errorf("Cause应该返回多层错误链的根因，实际: %v", got)
+	}
+	if got := Cause(dbErr); got != driverErr {
+		t.Errorf("Cause应该返回 *Error 包装的原始错误，实际: %v", got)
+	}
+
+	single := NotFound("USER_NOT_FOUND", "用户不存在")
+	if got := Cause(single); got != error(single) {
+		t.Errorf("没有cause的错误应该返回自身，实际: %v", got)
+	}
+	if got := Cause(driverErr); got != driverErr {
+		t.Errorf("没有cause的普通错误应该返回自身，实际: %v", got)
+	}
+	if Cause(nil) != nil {
+		t.Error("nil错误的Cause应该返回nil")
+	}
+}
+
+func TestSetUnknownError(t *testing.T) {
+	SetUnknownError(503, "UNKNOWN")
+	t.Cleanup(func() { SetUnknownError(UnknownCode, UnknownReason) })
+
+	boom := fmt.Errorf("boom")
+	appErr := FromError(boom)
+	if appErr.Code != 503 || appErr.Reason != "UNKNOWN" || appErr.Message != "boom" {
+		t.Errorf("未识别的错误应该使用设置的兜底状态，实际: %+v", appErr.Status)
+	}
+	if Code(boom) != 503 || Reason(boom) != "UNKNOWN" {
+		t.Errorf("Code 和 Reason 应该与 FromError 一致，实际: %d %q", Code(boom), Reason(boom))
+	}
+	if got := FromError(context.Canceled); got.Code != 499 || got.Reason != ContextCanceledReason {
+		t.Errorf("上下文错误不应该使用兜底状态，实际: %+v", got.Status)
+	}
+
+	SetUnknownError(UnknownCode, UnknownReason)
+	if Code(boom) != UnknownCode || Reason(boom) != UnknownReason {
+		t.Errorf("应该可以恢复默认的兜底状态，实际: %d %q", Code(boom), Reason(boom))
+	}
+}
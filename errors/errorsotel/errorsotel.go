@@ -0,0 +1,55 @@
+// Package errorsotel correlates errors with OpenTelemetry traces, keeping the
+// OpenTelemetry dependency out of the core errors package.
+//
+// Importing it makes errors.NewContext embed the trace ID of the active span
+// in error IDs:
+//
+//	import _ "github.com/honeybbq/protoc-gen-go-zero-errors/errors/errorsotel"
+package errorsotel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+)
+
+func init() {
+	errors.SetTraceIDFunc(TraceID)
+}
+
+// TraceID returns the trace ID of the span in ctx, or "" if there is none.
+func TraceID(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+	return ""
+}
+
+// RecordError records e on the span in ctx, marks the span as failed and
+// stamps the span's trace ID into the error metadata under
+// errors.MetadataKeyTraceID. It returns e unchanged when ctx carries no
+// recording span; otherwise the returned error is a copy of e.
+func RecordError(ctx context.Context, e *errors.Error) *errors.Error {
+	if e == nil {
+		return nil
+	}
+	span := trace.SpanFromContext(ctx)
+	if !span.SpanContext().IsValid() {
+		return e
+	}
+
+	span.RecordError(e, trace.WithAttributes(
+		attribute.String("error.id", e.GetID()),
+		attribute.Int("error.code", int(e.Code)),
+		attribute.String("error.reason", e.Reason),
+	))
+	span.SetStatus(codes.Error, e.Message)
+
+	return e.WithDefaultMetadata(map[string]string{
+		errors.MetadataKeyTraceID: span.SpanContext().TraceID().String(),
+	})
+}
@@ -0,0 +1,79 @@
+package errorsotel
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+)
+
+// startSpan 使用 tracetest 记录器创建一个span
+func startSpan(t *testing.T) (context.Context, *tracetest.SpanRecorder) {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+	ctx, _ := provider.Tracer("test").Start(context.Background(), "request")
+	return ctx, recorder
+}
+
+func TestRecordError(t *testing.T) {
+	ctx, recorder := startSpan(t)
+
+	origin := errors.InternalServer("DB_ERROR", "数据库错误")
+	got := RecordError(ctx, origin)
+	trace.SpanFromContext(ctx).End()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("应该记录1个span，实际: %d", len(spans))
+	}
+	span := spans[0]
+	if span.Status().Code != codes.Error || span.Status().Description != "数据库错误" {
+		t.Errorf("span状态应该是Error，实际: %+v", span.Status())
+	}
+	if len(span.Events()) != 1 || span.Events()[0].Name != "exception" {
+		t.Fatalf("span应该记录错误事件，实际: %v", span.Events())
+	}
+	var id string
+	for _, attr := range span.Events()[0].Attributes {
+		if attr.Key == "error.id" {
+			id = attr.Value.AsString()
+		}
+	}
+	if id != origin.ID {
+		t.Errorf("错误事件应该包含错误ID，实际: %q", id)
+	}
+
+	if traceID := span.SpanContext().TraceID().String(); got.Metadata[errors.MetadataKeyTraceID] != traceID {
+		t.Errorf("错误metadata应该包含追踪ID %s，实际: %v", traceID, got.Metadata)
+	}
+	if _, ok := origin.Metadata[errors.MetadataKeyTraceID]; ok {
+		t.Error("不应该修改原始错误")
+	}
+}
+
+func TestRecordErrorWithoutSpan(t *testing.T) {
+	origin := errors.BadRequest("BAD", "无效请求")
+	if got := RecordError(context.Background(), origin); got != origin {
+		t.Error("没有span时应该返回原错误")
+	}
+}
+
+func TestNewContextEmbedsTraceID(t *testing.T) {
+	ctx, _ := startSpan(t)
+
+	err := errors.NewContext(ctx, 500, "INTERNAL", "内部错误")
+	info, decodeErr := errors.DecodeErrorID(err.ID)
+	if decodeErr != nil {
+		t.Fatalf("解码错误ID失败: %v", decodeErr)
+	}
+	if info.TraceID != TraceID(ctx) || info.TraceID == "" {
+		t.Errorf("错误ID应该包含当前span的追踪ID，实际: %q", info.TraceID)
+	}
+}
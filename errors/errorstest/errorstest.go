@@ -0,0 +1,50 @@
+// Package errorstest provides helpers for asserting on errors produced by the
+// errors package in tests.
+package errorstest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+)
+
+// AssertGRPCStatus reports a test error if err does not carry the wanted gRPC
+// code and reason. err may be an *errors.Error (possibly wrapped) or an error
+// returned over gRPC, e.g. by a client stub or an interceptor.
+func AssertGRPCStatus(t testing.TB, err error, wantCode codes.Code, wantReason string) {
+	t.Helper()
+
+	if err == nil {
+		t.Errorf("gRPC status mismatch: got nil error, want code = %s reason = %q", wantCode, wantReason)
+		return
+	}
+
+	var gotCode codes.Code
+	appErr := new(errors.Error)
+	if errors.As(err, &appErr) {
+		gotCode = appErr.GRPCCode() // 不调用 GRPCStatus，避免为被断言的错误生成ID
+	} else if s, ok := status.FromError(err); ok {
+		gotCode = s.Code()
+		appErr = errors.FromError(err)
+	} else {
+		t.Errorf("gRPC status mismatch: got non-status error %T (%v), want code = %s reason = %q",
+			err, err, wantCode, wantReason)
+		return
+	}
+
+	var diff []string
+	if gotCode != wantCode {
+		diff = append(diff, fmt.Sprintf("  code:   got %s, want %s", gotCode, wantCode))
+	}
+	if appErr.Reason != wantReason {
+		diff = append(diff, fmt.Sprintf("  reason: got %q, want %q", appErr.Reason, wantReason))
+	}
+	if len(diff) > 0 {
+		t.Errorf("gRPC status mismatch (message = %q):\n%s", appErr.Message, strings.Join(diff, "\n"))
+	}
+}
@@ -0,0 +1,76 @@
+package errorstest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+)
+
+// recorder 记录断言失败信息，替代真实的 testing.T
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestAssertGRPCStatus(t *testing.T) {
+	testCases := []struct {
+		name       string
+		err        error
+		wantCode   codes.Code
+		wantReason string
+		wantFail   string
+	}{
+		{"匹配的Error", errors.NotFound("USER_NOT_FOUND", "用户不存在"), codes.NotFound, "USER_NOT_FOUND", ""},
+		{"包装的Error", fmt.Errorf("logic: %w", errors.Forbidden("DENIED", "禁止访问")), codes.PermissionDenied, "DENIED", ""},
+		{"gRPC状态", errors.Conflict("EXISTS", "已存在").GRPCStatus().Err(), codes.Aborted, "EXISTS", ""},
+		{"code不匹配", errors.BadRequest("BAD", "无效"), codes.NotFound, "BAD", "code:   got InvalidArgument, want NotFound"},
+		{"reason不匹配", errors.BadRequest("BAD", "无效"), codes.InvalidArgument, "OTHER", `reason: got "BAD", want "OTHER"`},
+		{"nil错误", nil, codes.NotFound, "X", "got nil error"},
+		{"非状态错误", fmt.Errorf("boom"), codes.Internal, "", "got non-status error"},
+		{"无详情的gRPC状态", status.Error(codes.Unavailable, "down"), codes.Unavailable, "", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &recorder{TB: t}
+			AssertGRPCStatus(r, tc.err, tc.wantCode, tc.wantReason)
+
+			if tc.wantFail == "" {
+				if len(r.failures) != 0 {
+					t.Errorf("不应该断言失败，实际: %v", r.failures)
+				}
+				return
+			}
+			if len(r.failures) != 1 || !strings.Contains(r.failures[0], tc.wantFail) {
+				t.Errorf("断言失败信息应该包含 %q，实际: %v", tc.wantFail, r.failures)
+			}
+		})
+	}
+}
+
+func TestAssertGRPCStatusDoesNotModifyError(t *testing.T) {
+	errors.SetIDGenerator(errors.IDGeneratorFunc(func(int) string { return "" }))
+	t.Cleanup(func() { errors.SetIDGenerator(nil) })
+
+	err := errors.NotFound("USER_NOT_FOUND", "用户不存在")
+	errors.SetIDGenerator(errors.IDGeneratorFunc(func(int) string { return "generated" }))
+	r := &recorder{TB: t}
+	AssertGRPCStatus(r, err, codes.NotFound, "USER_NOT_FOUND")
+	if len(r.failures) != 0 {
+		t.Errorf("不应该断言失败，实际: %v", r.failures)
+	}
+	if err.ID != "" {
+		t.Errorf("断言不应该为错误生成ID，实际: %q", err.ID)
+	}
+}
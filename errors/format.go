@@ -0,0 +1,120 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Format implements fmt.Formatter. %s and %v print the same compact text as
+// Error and %q prints the quoted message. %+v prints a multi-line report with
+// the code, reason, message, metadata and ID on separate lines, followed by
+// every error in the cause chain in unwrap order and, when captured, the stack
+// frames of the error's creation.
+func (e *Error) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			e.formatVerbose(s)
+			return
+		}
+		io.WriteString(s, e.Error())
+	case 's':
+		io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Message)
+	default:
+		fmt.Fprintf(s, "%%!%c(*errors.Error=%s)", verb, e.Error())
+	}
+}
+
+// formatVerbose 输出 %+v 的多行格式
+func (e *Error) formatVerbose(w io.Writer) {
+	fmt.Fprintf(w, "code: %d\n", e.Code)
+	fmt.Fprintf(w, "reason: %s\n", e.Reason)
+	fmt.Fprintf(w, "message: %s\n", e.Message)
+	fmt.Fprintf(w, "metadata: %v\n", e.RedactedMetadata())
+	fmt.Fprintf(w, "id: %s", e.id())
+
+	depth := 0
+	for cause := e.cause; cause != nil; cause = stderrors.Unwrap(cause) {
+		depth++
+		if se, ok := cause.(*Error); ok {
+			fmt.Fprintf(w, "\ncause[%d]: code = %d reason = %s message = %s", depth, se.Code, se.Reason, se.Message)
+		} else {
+			fmt.Fprintf(w, "\ncause[%d]: %s", depth, cause.Error())
+		}
+	}
+
+	for _, frame := range e.Frames() {
+		fmt.Fprintf(w, "\n%s\n\t%s:%d", frame.Function, frame.File, frame.Line)
+	}
+}
+
+// Logfmt renders the error as a single logfmt line, for example:
+//
+//	code=404 reason=NOT_FOUND id=djE6... msg="user not found" table=users
+//
+// The core fields come first, followed by the metadata sorted by key and the
+// cause, if any; redacted metadata keys are scrubbed, see
+// SetRedactedMetadataKeys. Keys and values containing spaces, quotes, '=' or
+// control characters are quoted and escaped.
+func (e *Error) Logfmt() string {
+	var b strings.Builder
+	b.Grow(128)
+
+	writeLogfmtPair(&b, "code", strconv.Itoa(int(e.Code)))
+	writeLogfmtPair(&b, "reason", e.Reason)
+	writeLogfmtPair(&b, "id", e.id())
+	writeLogfmtPair(&b, "msg", e.Message)
+
+	metadata := e.RedactedMetadata()
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		writeLogfmtPair(&b, k, metadata[k])
+	}
+
+	if e.cause != nil {
+		writeLogfmtPair(&b, "cause", e.cause.Error())
+	}
+	return b.String()
+}
+
+// writeLogfmtPair 写入一个 key=value 对，必要时对键和值加引号转义
+func writeLogfmtPair(b *strings.Builder, key, value string) {
+	if b.Len() > 0 {
+		b.WriteByte(' ')
+	}
+	writeLogfmtValue(b, key)
+	b.WriteByte('=')
+	writeLogfmtValue(b, value)
+}
+
+// writeLogfmtValue 写入键或值，包含特殊字符时加引号转义
+func writeLogfmtValue(b *strings.Builder, s string) {
+	if needsLogfmtQuote(s) {
+		b.WriteString(strconv.Quote(s))
+	} else {
+		b.WriteString(s)
+	}
+}
+
+// needsLogfmtQuote 判断键或值是否需要加引号
+func needsLogfmtQuote(value string) bool {
+	if value == "" {
+		return true
+	}
+	for _, r := range value {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}
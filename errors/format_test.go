@@ -0,0 +1,72 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLogfmt(t *testing.T) {
+	err := New(404, "NOT_FOUND", `user "alice" not found`).
+		WithID("abc123").
+		WithMetadata(map[string]string{
+			"table":  "users",
+			"query":  "id = 1",
+			"action": "select",
+		}).
+		WithCause(fmt.Errorf("sql: no rows"))
+
+	want := `code=404 reason=NOT_FOUND id=abc123 msg="user \"alice\" not found" action=select query="id = 1" table=users cause="sql: no rows"`
+	if got := err.Logfmt(); got != want {
+		t.Errorf("Logfmt输出不正确\n期望: %s\n实际: %s", want, got)
+	}
+
+	// 包含特殊字符的metadata键与值一样需要加引号
+	odd := New(400, "BAD", "bad").WithID("x").WithMetadata(map[string]string{"user id": "1", "a=b": "c"})
+	if got, want := odd.Logfmt(), `code=400 reason=BAD id=x msg=bad "a=b"=c "user id"=1`; got != want {
+		t.Errorf("metadata键的Logfmt输出不正确\n期望: %s\n实际: %s", want, got)
+	}
+
+	// 空值需要加引号，保证可被解析
+	empty := &Error{Status: Status{Code: 500}}
+	if got, want := empty.Logfmt(), `code=500 reason="" id="" msg=""`; got != want {
+		t.Errorf("空值的Logfmt输出不正确\n期望: %s\n实际: %s", want, got)
+	}
+}
+
+func TestFormatVerbose(t *testing.T) {
+	root := fmt.Errorf("dial tcp: connection refused")
+	repo := InternalServer("DB_ERROR", "数据库错误").WithCause(fmt.Errorf("query users: %w", root))
+	err := NotFound("USER_NOT_FOUND", "用户不存在").
+		WithID("id-123").
+		WithMetadata(map[string]string{"user_id": "42"}).
+		WithCause(repo)
+
+	if compact := fmt.Sprintf("%v", err); compact != err.Error() {
+		t.Errorf("%%v 应该与Error()一致，实际: %s", compact)
+	}
+	if quoted := fmt.Sprintf("%q", err); quoted != `"用户不存在"` {
+		t.Errorf("%%q 应该输出带引号的message，实际: %s", quoted)
+	}
+
+	verbose := fmt.Sprintf("%+v", err)
+	lines := strings.Split(verbose, "\n")
+	want := []string{
+		"code: 404",
+		"reason: USER_NOT_FOUND",
+		"message: 用户不存在",
+		"metadata: map[user_id:42]",
+		"id: id-123",
+		"cause[1]: code = 500 reason = DB_ERROR message = 数据库错误",
+		"cause[2]: query users: dial tcp: connection refused",
+		"cause[3]: dial tcp: connection refused",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("%%+v 应该输出 %d 行，实际:\n%s", len(want), verbose)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("第 %d 行不正确\n期望: %s\n实际: %s", i+1, want[i], lines[i])
+		}
+	}
+}
@@ -0,0 +1,357 @@
+package errors
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// IDGenerator generates error IDs for New, Newf, Errorf, GetID and friends.
+//
+// skip is the number of stack frames between the caller of Generate and the
+// code that created the error: Generate(0) refers to the function that called
+// Generate. Generators that do not embed call-site information may ignore it.
+type IDGenerator interface {
+	Generate(skip int) string
+}
+
+// IDGeneratorFunc adapts an ordinary function to the IDGenerator interface.
+type IDGeneratorFunc func(skip int) string
+
+// Generate calls f(skip + 1) so that f sees the same frames as a generator
+// implementing the interface directly.
+func (f IDGeneratorFunc) Generate(skip int) string { return f(skip + 1) }
+
+// idGenerator 当前使用的ID生成器，通过原子指针读取以支持运行时替换
+var idGenerator atomic.Pointer[IDGenerator]
+
+// idGenerationDisabled 为 true 时不生成任何错误ID
+var idGenerationDisabled atomic.Bool
+
+// SetIDGenerationEnabled turns error ID generation on or off globally. While
+// disabled, New, Newf, Errorf and FromError leave ID empty, GetID returns an
+// empty string and GRPCStatus omits the error_id metadata entry. IDs set
+// explicitly with WithID are kept. Generation is enabled by default; turning
+// it off removes the runtime.Caller and runtime.Stack cost from hot paths.
+func SetIDGenerationEnabled(enabled bool) {
+	idGenerationDisabled.Store(!enabled)
+}
+
+// buildID 嵌入默认生成器ID中的构建标识
+var buildID atomic.Pointer[string]
+
+// SetBuildID embeds id, typically the git commit or release of the running
+// binary, into IDs produced by the default generator so DecodeErrorID can tell
+// which build produced an error. Colons are replaced with underscores because
+// they delimit the ID fields. An empty id stops embedding a build ID.
+func SetBuildID(id string) {
+	id = strings.ReplaceAll(id, ":", "_")
+	buildID.Store(&id)
+}
+
+// currentBuildID 返回当前设置的构建标识
+func currentBuildID() string {
+	if id := buildID.Load(); id != nil {
+		return *id
+	}
+	return ""
+}
+
+// FilePathMode controls how much of the source file path the default
+// generator embeds in error IDs.
+type FilePathMode int32
+
+const (
+	// BaseName embeds the file name only, e.g. "service.go". It is the default.
+	BaseName FilePathMode = iota
+	// PackageRelative embeds the file name and its directory, e.g.
+	// "user/service.go", so that files with the same name in different
+	// packages can be told apart.
+	PackageRelative
+	// Full embeds the full path the file was compiled from. It makes IDs
+	// considerably longer; build with -trimpath to keep local paths out.
+	Full
+)
+
+// filePathMode 默认生成器嵌入的文件路径形式
+var filePathMode atomic.Int32
+
+// SetFilePathMode sets how much of the file path IDs produced by the default
+// generator embed, trading ID length for precision. Colons in the path are
+// replaced with underscores because they delimit the ID fields. DecodeErrorID
+// returns the embedded path as ErrorIDInfo.File whatever the mode.
+func SetFilePathMode(mode FilePathMode) {
+	filePathMode.Store(int32(mode))
+}
+
+// idFileName 按当前的文件路径模式截取嵌入ID的文件路径，runtime 返回的路径总是使用 /
+func idFileName(file string) string {
+	switch FilePathMode(filePathMode.Load()) {
+	case Full:
+		return strings.ReplaceAll(file, ":", "_")
+	case PackageRelative:
+		if i := strings.LastIndexByte(file, '/'); i > 0 {
+			if j := strings.LastIndexByte(file[:i], '/'); j >= 0 {
+				return strings.ReplaceAll(file[j+1:], ":", "_")
+			}
+		}
+		return strings.ReplaceAll(file, ":", "_")
+	default:
+		return filepath.Base(file)
+	}
+}
+
+// IDProfile controls which fields the default generator embeds in error IDs.
+type IDProfile int32
+
+const (
+	// FullProfile embeds the call site, timestamp, goroutine ID, process ID,
+	// random suffix and the optional build, instance and trace IDs. It is
+	// the default.
+	FullProfile IDProfile = iota
+	// CompactProfile embeds the timestamp and random suffix only, so IDs
+	// returned to public clients stay unique and carry their creation time
+	// without revealing anything about the service internals.
+	CompactProfile
+)
+
+// idProfile 默认生成器使用的ID字段组合
+var idProfile atomic.Int32
+
+// SetIDProfile sets which fields IDs produced by the default generator embed.
+// DecodeErrorID decodes IDs of either profile; for compact IDs it reports
+// IsCompact and leaves the call site, goroutine and process fields empty. To
+// trace a compact ID back to where it was created, look it up in the server
+// logs or in the ErrorStore enabled with SetErrorStoreSize.
+func SetIDProfile(profile IDProfile) {
+	idProfile.Store(int32(profile))
+}
+
+// Bounds of the random suffix width set with SetRandomSuffixBytes.
+const (
+	MinRandomSuffixBytes = 1
+	MaxRandomSuffixBytes = 16
+)
+
+// defaultRandomSuffixBytes 默认的随机后缀字节数
+const defaultRandomSuffixBytes = 4
+
+// randomSuffixBytes 随机后缀的字节数，0 表示 defaultRandomSuffixBytes
+var randomSuffixBytes atomic.Int32
+
+// SetRandomSuffixBytes sets the number of random bytes in the suffix of IDs
+// produced by the default generator, written as 2n hex digits. The default
+// of 4 bytes makes collisions between IDs created in the same nanosecond on
+// the same goroutine unlikely; services creating errors at very high rates
+// can widen it at the cost of longer IDs. n is clamped to
+// [MinRandomSuffixBytes, MaxRandomSuffixBytes]. DecodeErrorID treats the
+// suffix as opaque, so IDs of any width decode.
+func SetRandomSuffixBytes(n int) {
+	randomSuffixBytes.Store(int32(min(max(n, MinRandomSuffixBytes), MaxRandomSuffixBytes)))
+}
+
+// currentRandomSuffixBytes 返回当前的随机后缀字节数
+func currentRandomSuffixBytes() int {
+	if n := randomSuffixBytes.Load(); n != 0 {
+		return int(n)
+	}
+	return defaultRandomSuffixBytes
+}
+
+// idEncoding 默认生成器使用的base64编码
+var idEncoding atomic.Pointer[base64.Encoding]
+
+// SetIDEncoding sets the base64 encoding of IDs produced by the default
+// generator. The default is base64.RawURLEncoding, whose IDs can be put in
+// URLs and query strings as is; pass base64.StdEncoding to keep producing IDs
+// in the previous format. Passing nil restores the default. DecodeErrorID
+// accepts IDs in either alphabet, with or without padding, regardless of this
+// setting.
+func SetIDEncoding(enc *base64.Encoding) {
+	idEncoding.Store(enc)
+}
+
+// currentIDEncoding 返回当前的ID编码，未设置时使用URL安全的无填充编码
+func currentIDEncoding() *base64.Encoding {
+	if enc := idEncoding.Load(); enc != nil {
+		return enc
+	}
+	return base64.RawURLEncoding
+}
+
+// encodeID 使用当前的编码对ID载荷编码
+func encodeID(raw []byte) string {
+	return currentIDEncoding().EncodeToString(raw)
+}
+
+// decodeID 解码ID载荷，先尝试当前编码，再依次尝试其他base64编码以兼容旧ID
+func decodeID(id string) ([]byte, error) {
+	return decodeIDInto(nil, []byte(id))
+}
+
+// decodeIDInto 与 decodeID 相同，但将结果写入 dst 并复用其容量
+func decodeIDInto(dst, id []byte) ([]byte, error) {
+	current := currentIDEncoding()
+	decoded, err := current.AppendDecode(dst[:0], id)
+	if err == nil {
+		return decoded, nil
+	}
+	for _, enc := range []*base64.Encoding{
+		base64.RawURLEncoding, base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding,
+	} {
+		if enc == current {
+			continue
+		}
+		if decoded, encErr := enc.AppendDecode(dst[:0], id); encErr == nil {
+			return decoded, nil
+		}
+	}
+	return nil, err
+}
+
+// instanceID 嵌入默认生成器ID中的实例标识
+var instanceID atomic.Pointer[string]
+
+// hostname 启动时缓存的短主机名，避免每次生成ID都进行系统调用
+var hostname = shortHostname()
+
+// shortHostname 返回主机名中第一个点之前的部分
+func shortHostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+	name, _, _ = strings.Cut(name, ".")
+	return name
+}
+
+// SetInstanceID embeds id, such as a pod or node name, into IDs produced by
+// the default generator so DecodeErrorID can tell which instance produced an
+// error when process IDs collide across nodes. Colons are replaced with
+// underscores. An empty id stops embedding an instance ID, which is the
+// default to keep IDs short.
+func SetInstanceID(id string) {
+	id = strings.ReplaceAll(id, ":", "_")
+	instanceID.Store(&id)
+}
+
+// SetInstanceIDFromHostname is like SetInstanceID with the short host name,
+// the part of os.Hostname before the first dot, read once at startup.
+func SetInstanceIDFromHostname() {
+	SetInstanceID(hostname)
+}
+
+// currentInstanceID 返回当前设置的实例标识
+func currentInstanceID() string {
+	if id := instanceID.Load(); id != nil {
+		return *id
+	}
+	return ""
+}
+
+// idClock 默认生成器使用的时钟，nil 表示 time.Now
+var idClock atomic.Pointer[func() time.Time]
+
+// idRandSource 默认生成器使用的随机源，nil 表示 crypto/rand
+var idRandSource atomic.Pointer[io.Reader]
+
+// SetClock replaces the clock the default generator reads timestamps from,
+// so that tests can freeze time. Passing nil restores time.Now.
+func SetClock(now func() time.Time) {
+	if now == nil {
+		idClock.Store(nil)
+		return
+	}
+	idClock.Store(&now)
+}
+
+// SetRandSource replaces the source of the random suffix of IDs produced by
+// the default generator, e.g. with a seeded math/rand.Rand in tests. Together
+// with SetClock it makes IDs created from the same line of the same
+// goroutine reproducible. Passing nil restores crypto/rand.
+func SetRandSource(r io.Reader) {
+	if r == nil {
+		idRandSource.Store(nil)
+		return
+	}
+	idRandSource.Store(&r)
+}
+
+// idNow 返回默认生成器使用的当前时间
+func idNow() time.Time {
+	if now := idClock.Load(); now != nil {
+		return (*now)()
+	}
+	return time.Now()
+}
+
+// readRandom 从默认生成器使用的随机源读取随机字节
+func readRandom(buf []byte) error {
+	r := rand.Reader
+	if src := idRandSource.Load(); src != nil {
+		r = *src
+	}
+	_, err := io.ReadFull(r, buf)
+	return err
+}
+
+// goroutineIDDisabled 为 true 时默认生成器不再通过 runtime.Stack 获取 goroutine ID
+var goroutineIDDisabled atomic.Bool
+
+// SetGoroutineIDEnabled turns the goroutine ID field of IDs produced by the
+// default generator on or off. Looking it up requires a runtime.Stack call on
+// every error, which dominates the cost of New; Go offers no goroutine-local
+// storage to cache it in. While disabled the field is written as 0, so IDs
+// keep their layout and still decode. It is enabled by default.
+func SetGoroutineIDEnabled(enabled bool) {
+	goroutineIDDisabled.Store(!enabled)
+}
+
+// IDGenerationEnabled reports whether error IDs are currently generated.
+func IDGenerationEnabled() bool {
+	return !idGenerationDisabled.Load()
+}
+
+// SetIDGenerator replaces the generator used for error IDs, e.g. with a ULID,
+// UUIDv7 or trace-correlated implementation. Passing nil restores the default
+// generator, which encodes the call site and process information that
+// DecodeErrorID understands. It is safe to call concurrently with error
+// creation.
+func SetIDGenerator(g IDGenerator) {
+	if g == nil {
+		idGenerator.Store(nil)
+		return
+	}
+	idGenerator.Store(&g)
+}
+
+// currentIDGenerator 返回当前的ID生成器，未设置时返回默认实现
+func currentIDGenerator() IDGenerator {
+	if g := idGenerator.Load(); g != nil {
+		return *g
+	}
+	return defaultIDGenerator{}
+}
+
+// defaultIDGenerator 默认的ID生成器，生成可由 DecodeErrorID 解码的ID
+type defaultIDGenerator struct{}
+
+// Generate 生成包含调用位置、时间戳、goroutine ID、进程ID和随机后缀的ID
+func (defaultIDGenerator) Generate(skip int) string {
+	if IDProfile(idProfile.Load()) == CompactProfile {
+		return generateCompactErrorID()
+	}
+
+	// 使用内部函数尝试生成完整的错误ID
+	if id := tryGenerateErrorID(skip + 2); id != "" {
+		return id
+	}
+
+	// 如果内部函数失败，返回备用ID
+	return generateFallbackErrorID()
+}
@@ -0,0 +1,358 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	mathrand "math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fixedIDGenerator 总是返回固定ID的生成器
+type fixedIDGenerator struct {
+	id string
+}
+
+func (g fixedIDGenerator) Generate(int) string { return g.id }
+
+func TestSetIDGenerator(t *testing.T) {
+	SetIDGenerator(fixedIDGenerator{id: "fixed-id"})
+	t.Cleanup(func() { SetIDGenerator(nil) })
+
+	if id := New(400, "FIXED", "固定ID").ID; id != "fixed-id" {
+		t.Errorf("New应该使用配置的生成器，实际: %s", id)
+	}
+	if id := Newf(400, "FIXED", "固定ID %d", 1).ID; id != "fixed-id" {
+		t.Errorf("Newf应该使用配置的生成器，实际: %s", id)
+	}
+	if id := FromError(fmt.Errorf("plain")).ID; id != "fixed-id" {
+		t.Errorf("FromError应该使用配置的生成器，实际: %s", id)
+	}
+	if id := (&Error{}).GetID(); id != "fixed-id" {
+		t.Errorf("GetID应该使用配置的生成器，实际: %s", id)
+	}
+
+	// 恢复默认生成器
+	SetIDGenerator(nil)
+	if _, err := DecodeErrorID(New(400, "DEFAULT", "默认ID").ID); err != nil {
+		t.Errorf("恢复默认生成器后ID应该可以解码: %v", err)
+	}
+}
+
+func TestIDGeneratorFuncSkip(t *testing.T) {
+	// IDGeneratorFunc 应该看到与直接实现接口相同的调用栈
+	SetIDGenerator(IDGeneratorFunc(func(skip int) string {
+		// 跳过 generateErrorIDInternal 和当前函数自身
+		return generateErrorIDInternal(skip + 2)
+	}))
+	t.Cleanup(func() { SetIDGenerator(nil) })
+
+	info, err := DecodeErrorID(New(400, "FUNC", "函数生成器").ID)
+	if err != nil {
+		t.Fatalf("解码错误ID失败: %v", err)
+	}
+	if info.Function != "TestIDGeneratorFuncSkip" {
+		t.Errorf("错误ID应该指向调用者，实际: %s", info.Function)
+	}
+}
+
+func TestSetIDGenerationEnabled(t *testing.T) {
+	SetIDGenerationEnabled(false)
+	t.Cleanup(func() { SetIDGenerationEnabled(true) })
+
+	err := New(500, "NO_ID", "关闭ID生成")
+	if err.ID != "" {
+		t.Errorf("关闭ID生成时New不应该生成ID，实际: %s", err.ID)
+	}
+	if id := err.GetID(); id != "" {
+		t.Errorf("关闭ID生成时GetID应该返回空字符串，实际: %s", id)
+	}
+
+	// GRPCStatus 不应该携带空的 error_id
+	converted := FromError(err.GRPCStatus().Err())
+	if _, ok := converted.Metadata["error_id"]; ok || converted.ID != "" {
+		t.Errorf("关闭ID生成时gRPC状态不应该包含error_id，实际: %v", converted.Metadata)
+	}
+	if converted.Reason != "NO_ID" {
+		t.Errorf("gRPC往返后reason应该保留，实际: %s", converted.Reason)
+	}
+
+	// 显式设置的ID仍然保留
+	if id := err.WithID("manual").GetID(); id != "manual" {
+		t.Errorf("显式设置的ID应该保留，实际: %s", id)
+	}
+
+	SetIDGenerationEnabled(true)
+	if New(500, "WITH_ID", "开启ID生成").ID == "" {
+		t.Error("重新开启后应该生成ID")
+	}
+}
+
+func TestSetGoroutineIDEnabled(t *testing.T) {
+	SetGoroutineIDEnabled(false)
+	t.Cleanup(func() { SetGoroutineIDEnabled(true) })
+
+	info, err := DecodeErrorID(New(500, "NO_GID", "不记录goroutine ID").ID)
+	if err != nil {
+		t.Fatalf("关闭goroutine ID后ID仍应该可以解码: %v", err)
+	}
+	if info.GoroutineID != 0 || info.Function != "TestSetGoroutineIDEnabled" || info.ProcessID == 0 {
+		t.Errorf("只有goroutine ID应该为0，实际: %+v", info)
+	}
+
+	SetGoroutineIDEnabled(true)
+	if info, _ := DecodeErrorID(New(500, "WITH_GID", "记录goroutine ID").ID); info.GoroutineID == 0 {
+		t.Error("重新开启后应该记录goroutine ID")
+	}
+}
+
+// BenchmarkNewWithoutGoroutineID 与 BenchmarkNewError 对比省去 runtime.Stack 的开销
+func BenchmarkNewWithoutGoroutineID(b *testing.B) {
+	SetGoroutineIDEnabled(false)
+	defer SetGoroutineIDEnabled(true)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchErrSink = New(400, "BENCH", "基准测试错误")
+	}
+}
+
+// BenchmarkNewWithoutIDGeneration 与 BenchmarkNewError 对比关闭ID生成的开销
+func BenchmarkNewWithoutIDGeneration(b *testing.B) {
+	SetIDGenerationEnabled(false)
+	defer SetIDGenerationEnabled(true)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchErrSink = New(400, "BENCH", "基准测试错误")
+	}
+}
+
+func TestSetBuildID(t *testing.T) {
+	SetBuildID("v1.2.3:abc123")
+	t.Cleanup(func() { SetBuildID("") })
+
+	info, err := DecodeErrorID(New(500, "BUILD", "构建标识").ID)
+	if err != nil {
+		t.Fatalf("解码错误ID失败: %v", err)
+	}
+	if info.BuildID != "v1.2.3_abc123" {
+		t.Errorf("错误ID应该包含构建标识，实际: %q", info.BuildID)
+	}
+	if info.Function != "TestSetBuildID" || info.RandomSuffix == "" {
+		t.Errorf("附加构建标识不应该影响其他字段，实际: %+v", info)
+	}
+
+	SetBuildID("")
+	if info, _ := DecodeErrorID(New(500, "BUILD", "构建标识").ID); info.BuildID != "" {
+		t.Errorf("清除后不应该再包含构建标识，实际: %q", info.BuildID)
+	}
+}
+
+func TestSetFilePathMode(t *testing.T) {
+	t.Cleanup(func() { SetFilePathMode(BaseName) })
+
+	// 同一个调用位置在不同模式下嵌入的路径不同
+	newErr := func() *Error { return New(500, "PATH", "文件路径") }
+	fileIn := func(mode FilePathMode) string {
+		SetFilePathMode(mode)
+		info, err := DecodeErrorID(newErr().ID)
+		if err != nil {
+			t.Fatalf("解码错误ID失败: %v", err)
+		}
+		return info.File
+	}
+
+	if file := fileIn(BaseName); file != "generator_test.go" {
+		t.Errorf("BaseName 应该只包含文件名，实际: %q", file)
+	}
+	if file := fileIn(PackageRelative); file != "errors/generator_test.go" {
+		t.Errorf("PackageRelative 应该包含所在目录，实际: %q", file)
+	}
+	if file := fileIn(Full); !strings.HasSuffix(file, "/errors/generator_test.go") || file == "errors/generator_test.go" {
+		t.Errorf("Full 应该包含完整路径，实际: %q", file)
+	}
+
+	// 完整路径中的冒号和 @ 不应该破坏ID的解析
+	SetFilePathMode(Full)
+	id := formatErrorID("Get", idFileName("C:/go/pkg/mod/example.com/m@v1.0.0/user/service.go"), 12, 1, 7)
+	info, err := DecodeErrorID(id)
+	if err != nil {
+		t.Fatalf("解码错误ID失败: %v", err)
+	}
+	if info.Function != "Get" || info.File != "C_/go/pkg/mod/example.com/m@v1.0.0/user/service.go" || info.Line != 12 {
+		t.Errorf("完整路径解析错误: %+v", info)
+	}
+}
+
+func TestSetIDProfile(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	SetClock(func() time.Time { return created })
+	SetBuildID("3f2c1ab")
+	SetTraceIDFunc(func(context.Context) string { return "trace-1" })
+	t.Cleanup(func() {
+		SetIDProfile(FullProfile)
+		SetClock(nil)
+		SetBuildID("")
+		SetTraceIDFunc(nil)
+	})
+
+	full, err := DecodeErrorID(NewContext(context.Background(), 500, "PROFILE", "完整ID").ID)
+	if err != nil {
+		t.Fatalf("解码完整ID失败: %v", err)
+	}
+	if full.IsCompact || full.Function != "TestSetIDProfile" || full.BuildID != "3f2c1ab" || full.TraceID != "trace-1" {
+		t.Errorf("默认应该生成完整ID，实际: %+v", full)
+	}
+
+	SetIDProfile(CompactProfile)
+	id := NewContext(context.Background(), 500, "PROFILE", "精简ID").ID
+	info, err := DecodeErrorID(id)
+	if err != nil {
+		t.Fatalf("解码精简ID失败: %v", err)
+	}
+	if !info.IsCompact || !info.Time().Equal(created) || info.RandomSuffix == "" {
+		t.Errorf("精简ID应该包含时间戳和随机后缀，实际: %+v", info)
+	}
+	if info.Function != "" || info.File != "" || info.Line != 0 || info.GoroutineID != 0 || info.ProcessID != 0 ||
+		info.BuildID != "" || info.TraceID != "" {
+		t.Errorf("精简ID不应该包含调试字段，实际: %+v", info)
+	}
+	if strings.Contains(info.Raw, "generator_test") {
+		t.Errorf("精简ID不应该包含文件名，实际: %q", info.Raw)
+	}
+}
+
+func TestErrorIDSkipsPackageFrames(t *testing.T) {
+	t.Cleanup(func() { SetLazyIDGeneration(false) })
+
+	// NotFound 等便利函数是 New 的包装，ID应该指向调用便利函数的用户代码
+	for _, lazy := range []bool{false, true} {
+		SetLazyIDGeneration(lazy)
+		for name, err := range map[string]*Error{
+			"NotFound":       NotFound("NOT_FOUND", "未找到"),
+			"FromError":      FromError(fmt.Errorf("plain")),
+			"NewFromMissing": NewFromTemplate("NO_SUCH_TEMPLATE"),
+		} {
+			info, decodeErr := DecodeErrorID(err.GetID())
+			if decodeErr != nil {
+				t.Fatalf("%s: 解码错误ID失败: %v", name, decodeErr)
+			}
+			if info.Function != "TestErrorIDSkipsPackageFrames" || info.File != "generator_test.go" {
+				t.Errorf("%s (lazy=%v): 错误ID应该指向用户代码，实际: %s@%s:%d", name, lazy, info.Function, info.File, info.Line)
+			}
+		}
+	}
+}
+
+func TestErrorIDNoCaller(t *testing.T) {
+	// skip 超出调用栈深度
+	info, err := DecodeErrorID(generateErrorIDInternal(1000))
+	if err != nil {
+		t.Fatalf("解码错误ID失败: %v", err)
+	}
+	if !info.NoCaller || info.Function != NoCallerMarker || info.File != NoCallerMarker || info.Line != 0 {
+		t.Errorf("没有调用位置时应该记录 %s 标记，实际: %+v", NoCallerMarker, info)
+	}
+
+	if info, _ := DecodeErrorID(New(500, "CALLER", "有调用位置").ID); info.NoCaller {
+		t.Errorf("正常的错误ID不应该标记为没有调用位置: %+v", info)
+	}
+}
+
+func TestSetInstanceID(t *testing.T) {
+	if info, _ := DecodeErrorID(New(500, "INSTANCE", "实例标识").ID); info.InstanceID != "" {
+		t.Errorf("默认不应该包含实例标识，实际: %q", info.InstanceID)
+	}
+
+	SetBuildID("v1.2.3")
+	SetInstanceID("pod-7:us-east")
+	t.Cleanup(func() {
+		SetBuildID("")
+		SetInstanceID("")
+	})
+
+	info, err := DecodeErrorID(New(500, "INSTANCE", "实例标识").ID)
+	if err != nil {
+		t.Fatalf("解码错误ID失败: %v", err)
+	}
+	if info.InstanceID != "pod-7_us-east" || info.BuildID != "v1.2.3" {
+		t.Errorf("错误ID应该包含实例标识和构建标识，实际: %+v", info)
+	}
+	if info.Function != "TestSetInstanceID" || info.ProcessID == 0 {
+		t.Errorf("附加实例标识不应该影响其他字段，实际: %+v", info)
+	}
+
+	SetInstanceIDFromHostname()
+	if info, _ := DecodeErrorID(New(500, "INSTANCE", "实例标识").ID); info.InstanceID != hostname {
+		t.Errorf("实例标识应该是缓存的主机名 %q，实际: %q", hostname, info.InstanceID)
+	}
+	if strings.Contains(hostname, ".") {
+		t.Errorf("主机名应该是第一个点之前的部分，实际: %q", hostname)
+	}
+}
+
+func TestDeterministicIDs(t *testing.T) {
+	frozen := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	newID := func() string {
+		SetClock(func() time.Time { return frozen })
+		SetRandSource(mathrand.New(mathrand.NewSource(42)))
+		return New(404, "NOT_FOUND", "不存在").ID
+	}
+	t.Cleanup(func() {
+		SetClock(nil)
+		SetRandSource(nil)
+	})
+
+	first, second := newID(), newID()
+	if first != second {
+		t.Errorf("冻结时钟和固定随机源后ID应该相同:\n%s\n%s", first, second)
+	}
+	info, err := DecodeErrorID(first)
+	if err != nil {
+		t.Fatalf("解码错误ID失败: %v", err)
+	}
+	if info.Timestamp != frozen.UnixNano() {
+		t.Errorf("时间戳应该来自设置的时钟，实际: %d", info.Timestamp)
+	}
+
+	SetClock(nil)
+	SetRandSource(nil)
+	if newDefault := New(404, "NOT_FOUND", "不存在").ID; newDefault == first {
+		t.Error("恢复默认设置后ID不应该再固定")
+	}
+}
+
+func TestSetRandomSuffixBytes(t *testing.T) {
+	// 冻结时钟后同一行、同一协程创建的ID只有随机后缀不同
+	SetClock(func() time.Time { return time.Unix(0, 1700000000000000000) })
+	t.Cleanup(func() {
+		SetClock(nil)
+		SetRandomSuffixBytes(4)
+	})
+
+	SetRandomSuffixBytes(MaxRandomSuffixBytes)
+	ids := make(map[string]bool)
+	for i := 0; i < 10000; i++ {
+		id := New(500, "STRESS", "唯一性").ID
+		if ids[id] {
+			t.Fatalf("第 %d 个ID重复: %s", i, id)
+		}
+		ids[id] = true
+	}
+
+	suffixLen := func() int {
+		info, err := DecodeErrorID(New(500, "WIDTH", "宽度").ID)
+		if err != nil {
+			t.Fatalf("解码错误ID失败: %v", err)
+		}
+		return len(info.RandomSuffix)
+	}
+	for _, tt := range []struct{ n, want int }{{8, 16}, {0, 2}, {100, 32}, {4, 8}} {
+		SetRandomSuffixBytes(tt.n)
+		if got := suffixLen(); got != tt.want {
+			t.Errorf("SetRandomSuffixBytes(%d) 后随机后缀应该有 %d 个十六进制字符，实际: %d", tt.n, tt.want, got)
+		}
+	}
+}
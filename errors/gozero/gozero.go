@@ -0,0 +1,66 @@
+// Package gozero teaches errors.FromError about the errors returned by
+// go-zero components, so they map to meaningful codes instead of an opaque 500.
+//
+// Import it for its side effects:
+//
+//	import _ "github.com/honeybbq/protoc-gen-go-zero-errors/errors/gozero"
+package gozero
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+
+	"github.com/zeromicro/go-zero/core/breaker"
+	"github.com/zeromicro/go-zero/core/load"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+)
+
+// Reasons assigned to the go-zero errors recognised by this package.
+const (
+	// ReasonCircuitBreakerOpen is used for breaker.ErrServiceUnavailable.
+	ReasonCircuitBreakerOpen = "CIRCUIT_BREAKER_OPEN"
+	// ReasonServiceOverloaded is used for load.ErrServiceOverloaded.
+	ReasonServiceOverloaded = "SERVICE_OVERLOADED"
+	// ReasonRequestTooLarge is used when a request body exceeds the limit set
+	// by rest's MaxBytesHandler.
+	ReasonRequestTooLarge = "REQUEST_TOO_LARGE"
+)
+
+func init() {
+	errors.RegisterConverter(Convert)
+}
+
+// Convert maps errors produced by go-zero components to an *errors.Error
+// with a fixed message; the original error is kept as the cause and is not
+// sent to clients. It returns nil for errors it does not recognise, including
+// context cancellations and deadlines, which errors.FromError maps itself.
+func Convert(err error) *errors.Error {
+	if stderrors.Is(err, context.Canceled) || stderrors.Is(err, context.DeadlineExceeded) {
+		return nil
+	}
+	switch {
+	case stderrors.Is(err, breaker.ErrServiceUnavailable):
+		return newError(http.StatusServiceUnavailable, ReasonCircuitBreakerOpen, "service unavailable")
+	case stderrors.Is(err, load.ErrServiceOverloaded):
+		return newError(http.StatusServiceUnavailable, ReasonServiceOverloaded, "service overloaded")
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if stderrors.As(err, &maxBytesErr) {
+		return newError(http.StatusRequestEntityTooLarge, ReasonRequestTooLarge, "request body too large")
+	}
+	return nil
+}
+
+// newError 构造不带ID的错误，ID和cause由 errors.FromError 补充
+func newError(code int, reason, message string) *errors.Error {
+	return &errors.Error{
+		Status: errors.Status{
+			Code:    int32(code),
+			Reason:  reason,
+			Message: message,
+		},
+	}
+}
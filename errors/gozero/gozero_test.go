@@ -0,0 +1,74 @@
+package gozero
+
+import (
+	"context"
+	"database/sql"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/zeromicro/go-zero/core/breaker"
+	"github.com/zeromicro/go-zero/core/load"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+)
+
+func TestFromErrorGoZeroErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		err    error
+		code   int32
+		reason string
+		msg    string
+	}{
+		{"熔断器打开", breaker.ErrServiceUnavailable, 503, ReasonCircuitBreakerOpen, "service unavailable"},
+		{"包装的熔断错误", fmt.Errorf("call user rpc 10.0.0.5:8080: %w", breaker.ErrServiceUnavailable), 503, ReasonCircuitBreakerOpen, "service unavailable"},
+		{"自适应降载", load.ErrServiceOverloaded, 503, ReasonServiceOverloaded, "service overloaded"},
+		{"请求体过大", &http.MaxBytesError{Limit: 1024}, 413, ReasonRequestTooLarge, "request body too large"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			appErr := errors.FromError(tc.err)
+			if appErr.Code != tc.code || appErr.Reason != tc.reason {
+				t.Errorf("期望 %d/%s，实际 %d/%s", tc.code, tc.reason, appErr.Code, appErr.Reason)
+			}
+			if appErr.Message != tc.msg {
+				t.Errorf("message应该是固定的 %q，不应该沿用原始错误，实际: %s", tc.msg, appErr.Message)
+			}
+			if appErr.GetID() == "" {
+				t.Error("转换后的错误应该有错误ID")
+			}
+			if !stderrors.Is(appErr, tc.err) {
+				t.Error("转换后的错误应该保留原始cause")
+			}
+		})
+	}
+}
+
+func TestConvertIgnoresOtherErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+	}{
+		{"数据库记录不存在", fmt.Errorf("find user: %w", sql.ErrNoRows)},
+		{"httpc连接失败", &url.Error{Op: "Get", URL: "http://10.0.0.5/users", Err: stderrors.New("connection refused")}},
+		{"httpc超时", &url.Error{Op: "Get", URL: "http://10.0.0.5/users", Err: context.DeadlineExceeded}},
+		{"上下文取消", fmt.Errorf("call user rpc: %w", context.Canceled)},
+		{"未知错误", stderrors.New("boom")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Convert(tc.err); got != nil {
+				t.Errorf("不应该识别该错误，实际: %+v", got.Status)
+			}
+		})
+	}
+
+	// 上下文错误交给 FromError 映射，不会被 *url.Error 等包装影响
+	if appErr := errors.FromError(&url.Error{Op: "Get", URL: "http://user", Err: context.DeadlineExceeded}); appErr.Reason != errors.DeadlineExceededReason {
+		t.Errorf("httpc超时应该按上下文超时处理，实际: %d/%s", appErr.Code, appErr.Reason)
+	}
+}
@@ -0,0 +1,50 @@
+package errors
+
+import (
+	"strconv"
+
+	"google.golang.org/grpc/codes"
+)
+
+// MetadataKeyGRPCCode is the metadata key under which FromError records the
+// original code of a gRPC status that carries no errors detail, e.g. "Aborted".
+// Several gRPC codes map to the same HTTP code, so GRPCCode and GRPCStatus use
+// it to re-emit the exact code the downstream returned.
+const MetadataKeyGRPCCode = "grpc_code"
+
+// GRPCCode returns the gRPC code of err: the original code recorded by
+// FromError under MetadataKeyGRPCCode if there is one, ToGRPCCode of its HTTP
+// status, see StatusCode, otherwise. It returns codes.OK for a nil error.
+func GRPCCode(err error) codes.Code {
+	se := FromError(err)
+	if se == nil {
+		return codes.OK
+	}
+	return se.GRPCCode()
+}
+
+// GRPCCode returns the gRPC code GRPCStatus sends for e: the original code
+// recorded under MetadataKeyGRPCCode if there is one, ToGRPCCode of its HTTP
+// status otherwise. Unlike GRPCStatus, it never generates an ID.
+func (e *Error) GRPCCode() codes.Code {
+	if c, ok := parseGRPCCode(e.Metadata[MetadataKeyGRPCCode]); ok {
+		return c
+	}
+	return ToGRPCCode(e.StatusCode())
+}
+
+// parseGRPCCode 解析状态码名称（如 "Aborted"），也接受数字形式
+func parseGRPCCode(s string) (codes.Code, bool) {
+	if s == "" {
+		return codes.OK, false
+	}
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		if c.String() == s {
+			return c, true
+		}
+	}
+	if n, err := strconv.ParseUint(s, 10, 32); err == nil {
+		return codes.Code(n), true
+	}
+	return codes.OK, false
+}
@@ -0,0 +1,53 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFromErrorPreservesGRPCCode(t *testing.T) {
+	downstream := status.Error(codes.Aborted, "transaction aborted")
+
+	err := FromError(fmt.Errorf("call downstream: %w", downstream))
+	if err.Code != 409 {
+		t.Fatalf("Aborted应该映射为409，实际: %d", err.Code)
+	}
+	if got := err.Metadata[MetadataKeyGRPCCode]; got != "Aborted" {
+		t.Errorf("应该在metadata中记录原始gRPC状态码，实际: %q", got)
+	}
+	if got := GRPCCode(err); got != codes.Aborted {
+		t.Errorf("GRPCCode应该返回原始状态码，实际: %v", got)
+	}
+	if got := status.Code(err.GRPCStatus().Err()); got != codes.Aborted {
+		t.Errorf("重新发出的状态码应该保持Aborted，实际: %v", got)
+	}
+
+	// 经过一次往返后仍然保留原始状态码
+	if got := GRPCCode(FromError(err.GRPCStatus().Err())); got != codes.Aborted {
+		t.Errorf("往返后应该保持Aborted，实际: %v", got)
+	}
+}
+
+func TestGRPCCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want codes.Code
+	}{
+		{"nil", nil, codes.OK},
+		{"mapped", Conflict("ALREADY_EXISTS", "已存在"), codes.Aborted},
+		{"recorded", New(409, "", "").WithMetadata(map[string]string{MetadataKeyGRPCCode: "AlreadyExists"}), codes.AlreadyExists},
+		{"numeric", New(409, "", "").WithMetadata(map[string]string{MetadataKeyGRPCCode: "6"}), codes.AlreadyExists},
+		{"invalid", New(404, "", "").WithMetadata(map[string]string{MetadataKeyGRPCCode: "bogus"}), codes.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GRPCCode(tt.err); got != tt.want {
+				t.Errorf("GRPCCode应该返回 %v，实际: %v", tt.want, got)
+			}
+		})
+	}
+}
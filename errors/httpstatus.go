@@ -0,0 +1,32 @@
+package errors
+
+// metadataKeyHTTPStatus gRPC metadata中传递与 Code 不同的HTTP状态码
+const metadataKeyHTTPStatus = "http_status"
+
+// WithHTTPStatus returns a copy of the error that is sent with the given HTTP
+// status code while Code, e.g. a business code such as 40401, stays in the
+// response body. The status is also used to derive the gRPC code and to
+// classify the error, e.g. by IsServerError, IsNotFound, IsRetryable and
+// Severity, and travels with GRPCStatus and MarshalJSON.
+func (e *Error) WithHTTPStatus(status int) *Error {
+	err := Clone(e)
+	err.HTTPStatus = status
+	return err
+}
+
+// statusCode 返回错误的HTTP状态码，nil 错误与 Code 一样返回200
+func statusCode(err error) int {
+	if err == nil {
+		return 200
+	}
+	return FromError(err).StatusCode()
+}
+
+// StatusCode returns the HTTP status code of the error: the one set with
+// WithHTTPStatus, Code otherwise. It makes *Error a StatusCoder.
+func (e *Error) StatusCode() int {
+	if e.HTTPStatus != 0 {
+		return e.HTTPStatus
+	}
+	return int(e.Code)
+}
@@ -0,0 +1,61 @@
+package errors
+
+import (
+	"encoding/json"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+)
+
+func TestHTTPStatusClassification(t *testing.T) {
+	notFound := New(40401, "USER_NOT_FOUND", "用户不存在").WithHTTPStatus(404)
+	if !notFound.IsClientError() || notFound.IsServerError() || !IsNotFound(notFound) {
+		t.Error("业务码40401、HTTP状态码404的错误应该按404归类为客户端错误")
+	}
+	if got := Severity(notFound); got != SeverityInfo {
+		t.Errorf("HTTP状态码为404的错误应该是 SeverityInfo，实际: %v", got)
+	}
+	if !IsTerminal(notFound) || IsRetryable(notFound) || notFound.Timeout() {
+		t.Error("HTTP状态码为404的错误应该是终止性的、不可重试的")
+	}
+
+	unavailable := New(50301, "DB_DOWN", "数据库不可用").WithHTTPStatus(503)
+	if !unavailable.IsServerError() || !IsRetryable(unavailable) || IsTerminal(unavailable) || Severity(unavailable) != SeverityError {
+		t.Error("业务码50301、HTTP状态码503的错误应该按503归类")
+	}
+	if timeout := New(50401, "UPSTREAM_TIMEOUT", "上游超时").WithHTTPStatus(504); !timeout.Timeout() || !IsGatewayTimeout(timeout) {
+		t.Error("HTTP状态码为504的错误应该是超时错误")
+	}
+}
+
+func TestWithHTTPStatus(t *testing.T) {
+	base := New(40401, "USER_NOT_FOUND", "用户不存在")
+	appErr := base.WithHTTPStatus(404)
+
+	if appErr.Code != 40401 || appErr.StatusCode() != 404 || appErr.ID != base.ID {
+		t.Errorf("应该保留业务码和ID并使用新的HTTP状态码，实际: code=%d status=%d", appErr.Code, appErr.StatusCode())
+	}
+	if base.StatusCode() != 40401 {
+		t.Errorf("未设置HTTP状态码时应该使用 Code，实际: %d", base.StatusCode())
+	}
+	if GRPCCode(appErr) != codes.NotFound {
+		t.Errorf("gRPC状态码应该由HTTP状态码决定，实际: %v", GRPCCode(appErr))
+	}
+
+	got := FromError(appErr.GRPCStatus().Err())
+	if got.Code != 40401 || got.HTTPStatus != 404 {
+		t.Errorf("HTTP状态码应该随gRPC状态传递，实际: %+v", got.Status)
+	}
+	if _, ok := got.Metadata[metadataKeyHTTPStatus]; ok {
+		t.Errorf("HTTP状态码不应该留在metadata中，实际: %v", got.Metadata)
+	}
+
+	data, err := json.Marshal(appErr)
+	if err != nil {
+		t.Fatalf("序列化失败: %v", err)
+	}
+	var decoded Error
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.StatusCode() != 404 || decoded.Code != 40401 {
+		t.Errorf("HTTP状态码应该随JSON传递，实际: %s %v", data, err)
+	}
+}
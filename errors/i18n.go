@@ -0,0 +1,89 @@
+package errors
+
+import (
+	"strings"
+	"sync"
+)
+
+var (
+	messagesMu sync.RWMutex
+	messages   = make(map[string]map[string]string) // 语言 -> 原因 -> 消息
+)
+
+// RegisterMessages registers translated messages for lang, keyed by reason.
+// Messages may reference metadata values with {key} placeholders, e.g.
+// "用户 {user_id} 不存在". Registering the same language again adds to, and
+// overrides, the messages registered before.
+func RegisterMessages(lang string, m map[string]string) {
+	lang = normalizeLang(lang)
+	messagesMu.Lock()
+	defer messagesMu.Unlock()
+	catalog := messages[lang]
+	if catalog == nil {
+		catalog = make(map[string]string, len(m))
+		messages[lang] = catalog
+	}
+	for reason, msg := range m {
+		catalog[reason] = msg
+	}
+}
+
+// HasMessages reports whether any messages have been registered.
+func HasMessages() bool {
+	messagesMu.RLock()
+	defer messagesMu.RUnlock()
+	return len(messages) > 0
+}
+
+// LookupMessage returns the message registered for reason in lang. Language
+// tags match case-insensitively, and a regional tag such as "zh-CN" falls back
+// to its base language "zh".
+func LookupMessage(reason, lang string) (string, bool) {
+	lang = normalizeLang(lang)
+	messagesMu.RLock()
+	defer messagesMu.RUnlock()
+	if msg, ok := messages[lang][reason]; ok {
+		return msg, true
+	}
+	if base, _, ok := strings.Cut(lang, "-"); ok {
+		if msg, ok := messages[base][reason]; ok {
+			return msg, true
+		}
+	}
+	return "", false
+}
+
+// Localize returns a copy of err whose Message is the message registered for
+// its reason in lang, with {key} placeholders replaced by metadata values.
+// When no message is registered, the error is returned with its original
+// message. It returns nil for a nil error.
+func Localize(err error, lang string) *Error {
+	se := FromError(err)
+	if se == nil {
+		return nil
+	}
+	msg, ok := LookupMessage(se.Reason, lang)
+	if !ok {
+		return se
+	}
+	localized := Clone(se)
+	localized.Message = interpolate(msg, se.RedactedMetadata())
+	return localized
+}
+
+// interpolate 将消息中的 {key} 替换为metadata中的值，未知的占位符保持不变
+func interpolate(msg string, metadata map[string]string) string {
+	if len(metadata) == 0 || !strings.Contains(msg, "{") {
+		return msg
+	}
+	pairs := make([]string, 0, 2*len(metadata))
+	for k, v := range metadata {
+		pairs = append(pairs, "{"+k+"}", v)
+	}
+	return strings.NewReplacer(pairs...).Replace(msg)
+}
+
+// normalizeLang 统一语言标签格式，例如 zh_CN 转换为 zh-cn
+func normalizeLang(lang string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"))
+}
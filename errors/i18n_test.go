@@ -0,0 +1,42 @@
+package errors
+
+import "testing"
+
+func TestLocalize(t *testing.T) {
+	RegisterMessages("zh", map[string]string{"I18N_USER_NOT_FOUND": "用户 {user_id} 不存在"})
+	RegisterMessages("en-GB", map[string]string{"I18N_USER_NOT_FOUND": "User {user_id} not found, {unknown}"})
+
+	err := NotFound("I18N_USER_NOT_FOUND", "user not found").WithMetadata(map[string]string{"user_id": "42"})
+	tests := []struct {
+		name string
+		lang string
+		want string
+	}{
+		{"精确匹配并插值", "zh", "用户 42 不存在"},
+		{"地区回退到基础语言", "zh-CN", "用户 42 不存在"},
+		{"大小写与下划线", "EN_gb", "User 42 not found, {unknown}"},
+		{"没有翻译时保留原消息", "fr", "user not found"},
+		{"基础语言不匹配地区", "en", "user not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Localize(err, tt.lang)
+			if got.Message != tt.want {
+				t.Errorf("本地化消息应该是 %q, 实际: %q", tt.want, got.Message)
+			}
+			if got.Reason != err.Reason || got.ID != err.ID {
+				t.Errorf("本地化不应该改变原因和ID, 实际: %v", got)
+			}
+		})
+	}
+
+	if err.Message != "user not found" {
+		t.Errorf("Localize不应该修改原错误, 实际: %q", err.Message)
+	}
+	if Localize(nil, "zh") != nil {
+		t.Error("nil错误应该返回nil")
+	}
+	if got := Localize(BadRequest("I18N_UNREGISTERED", "原始消息"), "zh"); got.Message != "原始消息" {
+		t.Errorf("未注册的原因应该保留原消息, 实际: %q", got.Message)
+	}
+}
@@ -0,0 +1,65 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JoinReason is the reason of errors created by Join.
+const JoinReason = "MULTIPLE_ERRORS"
+
+// Join returns an Error that aggregates errs, discarding nil values. It returns
+// nil if errs contains no non-nil errors.
+//
+// The code of the joined error is the highest code among the children, its
+// message summarizes the children's messages and its metadata records the
+// reason of the i-th child under the key "reason.<i>". The children are kept
+// as the cause of the joined error, so errors.Is and errors.As walk all of
+// them; use Errors to retrieve them.
+func Join(errs ...error) *Error {
+	var children []error
+	for _, err := range errs {
+		if err != nil {
+			children = append(children, err)
+		}
+	}
+	if len(children) == 0 {
+		return nil
+	}
+
+	var code int32
+	messages := make([]string, 0, len(children))
+	metadata := make(map[string]string, len(children))
+	for i, child := range children {
+		se := FromError(child)
+		if se.Code > code {
+			code = se.Code
+		}
+		messages = append(messages, se.Message)
+		metadata["reason."+strconv.Itoa(i)] = se.Reason
+	}
+
+	e := &Error{
+		Status: Status{
+			Code:     code,
+			Reason:   JoinReason,
+			Message:  fmt.Sprintf("%d errors: %s", len(children), strings.Join(messages, "; ")),
+			Metadata: metadata,
+		},
+		cause: stderrors.Join(children...),
+		stack: captureStack(2),
+	}
+	e.initID(2) // skip Join and the caller
+	return e
+}
+
+// Errors returns the children of an error created by Join, or of any error
+// whose cause implements Unwrap() []error. It returns nil otherwise.
+func (e *Error) Errors() []error {
+	if u, ok := e.cause.(interface{ Unwrap() []error }); ok {
+		return u.Unwrap()
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package errors
+
+import (
+	stderrors "errors"
+	"testing"
+)
+
+var errPlain = stderrors.New("disk full")
+
+func TestJoin(t *testing.T) {
+	emailErr := BadRequest("INVALID_EMAIL", "邮箱格式错误")
+	nameErr := NotFound("USER_NOT_FOUND", "用户不存在")
+
+	joined := Join(emailErr, nil, nameErr, errPlain)
+
+	if joined.Code != 500 {
+		t.Errorf("聚合错误的状态码应该是子错误中最高的, 实际: %d", joined.Code)
+	}
+	if joined.Reason != JoinReason {
+		t.Errorf("聚合错误的原因应该是 %s, 实际: %s", JoinReason, joined.Reason)
+	}
+	if want := "3 errors: 邮箱格式错误; 用户不存在; disk full"; joined.Message != want {
+		t.Errorf("消息应该汇总子错误\n期望: %s\n实际: %s", want, joined.Message)
+	}
+	wantMD := map[string]string{"reason.0": "INVALID_EMAIL", "reason.1": "USER_NOT_FOUND", "reason.2": UnknownReason}
+	for k, v := range wantMD {
+		if got, ok := joined.Metadata[k]; !ok || got != v {
+			t.Errorf("metadata[%s]应该是 %q, 实际: %q", k, v, got)
+		}
+	}
+
+	if children := joined.Errors(); len(children) != 3 {
+		t.Errorf("Errors应该返回3个子错误, 实际: %d", len(children))
+	}
+	if !stderrors.Is(joined, errPlain) {
+		t.Error("errors.Is应该能找到普通子错误")
+	}
+	if !stderrors.Is(joined, nameErr) {
+		t.Error("errors.Is应该能找到*Error子错误")
+	}
+	var target *Error
+	if !stderrors.As(joined.Unwrap(), &target) || target.Reason != "INVALID_EMAIL" {
+		t.Errorf("errors.As应该找到第一个*Error子错误, 实际: %v", target)
+	}
+}
+
+func TestJoinOnlyHighestClientCode(t *testing.T) {
+	joined := Join(BadRequest("A", "a"), Conflict("B", "b"))
+	if joined.Code != 409 {
+		t.Errorf("聚合错误的状态码应该是409, 实际: %d", joined.Code)
+	}
+}
+
+func TestJoinNil(t *testing.T) {
+	if Join() != nil || Join(nil, nil) != nil {
+		t.Error("没有非nil错误时Join应该返回nil")
+	}
+}
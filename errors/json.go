@@ -0,0 +1,71 @@
+package errors
+
+import (
+	"encoding/json"
+	stderrors "errors"
+)
+
+// jsonError is the JSON representation of an Error, including its cause chain.
+type jsonError struct {
+	Status
+	// Retryable 遮蔽Status中的同名字段，只有显式设置时才写出，保留显式的false
+	Retryable *bool      `json:"retryable,omitempty"`
+	Cause     *jsonError `json:"cause,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler. The Status fields are written as
+// usual and the cause chain is nested under "cause": causes that are *Error
+// keep all their fields, other causes are reduced to their message. Redacted
+// metadata keys are written as RedactedValue.
+func (e *Error) MarshalJSON() ([]byte, error) {
+	return json.Marshal(toJSONError(e))
+}
+
+// UnmarshalJSON implements json.Unmarshaler, reconstructing an Error, including
+// its ID, metadata and cause chain, from the output of MarshalJSON. Causes
+// that only carry a message are restored as plain errors.
+func (e *Error) UnmarshalJSON(data []byte) error {
+	var je jsonError
+	if err := json.Unmarshal(data, &je); err != nil {
+		return err
+	}
+	*e = *fromJSONError(&je)
+	return nil
+}
+
+// toJSONError 将错误及其cause链转换为JSON结构
+func toJSONError(e *Error) *jsonError {
+	je := &jsonError{Status: e.Status}
+	je.ID = e.id()
+	je.Metadata = e.RedactedMetadata()
+	je.Domain = e.GetDomain()
+	if e.retryableSet {
+		retryable := e.Retryable
+		je.Retryable = &retryable
+	}
+	if e.cause == nil {
+		return je
+	}
+	if se, ok := e.cause.(*Error); ok {
+		je.Cause = toJSONError(se)
+	} else {
+		je.Cause = &jsonError{Status: Status{Message: e.cause.Error()}}
+	}
+	return je
+}
+
+// fromJSONError 从JSON结构还原错误及其cause链
+func fromJSONError(je *jsonError) *Error {
+	e := &Error{Status: je.Status}
+	if je.Retryable != nil {
+		e.Retryable, e.retryableSet = *je.Retryable, true
+	}
+	if c := je.Cause; c != nil {
+		if c.Cause == nil && c.Code == 0 && c.Reason == "" && c.ID == "" && len(c.Metadata) == 0 {
+			e.cause = stderrors.New(c.Message)
+		} else {
+			e.cause = fromJSONError(c)
+		}
+	}
+	return e
+}
@@ -0,0 +1,77 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestErrorJSONRoundTrip(t *testing.T) {
+	err := NotFound("USER_NOT_FOUND", "用户不存在").
+		WithMetadata(map[string]string{"user_id": "42"}).
+		WithCause(InternalServer("DB_ERROR", "数据库错误").WithCause(fmt.Errorf("connection refused")))
+
+	data, marshalErr := json.Marshal(err)
+	if marshalErr != nil {
+		t.Fatalf("序列化失败: %v", marshalErr)
+	}
+
+	var decoded Error
+	if unmarshalErr := json.Unmarshal(data, &decoded); unmarshalErr != nil {
+		t.Fatalf("反序列化失败: %v", unmarshalErr)
+	}
+
+	if decoded.ID != err.ID || decoded.Code != 404 || decoded.Reason != "USER_NOT_FOUND" || decoded.Metadata["user_id"] != "42" {
+		t.Errorf("顶层字段应该保留，实际: %+v", decoded.Status)
+	}
+
+	dbErr, ok := decoded.Unwrap().(*Error)
+	if !ok {
+		t.Fatalf("*Error类型的cause应该还原为*Error，实际: %T", decoded.Unwrap())
+	}
+	if dbErr.Reason != "DB_ERROR" || dbErr.ID != err.Unwrap().(*Error).ID {
+		t.Errorf("cause的字段应该保留，实际: %+v", dbErr.Status)
+	}
+	if root := dbErr.Unwrap(); root == nil || root.Error() != "connection refused" {
+		t.Errorf("普通cause应该还原为只包含message的错误，实际: %v", root)
+	}
+}
+
+func TestErrorJSONOmitsEmptyFields(t *testing.T) {
+	err := &Error{Status: Status{Code: 400, Reason: "BAD", Message: "无效请求", ID: "id-1"}}
+
+	data, marshalErr := json.Marshal(err)
+	if marshalErr != nil {
+		t.Fatalf("序列化失败: %v", marshalErr)
+	}
+	if want := `{"code":400,"reason":"BAD","message":"无效请求","id":"id-1"}`; string(data) != want {
+		t.Errorf("没有cause和metadata时不应该输出多余的字段\n期望: %s\n实际: %s", want, data)
+	}
+}
+
+func TestErrorJSONKeepsExplicitRetryable(t *testing.T) {
+	for _, retryable := range []bool{false, true} {
+		err := ServiceUnavailable("MAINTENANCE", "维护中").WithRetryable(retryable)
+
+		data, marshalErr := json.Marshal(err)
+		if marshalErr != nil {
+			t.Fatalf("序列化失败: %v", marshalErr)
+		}
+		var decoded Error
+		if unmarshalErr := json.Unmarshal(data, &decoded); unmarshalErr != nil {
+			t.Fatalf("反序列化失败: %v", unmarshalErr)
+		}
+		if got := IsRetryable(&decoded); got != retryable {
+			t.Errorf("显式设置的retryable=%v应该在JSON往返后保留，实际: %v (%s)", retryable, got, data)
+		}
+	}
+
+	data, _ := json.Marshal(ServiceUnavailable("MAINTENANCE", "维护中"))
+	var decoded Error
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("反序列化失败: %v", err)
+	}
+	if !IsRetryable(&decoded) || decoded.retryableSet {
+		t.Errorf("未显式设置时应该仍按状态码判断，实际: %s", data)
+	}
+}
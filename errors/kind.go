@@ -0,0 +1,41 @@
+package errors
+
+// Kind identifies a class of errors independently of their code and reason.
+// Kinds are compared by identity, so two kinds created with the same name are
+// still different; declare each kind once as a package-level variable:
+//
+//	var KindUserNotFound = errors.NewKind("user_not_found")
+//
+//	var ErrUserNotFound = errors.NotFound("USER_NOT_FOUND", "user not found").
+//		WithKind(KindUserNotFound)
+type Kind struct {
+	name string
+}
+
+// NewKind returns a new, distinct Kind. name is only used by String.
+func NewKind(name string) *Kind {
+	return &Kind{name: name}
+}
+
+// String returns the name the kind was created with.
+func (k *Kind) String() string {
+	if k == nil {
+		return ""
+	}
+	return k.name
+}
+
+// WithKind returns a copy of the error with kind k. When both errors compared
+// by Is have a kind, they match if and only if their kinds are the same,
+// regardless of code and reason. Kinds are not transmitted over gRPC or JSON,
+// so errors converted by FromError are matched by code and reason.
+func (e *Error) WithKind(k *Kind) *Error {
+	err := Clone(e)
+	err.kind = k
+	return err
+}
+
+// Kind returns the kind set with WithKind, or nil.
+func (e *Error) Kind() *Kind {
+	return e.kind
+}
@@ -0,0 +1,52 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"testing"
+)
+
+var (
+	kindUserNotFound  = NewKind("user_not_found")
+	kindOrderNotFound = NewKind("order_not_found")
+)
+
+func TestIsWithKind(t *testing.T) {
+	errUserNotFound := NotFound("NOT_FOUND", "用户不存在").WithKind(kindUserNotFound)
+	errOrderNotFound := NotFound("NOT_FOUND", "订单不存在").WithKind(kindOrderNotFound)
+
+	tests := []struct {
+		name   string
+		err    error
+		target error
+		want   bool
+	}{
+		{"同一类别", fmt.Errorf("wrap: %w", NotFound("USER_MISSING", "拼写不同").WithKind(kindUserNotFound)), errUserNotFound, true},
+		{"不同类别相同code和reason", errOrderNotFound, errUserNotFound, false},
+		{"只有目标有类别时比较code和reason", NotFound("NOT_FOUND", "无类别"), errUserNotFound, true},
+		{"只有错误有类别时比较code和reason", errUserNotFound, NotFound("NOT_FOUND", "无类别"), true},
+		{"都没有类别时比较code和reason", NotFound("NOT_FOUND", "a"), NotFound("NOT_FOUND", "b"), true},
+		{"都没有类别且reason不同", NotFound("NOT_FOUND", "a"), NotFound("GONE", "b"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stderrors.Is(tt.err, tt.target); got != tt.want {
+				t.Errorf("errors.Is 应该返回 %v，实际: %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestKindSurvivesCopies(t *testing.T) {
+	err := BadRequest("INVALID", "参数错误").WithKind(kindUserNotFound)
+	copied := err.WithMetadataKV("field", "name").WithID("custom")
+	if copied.Kind() != kindUserNotFound {
+		t.Errorf("With* 方法应该保留类别，实际: %v", copied.Kind())
+	}
+	if kindUserNotFound.String() != "user_not_found" || (*Kind)(nil).String() != "" {
+		t.Errorf("String 应该返回类别名称，实际: %q", kindUserNotFound.String())
+	}
+	if NewKind("user_not_found") == kindUserNotFound {
+		t.Error("同名的类别应该互不相同")
+	}
+}
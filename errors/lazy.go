@@ -0,0 +1,83 @@
+package errors
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// lazyIDs 为 true 时默认生成器的ID延迟到第一次读取时生成
+var lazyIDs atomic.Bool
+
+// SetLazyIDGeneration makes New, Newf, Errorf, Wrap, Wrapf, Join,
+// CloneWithNewID, NewFromTemplate and Definition.New defer building the error
+// ID until it is first needed. At creation only the caller program counter
+// and the timestamp are recorded; GetID, GRPCStatus, ToProto, Error,
+// MarshalJSON, LogValue, the %v verbs, AuditRecord and FromError build the
+// full ID from them, so the call site and time are the same as with eager
+// generation. Errors that are created and then discarded, e.g. after an
+// errors.Is check, never pay for the ID.
+//
+// Lazy IDs always carry goroutine ID 0, because the goroutine reading the ID
+// is not necessarily the one that created the error. Lazy generation only
+// applies to the default generator with FullProfile: with SetIDGenerator or
+// CompactProfile, IDs are still generated at creation. Copies made by Clone and the With* methods before
+// the first read share the pending ID, so they report the same ID as the
+// original. The ID field itself stays empty until an ID is set explicitly, so
+// that reading the ID of an error shared between goroutines is not a data
+// race; read it with GetID or ID instead. It is disabled by default.
+func SetLazyIDGeneration(enabled bool) {
+	lazyIDs.Store(enabled)
+}
+
+// lazyID 延迟生成ID所需的创建现场，由错误及其副本共享以保证ID一致
+type lazyID struct {
+	pcs       [callerSearchDepth]uintptr // 创建错误的调用栈，生成时从中查找包外的调用者
+	n         int
+	timestamp int64 // 创建时间，Unix纳秒
+
+	once sync.Once
+	id   string
+}
+
+// initID 为新建的错误设置ID，skip=1 表示 initID 的调用者
+func (e *Error) initID(skip int) {
+	// 精简ID不包含调用位置，延迟生成没有收益，也避免延迟路径绕过 CompactProfile
+	if !lazyIDs.Load() || idGenerator.Load() != nil || !IDGenerationEnabled() || IDProfile(idProfile.Load()) == CompactProfile {
+		e.ID = generateErrorID(skip + 1)
+		return
+	}
+	l := &lazyID{timestamp: idNow().UnixNano()}
+	if l.n = runtime.Callers(skip+1, l.pcs[:]); l.n == 0 {
+		e.ID = generateErrorID(skip + 1)
+		return
+	}
+	e.lazy = l
+}
+
+// id 返回错误ID，延迟的ID在第一次读取时根据记录的创建现场生成。
+// 只读取不修改 e，共享的错误可以被多个协程同时读取
+func (e *Error) id() string {
+	if e.ID != "" || e.lazy == nil {
+		return e.ID
+	}
+	return e.lazy.get()
+}
+
+// get 返回延迟生成的ID，只生成一次
+func (l *lazyID) get() string {
+	l.once.Do(func() { l.id = l.generate() })
+	return l.id
+}
+
+// generate 使用记录的调用位置和时间生成ID，失败时返回备用ID
+func (l *lazyID) generate() (result string) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = generateFallbackErrorID()
+		}
+	}()
+
+	funcName, filename, line := callerSite(l.pcs[:l.n])
+	return formatErrorID(funcName, filename, line, l.timestamp, 0)
+}
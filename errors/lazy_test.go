@@ -0,0 +1,165 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLazyIDGeneration(t *testing.T) {
+	SetLazyIDGeneration(true)
+	t.Cleanup(func() { SetLazyIDGeneration(false) })
+
+	created := time.Now()
+	err := New(404, "USER_NOT_FOUND", "用户不存在")
+	if err.ID != "" {
+		t.Fatalf("延迟模式下创建时不应该生成ID，实际: %s", err.ID)
+	}
+
+	clone := err.WithMetadata(map[string]string{"user_id": "42"})
+	id := err.GetID()
+	if id == "" {
+		t.Fatal("GetID 应该生成ID")
+	}
+	if clone.GetID() != id {
+		t.Errorf("副本应该与原错误共享ID，实际: %s != %s", clone.GetID(), id)
+	}
+
+	info, decodeErr := DecodeErrorID(id)
+	if decodeErr != nil {
+		t.Fatalf("延迟生成的ID应该可以解码: %v", decodeErr)
+	}
+	if info.Function != "TestLazyIDGeneration" || info.File != "lazy_test.go" {
+		t.Errorf("ID应该指向创建错误的位置，实际: %s@%s:%d", info.Function, info.File, info.Line)
+	}
+	if ts := time.Unix(0, info.Timestamp); ts.Before(created) || ts.After(time.Now()) {
+		t.Errorf("ID应该记录创建时间，实际: %v", ts)
+	}
+	if info.GoroutineID != 0 {
+		t.Errorf("延迟生成的ID不应该记录goroutine ID，实际: %d", info.GoroutineID)
+	}
+}
+
+func TestLazyIDGenerationAccessors(t *testing.T) {
+	SetLazyIDGeneration(true)
+	t.Cleanup(func() { SetLazyIDGeneration(false) })
+
+	tests := []struct {
+		name string
+		read func(*Error) string // 返回访问方法输出的错误ID
+	}{
+		{"GRPCStatus", func(e *Error) string { return FromError(e.GRPCStatus().Err()).ID }},
+		{"Error", func(e *Error) string { return strings.Fields(e.Error())[3] }},
+		{"MarshalJSON", func(e *Error) string {
+			data, _ := e.MarshalJSON()
+			var decoded struct{ ID string }
+			_ = json.Unmarshal(data, &decoded)
+			return decoded.ID
+		}},
+		{"FromError", func(e *Error) string { return FromError(e).GetID() }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Newf(500, "INTERNAL", "内部错误 %d", 1)
+			id := tt.read(err)
+			info, decodeErr := DecodeErrorID(id)
+			if decodeErr != nil {
+				t.Fatalf("%s 应该生成可解码的ID: %v", tt.name, decodeErr)
+			}
+			if info.File != "lazy_test.go" {
+				t.Errorf("ID应该指向创建错误的位置，实际: %s@%s:%d", info.Function, info.File, info.Line)
+			}
+			if id != err.GetID() {
+				t.Errorf("%s 输出的ID应该与 GetID 一致，实际: %s != %s", tt.name, id, err.GetID())
+			}
+			if err.ID != "" {
+				t.Errorf("读取延迟ID不应该修改错误，实际: %s", err.ID)
+			}
+		})
+	}
+}
+
+func TestLazyIDConcurrentReads(t *testing.T) {
+	SetLazyIDGeneration(true)
+	t.Cleanup(func() { SetLazyIDGeneration(false) })
+
+	// 包级别共享的错误会被多个协程同时读取，需配合 -race 运行
+	shared := New(404, "USER_NOT_FOUND", "用户不存在")
+	const readers = 8
+	ids := make([]string, readers)
+	var wg sync.WaitGroup
+	for i := range readers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = shared.Error()
+			_ = shared.Logfmt()
+			_ = fmt.Sprintf("%+v", shared)
+			_, _ = shared.MarshalJSON()
+			ids[i] = shared.GetID()
+		}()
+	}
+	wg.Wait()
+
+	for i, id := range ids {
+		if id == "" || id != ids[0] {
+			t.Fatalf("第 %d 个协程读取的ID不一致: %q != %q", i, id, ids[0])
+		}
+	}
+}
+
+func TestLazyIDGenerationWithCustomGenerator(t *testing.T) {
+	SetLazyIDGeneration(true)
+	SetIDGenerator(IDGeneratorFunc(func(int) string { return "custom-id" }))
+	t.Cleanup(func() {
+		SetLazyIDGeneration(false)
+		SetIDGenerator(nil)
+	})
+
+	if err := New(500, "CUSTOM", "自定义生成器"); err.ID != "custom-id" {
+		t.Errorf("自定义生成器应该在创建时生成ID，实际: %q", err.ID)
+	}
+}
+
+func TestLazyIDGenerationWithCompactProfile(t *testing.T) {
+	SetLazyIDGeneration(true)
+	SetIDProfile(CompactProfile)
+	t.Cleanup(func() {
+		SetLazyIDGeneration(false)
+		SetIDProfile(FullProfile)
+	})
+
+	err := New(404, "USER_NOT_FOUND", "用户不存在")
+	info, decodeErr := DecodeErrorID(err.GetID())
+	if decodeErr != nil {
+		t.Fatalf("精简ID应该可以解码: %v", decodeErr)
+	}
+	if !info.IsCompact || info.Function != "" || info.File != "" || info.ProcessID != 0 {
+		t.Errorf("延迟模式下也应该生成不含调用位置的精简ID，实际: %+v", info)
+	}
+}
+
+func BenchmarkNewLazyIDUnread(b *testing.B) {
+	SetLazyIDGeneration(true)
+	defer SetLazyIDGeneration(false)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchErrSink = New(400, "BENCH", "基准测试错误")
+	}
+}
+
+func BenchmarkNewLazyIDRead(b *testing.B) {
+	SetLazyIDGeneration(true)
+	defer SetLazyIDGeneration(false)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		err := New(400, "BENCH", "基准测试错误")
+		_ = err.GetID()
+		benchErrSink = err
+	}
+}
@@ -0,0 +1,41 @@
+package errors
+
+import "sync"
+
+// errorPool 复用短生命周期的 Error 对象
+var errorPool = sync.Pool{
+	New: func() any { return new(Error) },
+}
+
+// AcquireError returns an Error from an internal pool, initialised exactly like
+// New(code, reason, message), including a freshly generated ID.
+//
+// It is intended for extremely hot paths where an error is created, converted
+// and discarded within the same request. Pair every AcquireError with a single
+// ReleaseError once the error is no longer referenced. Never release an error
+// that was returned to a caller, stored, logged asynchronously or wrapped by
+// another error: it may be reused and overwritten at any time afterwards.
+// When in doubt, use New.
+func AcquireError(code int, reason, message string) *Error {
+	e := errorPool.Get().(*Error)
+	e.Code = int32(code)
+	e.Reason = reason
+	e.Message = message
+	e.ID = generateErrorID(2) // skip AcquireError and the caller
+	e.stack = captureStack(2)
+	e.pooled = true
+	return e
+}
+
+// ReleaseError resets e and returns it to the pool used by AcquireError.
+// e must not be used after calling ReleaseError. Only errors obtained from
+// AcquireError are pooled: errors created with New, copies made by Clone and
+// the With* methods, and errors that were already released are left
+// untouched, so releasing them is a no-op, as is releasing nil.
+func ReleaseError(e *Error) {
+	if e == nil || !e.pooled {
+		return
+	}
+	*e = Error{}
+	errorPool.Put(e)
+}
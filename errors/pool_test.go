@@ -0,0 +1,90 @@
+package errors
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestAcquireReleaseError(t *testing.T) {
+	err := AcquireError(404, "NOT_FOUND", "资源未找到")
+	if err.Code != 404 || err.Reason != "NOT_FOUND" || err.Message != "资源未找到" {
+		t.Errorf("AcquireError字段设置不正确: %+v", err.Status)
+	}
+
+	info, decodeErr := DecodeErrorID(err.ID)
+	if decodeErr != nil {
+		t.Fatalf("AcquireError应该生成可解码的错误ID: %v", decodeErr)
+	}
+	if info.Function != "TestAcquireReleaseError" {
+		t.Errorf("错误ID应该指向调用者，实际: %s", info.Function)
+	}
+
+	err.Metadata = map[string]string{"k": "v"}
+	ReleaseError(err)
+	if err.ID != "" || err.Metadata != nil || err.Unwrap() != nil {
+		t.Errorf("ReleaseError应该重置错误，实际: %+v", err.Status)
+	}
+
+	// 释放nil是安全的
+	ReleaseError(nil)
+}
+
+func TestReleaseErrorIgnoresUnpooled(t *testing.T) {
+	sentinel := New(404, "NOT_FOUND", "资源未找到")
+	ReleaseError(sentinel)
+	if sentinel.ID == "" || sentinel.Reason != "NOT_FOUND" {
+		t.Errorf("New创建的错误不应该被释放，实际: %+v", sentinel.Status)
+	}
+
+	pooled := AcquireError(404, "NOT_FOUND", "资源未找到")
+	clone := pooled.WithMetadata(map[string]string{"k": "v"})
+	ReleaseError(clone)
+	if clone.ID == "" || clone.Metadata["k"] != "v" {
+		t.Errorf("With*返回的副本不属于对象池，不应该被释放，实际: %+v", clone.Status)
+	}
+
+	ReleaseError(pooled)
+	if pooled.pooled {
+		t.Error("释放后的错误不应该再标记为来自对象池，重复释放应该被忽略")
+	}
+}
+
+func TestAcquireReleaseConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				reason := fmt.Sprintf("R_%d_%d", g, i)
+				err := AcquireError(400, reason, "并发")
+				if err.Reason != reason || err.Metadata != nil {
+					t.Errorf("从对象池获取的错误应该是干净的，实际: %+v", err.Status)
+				}
+				_ = err.GRPCStatus()
+				ReleaseError(err)
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+// benchErrSink 让基准测试中的错误逃逸到堆上，模拟错误经由接口返回的场景
+var benchErrSink error
+
+func BenchmarkNewError(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchErrSink = New(400, "BENCH", "基准测试错误")
+	}
+}
+
+func BenchmarkAcquireReleaseError(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		err := AcquireError(400, "BENCH", "基准测试错误")
+		benchErrSink = err
+		ReleaseError(err)
+	}
+}
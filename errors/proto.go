@@ -0,0 +1,32 @@
+package errors
+
+import (
+	"google.golang.org/protobuf/proto"
+
+	errorspb "github.com/honeybbq/go-zero-errors-proto/errors"
+)
+
+// ToProto returns the errorspb.Status that GRPCStatus attaches as the first
+// detail, so that the error can be transmitted through channels other than
+// gRPC, such as Kafka or NATS. As with GRPCStatus, an ID is generated if the
+// error has none and is carried in the metadata together with the explicit
+// Retryable and Severity values; redacted metadata keys are scrubbed. The
+// cause chain is not included.
+func (e *Error) ToProto() *errorspb.Status {
+	if e.id() == "" {
+		e.ID = generateErrorID(2) // skip ToProto and the caller
+	}
+	return e.statusDetail()
+}
+
+// FromProto converts a status produced by ToProto back into an *Error,
+// restoring the ID, Retryable and Severity from its metadata. s is not
+// modified. It returns nil if s is nil.
+func FromProto(s *errorspb.Status) *Error {
+	if s == nil {
+		return nil
+	}
+	ret := &Error{}
+	applyStatusDetail(ret, proto.Clone(s).(*errorspb.Status))
+	return ret
+}
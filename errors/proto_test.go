@@ -0,0 +1,52 @@
+package errors
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	errorspb "github.com/honeybbq/go-zero-errors-proto/errors"
+)
+
+func TestProtoRoundTrip(t *testing.T) {
+	origin := TooManyRequests("RATE_LIMITED", "请求过于频繁").
+		WithMetadata(map[string]string{"limit": "100"}).
+		WithRetryable(false).
+		WithSeverity(SeverityCritical)
+
+	pb := origin.ToProto()
+	if pb.Metadata["error_id"] != origin.ID {
+		t.Errorf("ToProto 应该与 GRPCStatus 一样在metadata中携带错误ID，实际: %v", pb.Metadata)
+	}
+
+	data, err := proto.Marshal(pb)
+	if err != nil {
+		t.Fatalf("序列化失败: %v", err)
+	}
+	received := new(errorspb.Status)
+	if err := proto.Unmarshal(data, received); err != nil {
+		t.Fatalf("反序列化失败: %v", err)
+	}
+
+	got := FromProto(received)
+	if !reflect.DeepEqual(got.Status, origin.Status) {
+		t.Errorf("往返后应该保留所有字段，期望: %+v，实际: %+v", origin.Status, got.Status)
+	}
+	if IsRetryable(got) != IsRetryable(origin) {
+		t.Error("往返后应该保留显式设置的 Retryable")
+	}
+	if received.Metadata["error_id"] != origin.ID {
+		t.Error("FromProto 不应该修改传入的 Status")
+	}
+	if FromProto(nil) != nil {
+		t.Error("FromProto(nil) 应该返回nil")
+	}
+}
+
+func TestToProtoGeneratesID(t *testing.T) {
+	e := &Error{Status: Status{Code: 500, Reason: "NO_ID"}}
+	if pb := e.ToProto(); e.ID == "" || pb.Metadata["error_id"] != e.ID {
+		t.Errorf("没有ID时 ToProto 应该生成ID，实际: %q %v", e.ID, pb.Metadata)
+	}
+}
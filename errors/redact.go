@@ -0,0 +1,62 @@
+package errors
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// RedactedValue replaces the values of redacted metadata keys.
+const RedactedValue = "***"
+
+// redactedKeys 需要脱敏的metadata键，统一为小写
+var redactedKeys atomic.Pointer[map[string]struct{}]
+
+// SetRedactedMetadataKeys sets the metadata keys, such as "authorization" or
+// "password", whose values are replaced with RedactedValue whenever an Error
+// is serialized or formatted: in GRPCStatus, MarshalJSON, Error, LogValue and
+// the interceptor responses. Keys match case-insensitively. The in-memory
+// Metadata is left intact. Calling it again replaces the previous keys;
+// calling it without keys disables redaction.
+func SetRedactedMetadataKeys(keys ...string) {
+	if len(keys) == 0 {
+		redactedKeys.Store(nil)
+		return
+	}
+	set := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		set[strings.ToLower(k)] = struct{}{}
+	}
+	redactedKeys.Store(&set)
+}
+
+// RedactedMetadata returns the metadata of e with the values of redacted keys
+// replaced by RedactedValue. It returns e.Metadata itself when nothing needs
+// to be redacted, so the result must not be modified.
+func (e *Error) RedactedMetadata() map[string]string {
+	return redactMetadata(e.Metadata)
+}
+
+// redactMetadata 返回脱敏后的metadata副本，没有需要脱敏的键时返回原map
+func redactMetadata(md map[string]string) map[string]string {
+	keys := redactedKeys.Load()
+	if keys == nil || len(md) == 0 {
+		return md
+	}
+	var redacted map[string]string
+	for k := range md {
+		if _, ok := (*keys)[strings.ToLower(k)]; !ok {
+			continue
+		}
+		if redacted == nil {
+			redacted = make(map[string]string, len(md))
+			for k, v := range md {
+				redacted[k] = v
+			}
+		}
+		redacted[k] = RedactedValue
+	}
+	if redacted == nil {
+		return md
+	}
+	return redacted
+}
@@ -0,0 +1,66 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRedactedMetadata(t *testing.T) {
+	SetRedactedMetadataKeys("Authorization", "password")
+	defer SetRedactedMetadataKeys()
+
+	err := BadRequest("LOGIN_FAILED", "登录失败").WithMetadata(map[string]string{
+		"authorization": "Bearer secret-token",
+		"PASSWORD":      "hunter2",
+		"user":          "alice",
+	})
+
+	// gRPC
+	converted := FromError(err.GRPCStatus().Err())
+	if converted.Metadata["authorization"] != RedactedValue || converted.Metadata["PASSWORD"] != RedactedValue {
+		t.Errorf("gRPC状态中的敏感metadata应该被脱敏，实际: %v", converted.Metadata)
+	}
+	if converted.Metadata["user"] != "alice" {
+		t.Errorf("非敏感metadata应该保留，实际: %v", converted.Metadata)
+	}
+
+	// JSON
+	data, jerr := json.Marshal(err)
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+	// Error() 和 slog
+	var logged strings.Builder
+	slog.New(slog.NewTextHandler(&logged, nil)).Info("failed", "err", err)
+	outputs := map[string]string{
+		"JSON":    string(data),
+		"Error()": err.Error(),
+		"slog":    logged.String(),
+		"Logfmt":  err.Logfmt(),
+		"%+v":     fmt.Sprintf("%+v", err),
+	}
+	for name, out := range outputs {
+		if strings.Contains(out, "secret-token") || strings.Contains(out, "hunter2") {
+			t.Errorf("%s 输出不应该包含敏感值，实际: %s", name, out)
+		}
+		if !strings.Contains(out, "alice") {
+			t.Errorf("%s 输出应该保留非敏感值，实际: %s", name, out)
+		}
+	}
+
+	// 内存中的错误保持不变
+	if err.Metadata["authorization"] != "Bearer secret-token" || err.Metadata["PASSWORD"] != "hunter2" {
+		t.Errorf("内存中的metadata不应该被修改，实际: %v", err.Metadata)
+	}
+}
+
+func TestRedactedMetadataDisabled(t *testing.T) {
+	md := map[string]string{"password": "hunter2"}
+	err := BadRequest("LOGIN_FAILED", "登录失败").WithMetadata(md)
+	if got := err.RedactedMetadata(); got["password"] != "hunter2" {
+		t.Errorf("未设置脱敏键时应该返回原始metadata，实际: %v", got)
+	}
+}
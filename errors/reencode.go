@@ -0,0 +1,30 @@
+package errors
+
+import "fmt"
+
+// ReencodeErrorID migrates an error ID from one encoding to another, for
+// example when rotating the key of a keyed transform. oldUntransform turns
+// the old ID back into the raw payload and newTransform encodes the payload
+// with the new scheme. A nil oldUntransform accepts any base64 encoding and a
+// nil newTransform uses the encoding set with SetIDEncoding.
+// The payload must be a valid error ID, so IDs that were not decoded with the
+// right scheme are rejected instead of being silently re-encoded.
+func ReencodeErrorID(old string, oldUntransform func([]byte) ([]byte, error), newTransform func([]byte) string) (string, error) {
+	if oldUntransform == nil {
+		oldUntransform = func(b []byte) ([]byte, error) {
+			return decodeID(string(b))
+		}
+	}
+	if newTransform == nil {
+		newTransform = encodeID
+	}
+
+	raw, err := oldUntransform([]byte(old))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode error ID: %w", err)
+	}
+	if _, err := decodeRawErrorID(string(raw)); err != nil {
+		return "", err
+	}
+	return newTransform(raw), nil
+}
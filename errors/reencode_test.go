@@ -0,0 +1,62 @@
+package errors
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// xorTransform 用于测试的简单可逆变换：按key异或后十六进制编码
+type xorTransform byte
+
+func (k xorTransform) transform(b []byte) string {
+	out := make([]byte, len(b))
+	for i, c := range b {
+		out[i] = c ^ byte(k)
+	}
+	return hex.EncodeToString(out)
+}
+
+func (k xorTransform) untransform(b []byte) ([]byte, error) {
+	out, err := hex.DecodeString(string(b))
+	if err != nil {
+		return nil, err
+	}
+	for i := range out {
+		out[i] ^= byte(k)
+	}
+	return out, nil
+}
+
+func TestReencodeErrorID(t *testing.T) {
+	original := New(500, "INTERNAL", "内部错误").ID
+	oldKey, newKey := xorTransform(0x5a), xorTransform(0x3c)
+
+	// 默认编码 -> 旧key
+	oldID, err := ReencodeErrorID(original, nil, oldKey.transform)
+	if err != nil {
+		t.Fatalf("重新编码失败: %v", err)
+	}
+
+	// 旧key -> 新key
+	newID, err := ReencodeErrorID(oldID, oldKey.untransform, newKey.transform)
+	if err != nil {
+		t.Fatalf("密钥轮换失败: %v", err)
+	}
+	if newID == oldID {
+		t.Error("轮换后的ID应该与旧ID不同")
+	}
+
+	// 新key -> 默认编码，应该还原为原始ID
+	back, err := ReencodeErrorID(newID, newKey.untransform, nil)
+	if err != nil {
+		t.Fatalf("还原默认编码失败: %v", err)
+	}
+	if back != original {
+		t.Errorf("轮换后应该能还原原始ID\n期望: %s\n实际: %s", original, back)
+	}
+
+	// 使用错误的旧key解码应该失败
+	if _, err := ReencodeErrorID(newID, oldKey.untransform, nil); err == nil {
+		t.Error("使用错误的key解码时应该返回错误")
+	}
+}
@@ -0,0 +1,67 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxResponseBody FromHTTPResponse 最多读取的响应体字节数
+const maxResponseBody = 1 << 20
+
+// maxResponseMessage 由非JSON响应体生成消息时保留的最大字节数
+const maxResponseMessage = 256
+
+// FromHTTPResponse reconstructs the Error described by the body of an error
+// response, so that a gateway can propagate a downstream error as is. Bodies
+// in the shape written by the interceptor package and by MarshalJSON, with
+// code, reason, message, metadata and id members, keep all these fields,
+// including the original ID; a missing code is taken from the status code.
+// Other bodies, such as the plain text of a proxy error page, become an error
+// with the status code of the response, UnknownReason and the body, or the
+// status text when it is empty, as message.
+//
+// At most 1 MiB of the body is read; closing it remains the caller's
+// responsibility. The error result is only set when resp is nil or reading
+// the body fails.
+func FromHTTPResponse(resp *http.Response) (*Error, error) {
+	if resp == nil {
+		return nil, fmt.Errorf("nil http response")
+	}
+	var body []byte
+	if resp.Body != nil {
+		var err error
+		if body, err = io.ReadAll(io.LimitReader(resp.Body, maxResponseBody)); err != nil {
+			return nil, fmt.Errorf("read http response body: %w", err)
+		}
+	}
+
+	var je jsonError
+	if err := json.Unmarshal(body, &je); err == nil && (je.Code != 0 || je.Reason != "") {
+		e := fromJSONError(&je)
+		if e.Code == 0 {
+			e.Code = int32(resp.StatusCode)
+		}
+		return e, nil
+	}
+	return New(resp.StatusCode, UnknownReason, responseMessage(resp.StatusCode, body)), nil
+}
+
+// responseMessage 使用截断后的响应体作为消息，响应体为空时使用状态码的描述
+func responseMessage(code int, body []byte) string {
+	msg := strings.TrimSpace(string(body))
+	if len(msg) > maxResponseMessage {
+		msg = msg[:maxResponseMessage]
+		// 避免截断在多字节字符中间
+		for !utf8.ValidString(msg) {
+			msg = msg[:len(msg)-1]
+		}
+	}
+	if msg == "" {
+		msg = http.StatusText(code)
+	}
+	return msg
+}
@@ -0,0 +1,61 @@
+package errors
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// newResponse 创建带有指定状态码和响应体的 http.Response
+func newResponse(code int, body string) *http.Response {
+	return &http.Response{StatusCode: code, Body: io.NopCloser(strings.NewReader(body))}
+}
+
+func TestFromHTTPResponse(t *testing.T) {
+	original := NotFound("USER_NOT_FOUND", "用户不存在").WithMetadataKV("user_id", "42")
+	body, err := original.MarshalJSON()
+	if err != nil {
+		t.Fatalf("序列化错误失败: %v", err)
+	}
+
+	got, err := FromHTTPResponse(newResponse(http.StatusNotFound, string(body)))
+	if err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+	if got.ID != original.ID || got.Code != 404 || got.Reason != "USER_NOT_FOUND" || got.Message != "用户不存在" {
+		t.Errorf("应该还原原始错误及其ID，实际: %+v", got.Status)
+	}
+	if got.Metadata["user_id"] != "42" {
+		t.Errorf("应该还原metadata，实际: %v", got.Metadata)
+	}
+
+	// 缺少 code 时使用响应的状态码
+	got, _ = FromHTTPResponse(newResponse(http.StatusConflict, `{"reason":"ORDER_LOCKED","message":"订单已锁定","id":"abc"}`))
+	if got.Code != 409 || got.Reason != "ORDER_LOCKED" || got.ID != "abc" {
+		t.Errorf("缺少code时应该使用状态码，实际: %+v", got.Status)
+	}
+}
+
+func TestFromHTTPResponsePlainText(t *testing.T) {
+	got, err := FromHTTPResponse(newResponse(http.StatusBadGateway, "<html>502 Bad Gateway</html>\n"))
+	if err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+	if got.Code != 502 || got.Reason != UnknownReason || got.Message != "<html>502 Bad Gateway</html>" {
+		t.Errorf("非JSON响应应该按状态码生成错误，实际: %+v", got.Status)
+	}
+	if got.ID == "" {
+		t.Error("生成的错误应该有ID")
+	}
+
+	if got, _ = FromHTTPResponse(newResponse(http.StatusServiceUnavailable, `{"error":"down"}`)); got.Message != `{"error":"down"}` || got.Code != 503 {
+		t.Errorf("不是错误结构的JSON应该按状态码生成错误，实际: %+v", got.Status)
+	}
+	if got, _ = FromHTTPResponse(newResponse(http.StatusBadGateway, "")); got.Message != "Bad Gateway" {
+		t.Errorf("空响应体应该使用状态码描述作为消息，实际: %q", got.Message)
+	}
+	if _, err := FromHTTPResponse(nil); err == nil {
+		t.Error("nil 响应应该返回错误")
+	}
+}
@@ -0,0 +1,50 @@
+package errors
+
+import "net/http"
+
+// metadataKeyRetryable gRPC metadata中传递显式设置的 Retryable
+const metadataKeyRetryable = "retryable"
+
+// WithRetryable explicitly marks the error as retryable or not, overriding the
+// default derived from its code.
+func (e *Error) WithRetryable(retryable bool) *Error {
+	err := Clone(e)
+	err.Retryable = retryable
+	err.retryableSet = true
+	return err
+}
+
+// IsRetryable reports whether the operation that returned err may be retried.
+// Unless overridden with WithRetryable, errors whose HTTP status, see
+// StatusCode, is 429, 503 or 504 are retryable and all others are not.
+func IsRetryable(err error) bool {
+	se := FromError(err)
+	if se == nil {
+		return false
+	}
+	if se.retryableSet {
+		return se.Retryable
+	}
+	switch se.StatusCode() {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// Timeout reports whether the error is a timeout, i.e. its HTTP status, see
+// StatusCode, is 408 or 504.
+// Together with Temporary it lets retry helpers written against net.Error
+// recognize errors of this package. Like net.Error's, the result is advisory.
+func (e *Error) Timeout() bool {
+	status := e.StatusCode()
+	return status == http.StatusRequestTimeout || status == http.StatusGatewayTimeout
+}
+
+// Temporary reports whether the error is temporary, as IsRetryable does. It
+// is advisory: net.Error deprecated Temporary because most errors are not
+// clearly temporary or permanent, but some retry libraries still check it.
+func (e *Error) Temporary() bool {
+	return IsRetryable(e)
+}
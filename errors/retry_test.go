@@ -0,0 +1,100 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"testing"
+)
+
+func TestIsRetryableDefaults(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"429", New(429, "TOO_MANY_REQUESTS", "请求过多"), true},
+		{"503", ServiceUnavailable("UNAVAILABLE", "服务不可用"), true},
+		{"504", GatewayTimeout("TIMEOUT", "超时"), true},
+		{"400", BadRequest("INVALID", "无效参数"), false},
+		{"404", NotFound("NOT_FOUND", "不存在"), false},
+		{"500", InternalServer("INTERNAL", "内部错误"), false},
+		{"包装的503", fmt.Errorf("wrap: %w", ServiceUnavailable("UNAVAILABLE", "服务不可用")), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable应该返回 %v, 实际: %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWithRetryableOverride(t *testing.T) {
+	original := InternalServer("DEADLOCK", "死锁")
+	retryable := original.WithRetryable(true)
+	if !IsRetryable(retryable) || !retryable.Retryable {
+		t.Error("WithRetryable(true)应该覆盖500的默认值")
+	}
+	if IsRetryable(original) {
+		t.Error("WithRetryable不应该修改原错误")
+	}
+	if IsRetryable(ServiceUnavailable("MAINTENANCE", "维护中").WithRetryable(false)) {
+		t.Error("WithRetryable(false)应该覆盖503的默认值")
+	}
+	if !IsRetryable(Clone(retryable).WithMetadata(map[string]string{"k": "v"})) {
+		t.Error("Clone和With*方法应该保留显式设置的Retryable")
+	}
+}
+
+func TestRetryableSurvivesGRPC(t *testing.T) {
+	converted := FromError(ServiceUnavailable("MAINTENANCE", "维护中").WithRetryable(false).GRPCStatus().Err())
+	if IsRetryable(converted) {
+		t.Error("显式设置的Retryable应该通过gRPC传递")
+	}
+	if _, ok := converted.Metadata[metadataKeyRetryable]; ok {
+		t.Errorf("retryable不应该保留在metadata中, 实际: %v", converted.Metadata)
+	}
+
+	converted = FromError(InternalServer("DEADLOCK", "死锁").WithRetryable(true).GRPCStatus().Err())
+	if !IsRetryable(converted) || !converted.Retryable {
+		t.Error("Retryable(true)应该通过gRPC传递")
+	}
+}
+
+func TestTemporaryAndTimeout(t *testing.T) {
+	tests := []struct {
+		code      int
+		temporary bool
+		timeout   bool
+	}{
+		{400, false, false},
+		{408, false, true},
+		{429, true, false},
+		{500, false, false},
+		{503, true, false},
+		{504, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.code), func(t *testing.T) {
+			err := New(tt.code, "TEST", "测试")
+			if got := err.Temporary(); got != tt.temporary {
+				t.Errorf("Temporary应该返回 %v, 实际: %v", tt.temporary, got)
+			}
+			if got := err.Timeout(); got != tt.timeout {
+				t.Errorf("Timeout应该返回 %v, 实际: %v", tt.timeout, got)
+			}
+		})
+	}
+
+	if New(503, "UNAVAILABLE", "服务不可用").WithRetryable(false).Temporary() {
+		t.Error("Temporary应该遵循 WithRetryable 的设置")
+	}
+
+	// 通过 net.Error 使用的接口识别
+	var err error = fmt.Errorf("wrap: %w", GatewayTimeout("TIMEOUT", "超时"))
+	var te interface{ Timeout() bool }
+	if !stderrors.As(err, &te) || !te.Timeout() {
+		t.Error("包装的错误应该可以通过 interface{ Timeout() bool } 识别")
+	}
+}
@@ -0,0 +1,100 @@
+package errors
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// metadataKeySeverity gRPC metadata中传递显式设置的 Severity
+const metadataKeySeverity = "severity"
+
+// SeverityLevel classifies errors for alerting. The zero value means the
+// severity has not been set explicitly and is derived from the code.
+type SeverityLevel int
+
+// Severity levels, from least to most severe.
+const (
+	SeverityUnspecified SeverityLevel = iota
+	SeverityDebug
+	SeverityInfo
+	SeverityWarn
+	SeverityError
+	SeverityCritical
+)
+
+// String returns the lower-case name of the severity.
+func (s SeverityLevel) String() string {
+	switch s {
+	case SeverityUnspecified:
+		return "unspecified"
+	case SeverityDebug:
+		return "debug"
+	case SeverityInfo:
+		return "info"
+	case SeverityWarn:
+		return "warn"
+	case SeverityError:
+		return "error"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+}
+
+// MarshalText encodes the severity as its name.
+func (s SeverityLevel) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a severity name as returned by String.
+func (s *SeverityLevel) UnmarshalText(text []byte) error {
+	level, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*s = level
+	return nil
+}
+
+// ParseSeverity parses a severity name as returned by String, ignoring case.
+func ParseSeverity(name string) (SeverityLevel, error) {
+	for s := SeverityUnspecified; s <= SeverityCritical; s++ {
+		if strings.EqualFold(name, s.String()) {
+			return s, nil
+		}
+	}
+	return SeverityUnspecified, fmt.Errorf("unknown severity %q", name)
+}
+
+// WithSeverity explicitly sets the severity of the error, overriding the
+// default derived from its code.
+func (e *Error) WithSeverity(severity SeverityLevel) *Error {
+	err := Clone(e)
+	err.Severity = severity
+	return err
+}
+
+// Severity returns the severity of err. Unless set with WithSeverity, it is
+// derived from the HTTP status, see StatusCode: 5xx statuses are
+// SeverityError, 408 and 499 are SeverityWarn, other 4xx statuses are
+// SeverityInfo, and everything else is SeverityError. It returns
+// SeverityUnspecified for a nil error.
+func Severity(err error) SeverityLevel {
+	se := FromError(err)
+	if se == nil {
+		return SeverityUnspecified
+	}
+	if se.Severity != SeverityUnspecified {
+		return se.Severity
+	}
+	switch {
+	case se.StatusCode() == http.StatusRequestTimeout || se.StatusCode() == 499:
+		return SeverityWarn
+	case se.IsClientError():
+		return SeverityInfo
+	default:
+		return SeverityError
+	}
+}
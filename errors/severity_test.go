@@ -0,0 +1,82 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSeverityDefaults(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want SeverityLevel
+	}{
+		{"nil", nil, SeverityUnspecified},
+		{"500", InternalServer("INTERNAL", "内部错误"), SeverityError},
+		{"503", ServiceUnavailable("UNAVAILABLE", "服务不可用"), SeverityError},
+		{"408", New(408, "REQUEST_TIMEOUT", "请求超时"), SeverityWarn},
+		{"499", ClientClosed("CANCELED", "客户端关闭"), SeverityWarn},
+		{"400", BadRequest("INVALID", "无效参数"), SeverityInfo},
+		{"404", NotFound("NOT_FOUND", "不存在"), SeverityInfo},
+		{"包装的500", fmt.Errorf("wrap: %w", InternalServer("INTERNAL", "内部错误")), SeverityError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Severity(tt.err); got != tt.want {
+				t.Errorf("Severity应该返回 %v, 实际: %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWithSeverityOverride(t *testing.T) {
+	original := NotFound("CONFIG_MISSING", "配置缺失")
+	critical := original.WithSeverity(SeverityCritical)
+	if Severity(critical) != SeverityCritical {
+		t.Errorf("WithSeverity应该覆盖默认值, 实际: %v", Severity(critical))
+	}
+	if Severity(original) != SeverityInfo {
+		t.Error("WithSeverity不应该修改原错误")
+	}
+	if Severity(critical.WithMetadata(map[string]string{"k": "v"})) != SeverityCritical {
+		t.Error("With*方法应该保留显式设置的Severity")
+	}
+}
+
+func TestSeveritySurvivesGRPC(t *testing.T) {
+	converted := FromError(NotFound("CONFIG_MISSING", "配置缺失").WithSeverity(SeverityCritical).GRPCStatus().Err())
+	if Severity(converted) != SeverityCritical {
+		t.Errorf("显式设置的Severity应该通过gRPC传递, 实际: %v", Severity(converted))
+	}
+	if _, ok := converted.Metadata[metadataKeySeverity]; ok {
+		t.Errorf("severity不应该保留在metadata中, 实际: %v", converted.Metadata)
+	}
+
+	converted = FromError(NotFound("NOT_FOUND", "不存在").GRPCStatus().Err())
+	if converted.Severity != SeverityUnspecified || Severity(converted) != SeverityInfo {
+		t.Errorf("未设置的Severity应该使用默认值, 实际: %v", converted.Severity)
+	}
+}
+
+func TestSeverityJSON(t *testing.T) {
+	data, err := json.Marshal(BadRequest("INVALID", "无效参数").WithSeverity(SeverityWarn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"severity":"warn"`) {
+		t.Errorf("JSON应该包含severity名称, 实际: %s", data)
+	}
+	var decoded Error
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Severity != SeverityWarn {
+		t.Errorf("反序列化后Severity应该是warn, 实际: %v", decoded.Severity)
+	}
+
+	if _, err := ParseSeverity("fatal"); err == nil {
+		t.Error("未知的severity名称应该返回错误")
+	}
+}
@@ -0,0 +1,42 @@
+package errors
+
+import (
+	"log/slog"
+	"sort"
+)
+
+// LogValue implements slog.LogValuer, so that logging an Error with log/slog
+// produces a group with its code, reason, id and message. Non-empty metadata
+// is added as a "metadata" group, with redacted keys scrubbed. The cause is
+// added as a "cause" group if it is an *Error, or as a string otherwise.
+func (e *Error) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, 6)
+	attrs = append(attrs,
+		slog.Int("code", int(e.Code)),
+		slog.String("reason", e.Reason),
+		slog.String("id", e.id()),
+		slog.String("message", e.Message),
+	)
+
+	if metadata := e.RedactedMetadata(); len(metadata) > 0 {
+		keys := make([]string, 0, len(metadata))
+		for k := range metadata {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		md := make([]slog.Attr, 0, len(keys))
+		for _, k := range keys {
+			md = append(md, slog.String(k, metadata[k]))
+		}
+		attrs = append(attrs, slog.Attr{Key: "metadata", Value: slog.GroupValue(md...)})
+	}
+
+	if e.cause != nil {
+		if se, ok := e.cause.(*Error); ok {
+			attrs = append(attrs, slog.Attr{Key: "cause", Value: se.LogValue()})
+		} else {
+			attrs = append(attrs, slog.String("cause", e.cause.Error()))
+		}
+	}
+	return slog.GroupValue(attrs...)
+}
@@ -0,0 +1,55 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"testing"
+)
+
+func TestLogValue(t *testing.T) {
+	err := NotFound("USER_NOT_FOUND", "用户不存在").
+		WithMetadata(map[string]string{"user_id": "42"}).
+		WithCause(InternalServer("DB_ERROR", "数据库错误").WithCause(fmt.Errorf("connection refused")))
+
+	v := err.LogValue()
+	if v.Kind() != slog.KindGroup {
+		t.Fatalf("LogValue应该返回分组, 实际: %v", v.Kind())
+	}
+	attrs := make(map[string]slog.Value)
+	for _, a := range v.Group() {
+		attrs[a.Key] = a.Value
+	}
+	if attrs["code"].Int64() != 404 || attrs["reason"].String() != "USER_NOT_FOUND" ||
+		attrs["id"].String() != err.ID || attrs["message"].String() != "用户不存在" {
+		t.Errorf("分组应该包含code、reason、id和message, 实际: %v", v)
+	}
+	if attrs["metadata"].Kind() != slog.KindGroup {
+		t.Errorf("metadata应该是子分组, 实际: %v", attrs["metadata"])
+	}
+	if attrs["cause"].Kind() != slog.KindGroup {
+		t.Fatalf("*Error类型的cause应该是子分组, 实际: %v", attrs["cause"])
+	}
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Error("failed", "err", err)
+	var record struct {
+		Err struct {
+			Code     int               `json:"code"`
+			Reason   string            `json:"reason"`
+			Metadata map[string]string `json:"metadata"`
+			Cause    struct {
+				Reason string `json:"reason"`
+				Cause  string `json:"cause"`
+			} `json:"cause"`
+		} `json:"err"`
+	}
+	if jsonErr := json.Unmarshal(buf.Bytes(), &record); jsonErr != nil {
+		t.Fatalf("日志不是有效的JSON: %v\n%s", jsonErr, buf.String())
+	}
+	if record.Err.Code != 404 || record.Err.Metadata["user_id"] != "42" ||
+		record.Err.Cause.Reason != "DB_ERROR" || record.Err.Cause.Cause != "connection refused" {
+		t.Errorf("slog输出的字段不正确: %s", buf.String())
+	}
+}
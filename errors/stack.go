@@ -0,0 +1,61 @@
+package errors
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// maxStackCaptureDepth 调用栈记录的最大深度
+const maxStackCaptureDepth = 64
+
+// stackCaptureDepth 创建错误时记录的调用栈深度，0表示不记录
+var stackCaptureDepth atomic.Int32
+
+// SetStackCaptureDepth makes New, Newf, Errorf and AcquireError record up to n
+// stack frames of the code creating the error (capped at 64). Zero, the
+// default, disables stack capture so only the caller frame embedded in the ID
+// is kept. The captured stack is available through StackTrace, Frames and the
+// %+v verb.
+func SetStackCaptureDepth(n int) {
+	if n < 0 {
+		n = 0
+	}
+	if n > maxStackCaptureDepth {
+		n = maxStackCaptureDepth
+	}
+	stackCaptureDepth.Store(int32(n))
+}
+
+// captureStack 记录调用栈，skip=1 表示 captureStack 的调用者
+func captureStack(skip int) []uintptr {
+	depth := stackCaptureDepth.Load()
+	if depth == 0 {
+		return nil
+	}
+	pcs := make([]uintptr, depth)
+	n := runtime.Callers(skip+1, pcs)
+	return pcs[:n]
+}
+
+// StackTrace returns the program counters recorded when the error was
+// created, or nil if stack capture was disabled at that time.
+func (e *Error) StackTrace() []uintptr {
+	return e.stack
+}
+
+// Frames resolves StackTrace into runtime frames, innermost first.
+func (e *Error) Frames() []runtime.Frame {
+	if len(e.stack) == 0 {
+		return nil
+	}
+	frames := make([]runtime.Frame, 0, len(e.stack))
+	iter := runtime.CallersFrames(e.stack)
+	for {
+		frame, more := iter.Next()
+		frames = append(frames, frame)
+		if !more {
+			break
+		}
+	}
+	return frames
+}
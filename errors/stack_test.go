@@ -0,0 +1,48 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// newStackError 多一层调用，用于验证调用栈的顺序
+func newStackError() *Error {
+	return New(500, "STACK", "调用栈测试")
+}
+
+func TestStackCapture(t *testing.T) {
+	// 默认不记录调用栈
+	if err := New(500, "STACK", "调用栈测试"); err.StackTrace() != nil || err.Frames() != nil {
+		t.Error("默认情况下不应该记录调用栈")
+	}
+
+	SetStackCaptureDepth(8)
+	t.Cleanup(func() { SetStackCaptureDepth(0) })
+
+	err := newStackError()
+	if n := len(err.StackTrace()); n == 0 || n > 8 {
+		t.Fatalf("调用栈深度应该在1到8之间，实际: %d", n)
+	}
+
+	frames := err.Frames()
+	if !strings.HasSuffix(frames[0].Function, ".newStackError") {
+		t.Errorf("第一帧应该是创建错误的函数，实际: %s", frames[0].Function)
+	}
+	if !strings.HasSuffix(frames[1].Function, ".TestStackCapture") {
+		t.Errorf("第二帧应该是调用者，实际: %s", frames[1].Function)
+	}
+
+	// With* 方法保留原始调用栈
+	if len(err.WithMetadata(map[string]string{"k": "v"}).StackTrace()) != len(err.StackTrace()) {
+		t.Error("WithMetadata应该保留调用栈")
+	}
+
+	verbose := fmt.Sprintf("%+v", err)
+	if !strings.Contains(verbose, "newStackError") || !strings.Contains(verbose, "stack_test.go:") {
+		t.Errorf("%%+v 应该输出调用栈，实际: %s", verbose)
+	}
+	if compact := fmt.Sprintf("%v", err); compact != err.Error() {
+		t.Errorf("%%v 应该与Error()一致，实际: %s", compact)
+	}
+}
@@ -0,0 +1,110 @@
+package errors
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrorStore is a bounded in-memory store of errors keyed by error ID, which
+// evicts the least recently used error when full. It lets development tools
+// look up the full error, including its cause chain, stack and unredacted
+// metadata, from the ID a client reported. It is safe for concurrent use.
+type ErrorStore struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List               // 最近使用的在前
+	items map[string]*list.Element // 错误ID到 order 中元素的映射
+}
+
+// NewErrorStore returns a store holding at most size errors.
+func NewErrorStore(size int) *ErrorStore {
+	return &ErrorStore{
+		size:  max(size, 1),
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// Add stores a copy of e under its ID, generating the ID if needed. Errors
+// without an ID, e.g. while ID generation is disabled, are not stored.
+func (s *ErrorStore) Add(e *Error) {
+	if s == nil || e == nil {
+		return
+	}
+	id := e.GetID()
+	if id == "" {
+		return
+	}
+	// 保存副本，避免错误被 ReleaseError 放回池中后内容改变
+	stored := Clone(e)
+	stored.ID = id
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.items[id]; ok {
+		el.Value = stored
+		s.order.MoveToFront(el)
+		return
+	}
+	s.items[id] = s.order.PushFront(stored)
+	if s.order.Len() > s.size {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.items, oldest.Value.(*Error).ID)
+	}
+}
+
+// Lookup returns the error stored under id. The returned error is shared with
+// the store and must not be modified.
+func (s *ErrorStore) Lookup(id string) (*Error, bool) {
+	if s == nil {
+		return nil, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.items[id]
+	if !ok {
+		return nil, false
+	}
+	s.order.MoveToFront(el)
+	return el.Value.(*Error), true
+}
+
+// Len returns the number of stored errors.
+func (s *ErrorStore) Len() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+// errorStore 拦截器保存错误使用的存储，nil 表示未开启
+var errorStore atomic.Pointer[ErrorStore]
+
+// SetErrorStoreSize enables the process-wide ErrorStore, holding at most size
+// errors, which the interceptor package fills with every error it handles.
+// Look errors up with LookupError. A size of zero or less, the default,
+// disables the store and drops the stored errors. Stored errors keep their
+// causes and metadata in memory, so keep the size small and prefer to enable
+// it in development only.
+func SetErrorStoreSize(size int) {
+	if size <= 0 {
+		errorStore.Store(nil)
+		return
+	}
+	errorStore.Store(NewErrorStore(size))
+}
+
+// StoreError adds e to the store enabled with SetErrorStoreSize. It does
+// nothing while the store is disabled.
+func StoreError(e *Error) {
+	errorStore.Load().Add(e)
+}
+
+// LookupError returns the error stored under id by StoreError.
+func LookupError(id string) (*Error, bool) {
+	return errorStore.Load().Lookup(id)
+}
@@ -0,0 +1,78 @@
+package errors
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestErrorStoreEviction(t *testing.T) {
+	s := NewErrorStore(2)
+	first, second, third := NotFound("A", "a"), NotFound("B", "b"), NotFound("C", "c")
+	s.Add(first)
+	s.Add(second)
+	// 访问 first 后 second 成为最久未使用的错误
+	if _, ok := s.Lookup(first.GetID()); !ok {
+		t.Fatal("first 应该在存储中")
+	}
+	s.Add(third)
+
+	if s.Len() != 2 {
+		t.Errorf("存储应该最多保存 2 个错误，实际: %d", s.Len())
+	}
+	if _, ok := s.Lookup(second.GetID()); ok {
+		t.Error("最久未使用的 second 应该被淘汰")
+	}
+	for _, e := range []*Error{first, third} {
+		if got, ok := s.Lookup(e.GetID()); !ok || got.Reason != e.Reason {
+			t.Errorf("%s 应该在存储中，实际: %v %v", e.Reason, got, ok)
+		}
+	}
+}
+
+func TestErrorStoreLookup(t *testing.T) {
+	SetErrorStoreSize(4)
+	t.Cleanup(func() { SetErrorStoreSize(0) })
+
+	e := InternalServer("DB_ERROR", "数据库错误").WithMetadataKV("table", "orders")
+	StoreError(e)
+	e.Metadata["table"] = "users"
+
+	got, ok := LookupError(e.GetID())
+	if !ok {
+		t.Fatal("保存的错误应该可以按ID查找")
+	}
+	if got.Metadata["table"] != "orders" {
+		t.Errorf("存储应该保存错误的副本，实际: %v", got.Metadata)
+	}
+	if _, ok := LookupError("unknown"); ok {
+		t.Error("未知ID不应该找到错误")
+	}
+
+	SetErrorStoreSize(0)
+	StoreError(e)
+	if _, ok := LookupError(e.GetID()); ok {
+		t.Error("关闭存储后不应该找到错误")
+	}
+}
+
+func TestErrorStoreConcurrent(t *testing.T) {
+	s := NewErrorStore(16)
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 100 {
+				e := NotFound(fmt.Sprintf("R_%d_%d", i, j), "not found")
+				s.Add(e)
+				s.Lookup(e.GetID())
+				s.Len()
+			}
+		}()
+	}
+	wg.Wait()
+	if s.Len() != 16 {
+		t.Errorf("存储应该保存 16 个错误，实际: %d", s.Len())
+	}
+}
@@ -0,0 +1,158 @@
+package errors
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Reserved metadata keys set by NewFromTemplate.
+const (
+	MetadataKeyCategory  = "category"
+	MetadataKeyShortCode = "short_code"
+)
+
+// Template describes an error reason. Generated code registers a template for
+// every reason declared in proto files; templates for hand-written errors can
+// be registered with RegisterTemplate.
+type Template struct {
+	Code   int32
+	Reason string
+	// Message is the default message. It is used as a format string when
+	// NewFromTemplate is given arguments.
+	Message string
+	// Category groups related reasons. Generated code sets it to the fully
+	// qualified name of the proto enum declaring them, e.g.
+	// "api.user.v1.UserError", so that packages declaring the same reason
+	// register separate templates.
+	Category string
+	// Retryable marks errors created from the template as retryable. When
+	// false, IsRetryable falls back to the default derived from Code.
+	Retryable bool
+	// ShortCode is an optional compact identifier shown to end users.
+	ShortCode string
+}
+
+// templateKey 模板按分类和原因注册，不同包中相同的原因互不覆盖
+type templateKey struct {
+	category string
+	reason   string
+}
+
+var (
+	templatesMu sync.RWMutex
+	templates   = make(map[templateKey]Template)
+	// templateCategories 原因到注册了该原因的分类的映射，已排序
+	templateCategories = make(map[string][]string)
+)
+
+// RegisterTemplate registers t under its category and reason, replacing any
+// template previously registered for the same category and reason. Templates
+// with the same reason in other categories are kept.
+func RegisterTemplate(t Template) {
+	templatesMu.Lock()
+	defer templatesMu.Unlock()
+	key := templateKey{category: t.Category, reason: t.Reason}
+	if _, ok := templates[key]; !ok {
+		categories := append(templateCategories[t.Reason], t.Category)
+		sort.Strings(categories)
+		templateCategories[t.Reason] = categories
+	}
+	templates[key] = t
+}
+
+// LookupTemplate returns the template registered for reason. If templates
+// for reason are registered in several categories, e.g. by two generated
+// packages declaring NOT_FOUND, the reason is ambiguous and LookupTemplate
+// reports false; use LookupCategoryTemplate instead.
+func LookupTemplate(reason string) (Template, bool) {
+	t, msg := lookupTemplate(reason)
+	return t, msg == ""
+}
+
+// LookupCategoryTemplate returns the template registered for reason in
+// category.
+func LookupCategoryTemplate(category, reason string) (Template, bool) {
+	templatesMu.RLock()
+	defer templatesMu.RUnlock()
+	t, ok := templates[templateKey{category: category, reason: reason}]
+	return t, ok
+}
+
+// lookupTemplate 按原因查找唯一的模板，找不到或有歧义时返回说明原因的消息
+func lookupTemplate(reason string) (Template, string) {
+	templatesMu.RLock()
+	defer templatesMu.RUnlock()
+	switch categories := templateCategories[reason]; len(categories) {
+	case 0:
+		return Template{}, fmt.Sprintf("no error template registered for reason %q", reason)
+	case 1:
+		return templates[templateKey{category: categories[0], reason: reason}], ""
+	default:
+		return Template{}, fmt.Sprintf("error template for reason %q is ambiguous, registered in categories %q", reason, categories)
+	}
+}
+
+// NewFromTemplate returns an error built from the template registered for
+// reason, formatting its message with args. Category and ShortCode are
+// recorded in the metadata. If no template or, see LookupTemplate, more than
+// one template is registered for reason, the error has UnknownCode and a
+// message saying so.
+func NewFromTemplate(reason string, args ...any) *Error {
+	t, msg := lookupTemplate(reason)
+	return newFromTemplate(t, msg, reason, args)
+}
+
+// NewFromCategoryTemplate is like NewFromTemplate but uses the template
+// registered for reason in category, see LookupCategoryTemplate.
+func NewFromCategoryTemplate(category, reason string, args ...any) *Error {
+	t, ok := LookupCategoryTemplate(category, reason)
+	msg := ""
+	if !ok {
+		msg = fmt.Sprintf("no error template registered for reason %q in category %q", reason, category)
+	}
+	return newFromTemplate(t, msg, reason, args)
+}
+
+// newFromTemplate 根据模板构建错误，msg 非空时表示没有可用的模板。
+// 调用栈和错误ID跳过本函数和导出的 NewFrom* 函数，指向它们的调用者
+func newFromTemplate(t Template, msg, reason string, args []any) *Error {
+	if msg != "" {
+		e := &Error{
+			Status: Status{
+				Code:    UnknownCode,
+				Reason:  reason,
+				Message: msg,
+			},
+			stack: captureStack(3),
+		}
+		e.initID(3)
+		return e
+	}
+
+	message := t.Message
+	if len(args) > 0 {
+		message = fmt.Sprintf(t.Message, args...)
+	}
+	e := &Error{
+		Status: Status{
+			Code:      t.Code,
+			Reason:    t.Reason,
+			Message:   message,
+			Retryable: t.Retryable,
+		},
+		stack:        captureStack(3),
+		retryableSet: t.Retryable,
+	}
+	e.initID(3)
+	if t.Category != "" || t.ShortCode != "" {
+		e.Metadata = make(map[string]string, 2)
+		if t.Category != "" {
+			e.Metadata[MetadataKeyCategory] = t.Category
+		}
+		if t.ShortCode != "" {
+			e.Metadata[MetadataKeyShortCode] = t.ShortCode
+		}
+	}
+	return e
+}
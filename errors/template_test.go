@@ -0,0 +1,96 @@
+package errors
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewFromTemplate(t *testing.T) {
+	RegisterTemplate(Template{
+		Code:      429,
+		Reason:    "QUOTA_EXCEEDED",
+		Message:   "配额已用完: %s",
+		Category:  "Billing",
+		Retryable: true,
+		ShortCode: "B001",
+	})
+
+	err := NewFromTemplate("QUOTA_EXCEEDED", "api-calls")
+	if err.Code != 429 || err.Reason != "QUOTA_EXCEEDED" || err.Message != "配额已用完: api-calls" {
+		t.Errorf("错误应该根据模板构建, 实际: %v", err)
+	}
+	if err.Metadata[MetadataKeyCategory] != "Billing" || err.Metadata[MetadataKeyShortCode] != "B001" {
+		t.Errorf("分类和短码应该写入metadata, 实际: %v", err.Metadata)
+	}
+	if !IsRetryable(err) {
+		t.Error("模板标记为可重试时错误应该可重试")
+	}
+	if info, decodeErr := DecodeErrorID(err.ID); decodeErr != nil || !strings.Contains(info.Function, "TestNewFromTemplate") {
+		t.Errorf("错误ID应该指向调用者, 实际: %+v", info)
+	}
+
+	if tmpl, ok := LookupTemplate("QUOTA_EXCEEDED"); !ok || tmpl.ShortCode != "B001" {
+		t.Errorf("应该能查询到注册的模板, 实际: %+v", tmpl)
+	}
+}
+
+func TestNewFromTemplateWithoutArgs(t *testing.T) {
+	RegisterTemplate(Template{Code: 400, Reason: "PERCENT_LITERAL", Message: "100% 无效"})
+
+	err := NewFromTemplate("PERCENT_LITERAL")
+	if err.Message != "100% 无效" {
+		t.Errorf("没有参数时消息应该保持原样, 实际: %s", err.Message)
+	}
+	if len(err.Metadata) != 0 || IsRetryable(err) {
+		t.Errorf("没有分类和短码时不应该写入metadata, 实际: %v", err.Metadata)
+	}
+}
+
+func TestNewFromTemplateMissing(t *testing.T) {
+	if _, ok := LookupTemplate("NOT_REGISTERED"); ok {
+		t.Fatal("未注册的原因不应该查询到模板")
+	}
+
+	err := NewFromTemplate("NOT_REGISTERED", "ignored")
+	if err.Code != UnknownCode || err.Reason != "NOT_REGISTERED" {
+		t.Errorf("未注册的模板应该返回未知错误并保留原因, 实际: %v", err)
+	}
+	if !strings.Contains(err.Message, "NOT_REGISTERED") {
+		t.Errorf("消息应该说明缺少模板, 实际: %s", err.Message)
+	}
+}
+
+func TestTemplateCategories(t *testing.T) {
+	// 两个生成的包声明了相同的原因，注册顺序不应该影响结果
+	RegisterTemplate(Template{Code: 404, Reason: "SHARED_NOT_FOUND", Message: "用户不存在", Category: "api.user.v1.UserError"})
+	RegisterTemplate(Template{Code: 410, Reason: "SHARED_NOT_FOUND", Message: "订单不存在", Category: "api.order.v1.OrderError"})
+
+	for category, code := range map[string]int32{"api.user.v1.UserError": 404, "api.order.v1.OrderError": 410} {
+		if tmpl, ok := LookupCategoryTemplate(category, "SHARED_NOT_FOUND"); !ok || tmpl.Code != code {
+			t.Errorf("%s 的模板不应该被其他包覆盖, 实际: %+v", category, tmpl)
+		}
+		if err := NewFromCategoryTemplate(category, "SHARED_NOT_FOUND"); err.Code != code || err.Metadata[MetadataKeyCategory] != category {
+			t.Errorf("应该根据 %s 的模板构建错误, 实际: %v", category, err)
+		}
+	}
+
+	if _, ok := LookupTemplate("SHARED_NOT_FOUND"); ok {
+		t.Error("多个分类注册了相同的原因时按原因查询应该失败")
+	}
+	err := NewFromTemplate("SHARED_NOT_FOUND")
+	if err.Code != UnknownCode || !strings.Contains(err.Message, "ambiguous") {
+		t.Errorf("有歧义的原因应该返回未知错误并说明原因, 实际: %v", err)
+	}
+	if info, decodeErr := DecodeErrorID(err.ID); decodeErr != nil || !strings.Contains(info.Function, "TestTemplateCategories") {
+		t.Errorf("错误ID应该指向调用者, 实际: %+v", info)
+	}
+
+	// 同一个分类重复注册时替换原模板
+	RegisterTemplate(Template{Code: 404, Reason: "SHARED_NOT_FOUND", Message: "用户已注销", Category: "api.user.v1.UserError"})
+	if tmpl, _ := LookupCategoryTemplate("api.user.v1.UserError", "SHARED_NOT_FOUND"); tmpl.Message != "用户已注销" {
+		t.Errorf("同一分类重复注册应该替换模板, 实际: %+v", tmpl)
+	}
+	if err := NewFromCategoryTemplate("api.pay.v1.PayError", "SHARED_NOT_FOUND"); err.Code != UnknownCode || !strings.Contains(err.Message, "api.pay.v1.PayError") {
+		t.Errorf("分类中未注册的原因应该返回未知错误, 实际: %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+package errors
+
+import "strconv"
+
+// MetadataKeyTerminal is the metadata key that records an explicit
+// WithTerminal override. Being metadata, it survives gRPC round-trips.
+const MetadataKeyTerminal = "terminal"
+
+// WithTerminal marks the error as terminal (never retry, fail the workflow)
+// or transient, overriding the default derived from its code.
+func (e *Error) WithTerminal(terminal bool) *Error {
+	err := Clone(e)
+	err.Metadata[MetadataKeyTerminal] = strconv.FormatBool(terminal)
+	return err
+}
+
+// IsTerminal reports whether err should fail a workflow permanently instead of
+// being retried. Unless overridden with WithTerminal, errors with a 4xx HTTP
+// status, see StatusCode, are terminal and all other errors are transient.
+func IsTerminal(err error) bool {
+	se := FromError(err)
+	if se == nil {
+		return false
+	}
+	if v, ok := se.Metadata[MetadataKeyTerminal]; ok {
+		if terminal, parseErr := strconv.ParseBool(v); parseErr == nil {
+			return terminal
+		}
+	}
+	return se.IsClientError()
+}
@@ -0,0 +1,52 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestIsTerminalDefaults(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"400", BadRequest("INVALID", "无效参数"), true},
+		{"404", NotFound("NOT_FOUND", "不存在"), true},
+		{"500", InternalServer("INTERNAL", "内部错误"), false},
+		{"503", ServiceUnavailable("UNAVAILABLE", "服务不可用"), false},
+		{"包装的4xx", fmt.Errorf("wrap: %w", Forbidden("DENIED", "拒绝")), true},
+		{"普通错误", fmt.Errorf("boom"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTerminal(tt.err); got != tt.want {
+				t.Errorf("IsTerminal应该返回 %v, 实际: %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWithTerminalOverride(t *testing.T) {
+	original := BadRequest("RATE_LIMITED", "请稍后重试")
+	transient := original.WithTerminal(false)
+	if IsTerminal(transient) {
+		t.Error("WithTerminal(false)应该覆盖4xx的默认值")
+	}
+	if !IsTerminal(original) {
+		t.Error("WithTerminal不应该修改原错误")
+	}
+	if !IsTerminal(InternalServer("CORRUPTED", "数据损坏").WithTerminal(true)) {
+		t.Error("WithTerminal(true)应该覆盖5xx的默认值")
+	}
+}
+
+func TestTerminalSurvivesGRPC(t *testing.T) {
+	err := InternalServer("CORRUPTED", "数据损坏").WithTerminal(true)
+
+	converted := FromError(err.GRPCStatus().Err())
+	if !IsTerminal(converted) {
+		t.Errorf("终止标记应该通过gRPC传递, 实际metadata: %v", converted.Metadata)
+	}
+}
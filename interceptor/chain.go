@@ -0,0 +1,68 @@
+package interceptor
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// ChainUnaryServer combines interceptors into a single unary server
+// interceptor. As with grpc.ChainUnaryInterceptor, the first interceptor is
+// the outermost and the last one wraps the handler directly, so list
+// UnaryServerErrorInterceptor first to convert the errors returned by all
+// the others:
+//
+//	interceptor.ChainUnaryServer(
+//		interceptor.UnaryServerErrorInterceptor(),
+//		metricsInterceptor,
+//		authInterceptor,
+//	)
+func ChainUnaryServer(interceptors ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	switch len(interceptors) {
+	case 0:
+		return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			return handler(ctx, req)
+		}
+	case 1:
+		return interceptors[0]
+	}
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return interceptors[0](ctx, req, info, chainUnaryHandler(interceptors, 0, info, handler))
+	}
+}
+
+// chainUnaryHandler 返回调用第 curr+1 个拦截器的处理器，最后一个拦截器调用原始处理器
+func chainUnaryHandler(interceptors []grpc.UnaryServerInterceptor, curr int, info *grpc.UnaryServerInfo, final grpc.UnaryHandler) grpc.UnaryHandler {
+	if curr == len(interceptors)-1 {
+		return final
+	}
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		return interceptors[curr+1](ctx, req, info, chainUnaryHandler(interceptors, curr+1, info, final))
+	}
+}
+
+// ChainStreamServer is the stream counterpart of ChainUnaryServer, with the
+// same order as grpc.ChainStreamInterceptor.
+func ChainStreamServer(interceptors ...grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	switch len(interceptors) {
+	case 0:
+		return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return handler(srv, ss)
+		}
+	case 1:
+		return interceptors[0]
+	}
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return interceptors[0](srv, ss, info, chainStreamHandler(interceptors, 0, info, handler))
+	}
+}
+
+// chainStreamHandler 返回调用第 curr+1 个流拦截器的处理器，最后一个拦截器调用原始处理器
+func chainStreamHandler(interceptors []grpc.StreamServerInterceptor, curr int, info *grpc.StreamServerInfo, final grpc.StreamHandler) grpc.StreamHandler {
+	if curr == len(interceptors)-1 {
+		return final
+	}
+	return func(srv interface{}, ss grpc.ServerStream) error {
+		return interceptors[curr+1](srv, ss, info, chainStreamHandler(interceptors, curr+1, info, final))
+	}
+}
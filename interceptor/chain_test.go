@@ -0,0 +1,110 @@
+package interceptor
+
+import (
+	"context"
+	stderrors "errors"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+)
+
+// recordingUnary 返回记录进入和退出顺序的一元拦截器
+func recordingUnary(name string, calls *[]string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		*calls = append(*calls, name+" before")
+		resp, err := handler(ctx, req)
+		*calls = append(*calls, name+" after")
+		return resp, err
+	}
+}
+
+// recordingStream 返回记录进入和退出顺序的流拦截器
+func recordingStream(name string, calls *[]string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		*calls = append(*calls, name+" before")
+		err := handler(srv, ss)
+		*calls = append(*calls, name+" after")
+		return err
+	}
+}
+
+func TestChainUnaryServerOrder(t *testing.T) {
+	var calls []string
+	chain := ChainUnaryServer(recordingUnary("first", &calls), recordingUnary("second", &calls), recordingUnary("third", &calls))
+	resp, err := chain(context.Background(), "req", &grpc.UnaryServerInfo{FullMethod: "/svc/Get"},
+		func(_ context.Context, req interface{}) (interface{}, error) {
+			calls = append(calls, "handler")
+			return req, nil
+		})
+	if err != nil || resp != "req" {
+		t.Fatalf("链应该返回处理器的结果，实际: %v %v", resp, err)
+	}
+
+	want := []string{"first before", "second before", "third before", "handler", "third after", "second after", "first after"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("执行顺序应该与 grpc.ChainUnaryInterceptor 一致\n期望: %v\n实际: %v", want, calls)
+	}
+}
+
+func TestChainUnaryServerConvertsLast(t *testing.T) {
+	var seen error
+	inner := func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		_, err := handler(ctx, req)
+		seen = err
+		return nil, err
+	}
+	chain := ChainUnaryServer(UnaryServerErrorInterceptor(WithLogger(nopLogger)), inner)
+	_, err := chain(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/svc/Get"},
+		func(context.Context, interface{}) (interface{}, error) {
+			return nil, errors.NotFound("ORDER_NOT_FOUND", "订单不存在")
+		})
+
+	var appErr *errors.Error
+	if !stderrors.As(seen, &appErr) {
+		t.Errorf("内层拦截器应该看到原始错误，实际: %T", seen)
+	}
+	if st, _ := status.FromError(err); stderrors.As(err, &appErr) || st.Code() != codes.NotFound {
+		t.Errorf("错误拦截器应该最后转换错误，实际: %v", err)
+	}
+}
+
+func TestChainStreamServer(t *testing.T) {
+	var calls []string
+	chain := ChainStreamServer(
+		StreamServerErrorInterceptor(WithLogger(nopLogger)),
+		recordingStream("first", &calls),
+		recordingStream("second", &calls),
+	)
+	ss := &fakeServerStream{ctx: context.Background()}
+	err := chain(nil, ss, &grpc.StreamServerInfo{FullMethod: "/svc/Stream"}, func(interface{}, grpc.ServerStream) error {
+		calls = append(calls, "handler")
+		return stderrors.New("boom")
+	})
+
+	want := []string{"first before", "second before", "handler", "second after", "first after"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("执行顺序应该与 grpc.ChainStreamInterceptor 一致\n期望: %v\n实际: %v", want, calls)
+	}
+	if st, ok := status.FromError(err); !ok || st.Code() != codes.Internal {
+		t.Errorf("错误拦截器应该最后转换错误，实际: %v", err)
+	}
+}
+
+func TestChainServerEmpty(t *testing.T) {
+	resp, err := ChainUnaryServer()(context.Background(), "req", &grpc.UnaryServerInfo{},
+		func(_ context.Context, req interface{}) (interface{}, error) { return req, nil })
+	if err != nil || resp != "req" {
+		t.Errorf("空链应该直接调用处理器，实际: %v %v", resp, err)
+	}
+	called := false
+	_ = ChainStreamServer()(nil, &fakeServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{},
+		func(interface{}, grpc.ServerStream) error { called = true; return nil })
+	if !called {
+		t.Error("空链应该直接调用流处理器")
+	}
+}
@@ -0,0 +1,75 @@
+package interceptor
+
+import (
+	"context"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	errorspb "github.com/honeybbq/go-zero-errors-proto/errors"
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+)
+
+// UnaryClientErrorInterceptor returns a new unary client interceptor that
+// converts gRPC statuses carrying our error details into *errors.Error, so that
+// errors.Reason, errors.ID and friends work directly on the returned error.
+// Other errors are returned unchanged.
+func UnaryClientErrorInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return clientError(invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
+
+// StreamClientErrorInterceptor returns a new stream client interceptor that
+// converts errors like UnaryClientErrorInterceptor, both when opening the
+// stream and from the returned stream's methods.
+func StreamClientErrorInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, clientError(err)
+		}
+		return &errorClientStream{ClientStream: cs}, nil
+	}
+}
+
+// errorClientStream wraps a grpc.ClientStream so that errors returned by its
+// methods are converted by clientError.
+type errorClientStream struct {
+	grpc.ClientStream
+}
+
+func (s *errorClientStream) RecvMsg(m interface{}) error {
+	return clientError(s.ClientStream.RecvMsg(m))
+}
+
+func (s *errorClientStream) SendMsg(m interface{}) error {
+	return clientError(s.ClientStream.SendMsg(m))
+}
+
+func (s *errorClientStream) CloseSend() error {
+	return clientError(s.ClientStream.CloseSend())
+}
+
+// clientError 将携带 errorspb.Status 详情的gRPC错误转换为 *errors.Error，其他错误原样返回
+func clientError(err error) error {
+	if err == nil || err == io.EOF {
+		return err
+	}
+	st, ok := status.FromError(err)
+	if !ok || !hasStatusDetail(st) {
+		return err
+	}
+	return errors.FromError(err)
+}
+
+// hasStatusDetail 判断gRPC状态是否包含我们的错误详情
+func hasStatusDetail(st *status.Status) bool {
+	for _, detail := range st.Proto().GetDetails() {
+		if detail.MessageIs((*errorspb.Status)(nil)) {
+			return true
+		}
+	}
+	return false
+}
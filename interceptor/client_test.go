@@ -0,0 +1,88 @@
+package interceptor
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+)
+
+// dialFailing 启动进程内gRPC服务器，所有方法都返回serverErr，返回使用客户端拦截器的连接
+func dialFailing(t *testing.T, serverErr error) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(grpc.UnknownServiceHandler(func(any, grpc.ServerStream) error {
+		return serverErr
+	}))
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientErrorInterceptor()),
+		grpc.WithStreamInterceptor(StreamClientErrorInterceptor()))
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestUnaryClientErrorInterceptor(t *testing.T) {
+	serverErr := errors.NotFound("USER_NOT_FOUND", "用户不存在")
+	conn := dialFailing(t, serverErr)
+
+	err := conn.Invoke(context.Background(), "/user.v1.User/Get", &emptypb.Empty{}, &emptypb.Empty{})
+	appErr, ok := err.(*errors.Error)
+	if !ok {
+		t.Fatalf("客户端应该直接得到*errors.Error，实际: %T", err)
+	}
+	if errors.Reason(err) != "USER_NOT_FOUND" || appErr.Code != 404 {
+		t.Errorf("原因和状态码应该被还原，实际: %v", appErr)
+	}
+	if appErr.ID != serverErr.ID {
+		t.Errorf("错误ID应该被还原\n期望: %s\n实际: %s", serverErr.ID, appErr.ID)
+	}
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("转换后的错误仍应该对应gRPC状态码，实际: %v", status.Code(err))
+	}
+}
+
+func TestStreamClientErrorInterceptor(t *testing.T) {
+	serverErr := errors.Forbidden("ACCESS_DENIED", "无权访问")
+	conn := dialFailing(t, serverErr)
+
+	stream, err := conn.NewStream(context.Background(), &grpc.StreamDesc{ServerStreams: true}, "/user.v1.User/Watch")
+	if err != nil {
+		t.Fatalf("创建流失败: %v", err)
+	}
+	// 服务器可能已经结束了流，这里只关心RecvMsg返回的错误
+	_ = stream.SendMsg(&emptypb.Empty{})
+	_ = stream.CloseSend()
+
+	err = stream.RecvMsg(&emptypb.Empty{})
+	if errors.Reason(err) != "ACCESS_DENIED" || errors.ID(err) != serverErr.ID {
+		t.Errorf("流上的错误应该被转换，实际: %v", err)
+	}
+}
+
+func TestClientErrorPassesThroughPlainStatus(t *testing.T) {
+	conn := dialFailing(t, status.Error(codes.Unavailable, "down"))
+
+	err := conn.Invoke(context.Background(), "/user.v1.User/Get", &emptypb.Empty{}, &emptypb.Empty{})
+	if _, ok := err.(*errors.Error); ok {
+		t.Error("没有错误详情的gRPC状态应该原样返回")
+	}
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("gRPC状态码应该保留，实际: %v", status.Code(err))
+	}
+}
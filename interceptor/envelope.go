@@ -0,0 +1,54 @@
+package interceptor
+
+import "github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+
+// Envelope is the response shape commonly used by go-zero applications for
+// both successful and failed requests. Code is the business code, 0 for
+// success; Msg is the message and Data the payload of successful responses.
+type Envelope struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data any    `json:"data,omitempty"`
+}
+
+// OkEnvelope wraps data in a successful Envelope, so that success responses
+// match the errors rendered by GoZeroEnvelopeFormatter:
+//
+//	httpx.OkJsonCtx(r.Context(), w, interceptor.OkEnvelope(resp))
+func OkEnvelope(data any) Envelope {
+	return Envelope{Code: 0, Msg: "ok", Data: data}
+}
+
+// EnvelopeCodeMapper returns the business code put in the Envelope of an
+// error.
+type EnvelopeCodeMapper func(*errors.Error) int
+
+// WithEnvelopeCode sets the business code mapping used by SetGoZeroEnvelope.
+// By default the business code is the code of the error.
+func WithEnvelopeCode(mapper EnvelopeCodeMapper) Option {
+	return func(o *options) {
+		o.envelopeCode = mapper
+	}
+}
+
+// GoZeroEnvelopeFormatter returns a ResponseFormatter that renders errors as
+// an Envelope with the business code returned by mapper and the message of
+// the error. A nil mapper uses the code of the error. The HTTP status code is
+// the StatusCode of the error; the error ID is not part of the envelope and
+// is only sent in the header set with SetErrorIDHeader.
+func GoZeroEnvelopeFormatter(mapper EnvelopeCodeMapper) ResponseFormatter {
+	return func(appErr *errors.Error) (int, any) {
+		code := int(appErr.Code)
+		if mapper != nil {
+			code = mapper(appErr)
+		}
+		return appErr.StatusCode(), Envelope{Code: code, Msg: appErr.Message}
+	}
+}
+
+// SetGoZeroEnvelope sets a go-zero error handler that renders errors as an
+// Envelope, so that error and success responses share the same shape. Use
+// WithEnvelopeCode to map errors to business codes.
+func SetGoZeroEnvelope(opts ...Option) {
+	SetErrorHandlerWith(GoZeroEnvelopeFormatter(newOptions(opts).envelopeCode), opts...)
+}
@@ -0,0 +1,61 @@
+package interceptor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+)
+
+func TestGoZeroEnvelope(t *testing.T) {
+	SetGoZeroEnvelope(WithLogger(nopLogger))
+	t.Cleanup(func() { httpx.SetErrorHandlerCtx(nil) })
+
+	handler := HTTPErrorMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		httpx.ErrorCtx(r.Context(), w, errors.NotFound("USER_NOT_FOUND", "用户不存在"))
+	})
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("HTTP状态码应该是404，实际: %d", w.Code)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("响应不是有效的JSON: %v", err)
+	}
+	if len(got) != 2 || got["code"] != float64(404) || got["msg"] != "用户不存在" {
+		t.Errorf("响应应该只包含 code 和 msg，实际: %v", got)
+	}
+	if w.Header().Get(DefaultErrorIDHeader) == "" {
+		t.Error("错误ID应该写入响应头")
+	}
+
+	ok, err := json.Marshal(OkEnvelope(map[string]string{"name": "alice"}))
+	if err != nil || string(ok) != `{"code":0,"msg":"ok","data":{"name":"alice"}}` {
+		t.Errorf("成功响应应该使用相同的结构，实际: %s %v", ok, err)
+	}
+}
+
+func TestWithEnvelopeCode(t *testing.T) {
+	businessCodes := map[string]int{"USER_NOT_FOUND": 10404}
+	SetGoZeroEnvelope(WithLogger(nopLogger), WithEnvelopeCode(func(appErr *errors.Error) int {
+		return businessCodes[appErr.Reason]
+	}))
+	t.Cleanup(func() { httpx.SetErrorHandlerCtx(nil) })
+
+	w := httptest.NewRecorder()
+	httpx.ErrorCtx(httptest.NewRequest(http.MethodGet, "/users/1", nil).Context(), w, errors.NotFound("USER_NOT_FOUND", "用户不存在"))
+
+	var got Envelope
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("响应不是有效的JSON: %v", err)
+	}
+	if w.Code != http.StatusNotFound || got.Code != 10404 || got.Msg != "用户不存在" {
+		t.Errorf("业务码应该由映射函数决定，实际: %d %+v", w.Code, got)
+	}
+}
@@ -0,0 +1,50 @@
+// Package errorsprom counts the errors handled by the interceptors with a
+// Prometheus counter, keeping the Prometheus dependency out of the
+// interceptor package:
+//
+//	counter, err := errorsprom.Register(prometheus.DefaultRegisterer)
+//
+// The counter is labeled by code, reason and method, the full gRPC method or
+// the HTTP request path. Reasons and paths are used as is; keep their
+// cardinality bounded, e.g. by wrapping Observer to replace path parameters
+// with the route pattern.
+package errorsprom
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/interceptor"
+)
+
+// MetricName is the name of the counter created by NewCounterVec.
+const MetricName = "errors_handled_total"
+
+// NewCounterVec returns an unregistered counter of handled errors labeled by
+// code, reason and method.
+func NewCounterVec() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: MetricName,
+		Help: "Number of errors handled by the error interceptors, by code, reason and method.",
+	}, []string{"code", "reason", "method"})
+}
+
+// Observer returns a MetricsObserver that increments counter, which must have
+// the labels code, reason and method.
+func Observer(counter *prometheus.CounterVec) interceptor.MetricsObserver {
+	return func(code int, reason, method string) {
+		counter.WithLabelValues(strconv.Itoa(code), reason, method).Inc()
+	}
+}
+
+// Register registers a counter created by NewCounterVec with reg and installs
+// it as the global observer with interceptor.SetMetricsObserver.
+func Register(reg prometheus.Registerer) (*prometheus.CounterVec, error) {
+	counter := NewCounterVec()
+	if err := reg.Register(counter); err != nil {
+		return nil, err
+	}
+	interceptor.SetMetricsObserver(Observer(counter))
+	return counter, nil
+}
@@ -0,0 +1,40 @@
+package errorsprom
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+	"github.com/honeybbq/protoc-gen-go-zero-errors/interceptor"
+)
+
+func TestRegister(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	counter, err := Register(reg)
+	if err != nil {
+		t.Fatalf("注册计数器失败: %v", err)
+	}
+	t.Cleanup(func() { interceptor.SetMetricsObserver(nil) })
+
+	unary := interceptor.UnaryServerErrorInterceptor(interceptor.WithLogger(interceptor.LoggerFunc(
+		func(context.Context, interceptor.Level, string, ...any) {})))
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.v1.User/GetUser"}
+	handler := func(context.Context, any) (any, error) {
+		return nil, errors.NotFound("USER_NOT_FOUND", "用户不存在")
+	}
+	for i := 0; i < 2; i++ {
+		_, _ = unary(context.Background(), nil, info, handler)
+	}
+
+	got := testutil.ToFloat64(counter.WithLabelValues("404", "USER_NOT_FOUND", "/user.v1.User/GetUser"))
+	if got != 2 {
+		t.Errorf("计数器应该为2，实际: %v", got)
+	}
+	if _, err := Register(reg); err == nil {
+		t.Error("重复注册应该返回错误")
+	}
+}
@@ -2,66 +2,138 @@ package interceptor
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime/debug"
 
-	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
 )
 
 // UnaryServerErrorInterceptor returns a new unary server interceptor that converts
 // application-specific errors into gRPC errors using the coreerrors package.
-func UnaryServerErrorInterceptor() grpc.UnaryServerInterceptor {
-	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		resp, err := handler(ctx, req)
+func UnaryServerErrorInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
+	o := newOptions(opts)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		if o.recoverPanics {
+			defer func() {
+				if rec := recover(); rec != nil {
+					resp, err = nil, o.recoverError(ctx, "gRPC unary panic", info.FullMethod, rec)
+				}
+			}()
+		}
+
+		resp, err = handler(ctx, req)
 		if err != nil {
 			// Attempt to convert any error to our *Error type
 			// FromError is expected to handle nil, *Error already, and other error types.
 			// If err is already a gRPC status, FromError should ideally parse it back.
 			// If FromError cannot handle a specific type gracefully and returns a generic internal error,
 			// that will then be converted to a gRPC status.
-			appErr := errors.FromError(err)
+			appErr := tagMethod(o.convert(ctx, err), info.FullMethod)
 			if appErr != nil { // Should always be non-nil if err was non-nil, as FromError creates a default
 				// 确保错误有ID并记录日志
-				errorID := appErr.GetID()
-				log.Printf("gRPC unary error [ID: %s]: %v", errorID, err)
+				o.logError(ctx, "gRPC unary error", appErr, err, "method", info.FullMethod)
+				o.observe(appErr, info.FullMethod)
 
-				return resp, appErr.GRPCStatus().Err()
+				st := o.outgoing(appErr).GRPCStatus().Err()
+				o.release(err)
+				return resp, st
 			}
 			// Fallback for any unexpected scenario where appErr might be nil despite err being non-nil
 			// or if err was not convertible in a structured way by FromError.
 			// This path should ideally not be hit if FromError is robust.
-			log.Printf("unhandled error type in UnaryServerErrorInterceptor: %T, value: %v", err, err)
+			o.currentLogger().Log(ctx, LevelError, "unhandled error type in UnaryServerErrorInterceptor",
+				"type", fmt.Sprintf("%T", err), "error", err)
 			return resp, status.Error(codes.Internal, err.Error()) // Default to gRPC internal error
 		}
 		return resp, err
 	}
 }
 
-// TODO: Implement StreamServerErrorInterceptor
-// StreamServerErrorInterceptor returns a new stream server interceptor that converts errors.
-// This is more complex as it needs to wrap the grpc.ServerStream and intercept errors
-// from RecvMsg, SendMsg, and the handler's return value.
-// For a simpler first pass, it might only handle the error returned by the stream handler itself.
-
-// Example of a simplified stream interceptor that only handles the handler's final error:
-func StreamServerErrorInterceptor() grpc.StreamServerInterceptor {
+// StreamServerErrorInterceptor returns a new stream server interceptor that converts
+// application-specific errors into gRPC errors. Besides the error returned by the
+// handler, errors returned by the stream's RecvMsg and SendMsg are converted as well.
+func StreamServerErrorInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	o := newOptions(opts)
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
-		err := handler(srv, ss) // Call the original handler
+		err := handler(srv, &errorServerStream{ServerStream: ss, o: o, method: info.FullMethod}) // Call the original handler
 		if err != nil {
-			appErr := errors.FromError(err)
+			appErr := tagMethod(o.convert(ss.Context(), err), info.FullMethod)
 			if appErr != nil {
 				// 确保错误有ID并记录日志
-				errorID := appErr.GetID()
-				log.Printf("gRPC stream error [ID: %s]: %v", errorID, err)
+				o.logError(ss.Context(), "gRPC stream error", appErr, err, "method", info.FullMethod)
+				o.observe(appErr, info.FullMethod)
 
-				return appErr.GRPCStatus().Err()
+				st := o.outgoing(appErr).GRPCStatus().Err()
+				o.release(err)
+				return st
 			}
 			// Fallback
-			log.Printf("unhandled error type in StreamServerErrorInterceptor: %T, value: %v", err, err)
+			o.currentLogger().Log(ss.Context(), LevelError, "unhandled error type in StreamServerErrorInterceptor",
+				"type", fmt.Sprintf("%T", err), "error", err)
 			return status.Error(codes.Internal, err.Error()) // Default to gRPC internal error
 		}
 		return err
 	}
 }
+
+// errorServerStream wraps a grpc.ServerStream so that errors returned by
+// RecvMsg and SendMsg are converted like the handler's return value.
+type errorServerStream struct {
+	grpc.ServerStream
+	o      *options
+	method string
+}
+
+// RecvMsg converts errors other than io.EOF, which signals the end of the client stream.
+func (s *errorServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil || err == io.EOF {
+		return err
+	}
+	return s.convert(err)
+}
+
+// SendMsg converts any error returned by the underlying stream.
+func (s *errorServerStream) SendMsg(m interface{}) error {
+	if err := s.ServerStream.SendMsg(m); err != nil {
+		return s.convert(err)
+	}
+	return nil
+}
+
+// convert 将流上的错误转换为携带错误ID的gRPC错误
+func (s *errorServerStream) convert(err error) error {
+	return s.o.outgoing(tagMethod(s.o.convert(s.Context(), err), s.method)).GRPCStatus().Err()
+}
+
+// MetadataKeyGRPCMethod is the metadata key under which the gRPC interceptors
+// record the full name of the method that returned the error.
+const MetadataKeyGRPCMethod = "grpc_method"
+
+// tagMethod 在错误的metadata中记录gRPC方法名，已有的值（如下游服务记录的方法）保持不变
+func tagMethod(appErr *errors.Error, method string) *errors.Error {
+	if appErr == nil || method == "" {
+		return appErr
+	}
+	if _, ok := appErr.Metadata[MetadataKeyGRPCMethod]; ok {
+		return appErr
+	}
+	return appErr.WithMetadataKV(MetadataKeyGRPCMethod, method)
+}
+
+// PanicReason is the reason of errors created from recovered panics.
+const PanicReason = "PANIC"
+
+// recoverError 将recover得到的值转换为带ID的gRPC错误，并连同调用栈记录日志
+func (o *options) recoverError(ctx context.Context, msg, method string, rec interface{}) error {
+	appErr := tagMethod(errors.New(http.StatusInternalServerError, PanicReason, "Internal server error"), method)
+	o.logError(ctx, msg, appErr, fmt.Errorf("panic: %v", rec), "method", method, "stack", string(debug.Stack()))
+	o.observe(appErr, method)
+	return appErr.GRPCStatus().Err()
+}
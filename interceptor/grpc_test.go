@@ -0,0 +1,232 @@
+package interceptor
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	errorspb "github.com/honeybbq/go-zero-errors-proto/errors"
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+)
+
+type ctxKey struct{}
+
+// fakeServerStream 用于测试的 grpc.ServerStream，SendMsg 和 RecvMsg 返回预设的错误
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	sendErr error
+	recvErr error
+}
+
+func (s *fakeServerStream) Context() context.Context  { return s.ctx }
+func (s *fakeServerStream) SendMsg(interface{}) error { return s.sendErr }
+func (s *fakeServerStream) RecvMsg(interface{}) error { return s.recvErr }
+
+func TestStreamServerErrorInterceptorSendMsg(t *testing.T) {
+	ctx := context.WithValue(context.Background(), ctxKey{}, "v")
+	ss := &fakeServerStream{ctx: ctx, sendErr: errors.ServiceUnavailable("DOWNSTREAM_DOWN", "下游不可用"), recvErr: io.EOF}
+
+	var sendErr, recvErr error
+	interceptor := StreamServerErrorInterceptor(WithLogger(LoggerFunc(func(context.Context, Level, string, ...any) {})))
+	_ = interceptor(nil, ss, &grpc.StreamServerInfo{FullMethod: "/svc/Stream"}, func(srv interface{}, stream grpc.ServerStream) error {
+		if stream.Context() != ctx {
+			t.Error("包装后的流应该返回原始的Context")
+		}
+		recvErr = stream.RecvMsg(nil)
+		sendErr = stream.SendMsg(nil)
+		return nil
+	})
+
+	if recvErr != io.EOF {
+		t.Errorf("RecvMsg返回的io.EOF应该原样传递，实际: %v", recvErr)
+	}
+
+	st, ok := status.FromError(sendErr)
+	if !ok {
+		t.Fatalf("SendMsg的错误应该转换为gRPC状态，实际: %v", sendErr)
+	}
+	var detail *errorspb.Status
+	for _, d := range st.Details() {
+		if s, ok := d.(*errorspb.Status); ok {
+			detail = s
+			break
+		}
+	}
+	if detail == nil {
+		t.Fatalf("gRPC状态应该包含errorspb.Status详情，实际: %v", st.Details())
+	}
+	if detail.Reason != "DOWNSTREAM_DOWN" || detail.Metadata["error_id"] == "" {
+		t.Errorf("详情应该包含原因和错误ID，实际: %v", detail)
+	}
+}
+
+func TestStreamServerErrorInterceptorRecvMsg(t *testing.T) {
+	ss := &fakeServerStream{ctx: context.Background(), recvErr: stderrors.New("connection reset")}
+
+	interceptor := StreamServerErrorInterceptor(WithLogger(LoggerFunc(func(context.Context, Level, string, ...any) {})))
+	var recvErr error
+	_ = interceptor(nil, ss, &grpc.StreamServerInfo{}, func(srv interface{}, stream grpc.ServerStream) error {
+		recvErr = stream.RecvMsg(nil)
+		return nil
+	})
+
+	if appErr := errors.FromError(recvErr); appErr.Message != "connection reset" || appErr.ID == "" {
+		t.Errorf("RecvMsg的错误应该被转换并带有错误ID，实际: %v", appErr)
+	}
+}
+
+func TestUnaryServerPanicRecovery(t *testing.T) {
+	logger := &captureLogger{}
+	interceptor := UnaryServerErrorInterceptor(WithPanicRecovery(), WithLogger(logger))
+
+	resp, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/svc/Panic"},
+		func(ctx context.Context, req interface{}) (interface{}, error) { panic("boom") })
+
+	if resp != nil {
+		t.Errorf("发生panic时不应该返回响应，实际: %v", resp)
+	}
+	if status.Code(err) != codes.Internal {
+		t.Errorf("gRPC状态码应该是Internal，实际: %v", status.Code(err))
+	}
+	appErr := errors.FromError(err)
+	if appErr.Code != 500 || appErr.Reason != PanicReason || appErr.ID == "" {
+		t.Errorf("应该返回带ID的PANIC错误，实际: %v", appErr)
+	}
+	if stack, _ := logger.keyvals["stack"].(string); !strings.Contains(stack, "TestUnaryServerPanicRecovery") {
+		t.Errorf("日志应该包含panic的调用栈，实际: %v", logger.keyvals)
+	}
+	if logger.keyvals["error_id"] != appErr.ID {
+		t.Errorf("日志中的错误ID应该与返回的一致，实际: %v", logger.keyvals["error_id"])
+	}
+}
+
+func TestUnaryServerPanicWithoutRecovery(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("默认情况下panic应该继续向上传播")
+		}
+	}()
+	interceptor := UnaryServerErrorInterceptor()
+	_, _ = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/svc/Panic"},
+		func(ctx context.Context, req interface{}) (interface{}, error) { panic("boom") })
+}
+
+func TestWithSanitizeServerErrors(t *testing.T) {
+	logger := &captureLogger{}
+	unary := UnaryServerErrorInterceptor(WithSanitizeServerErrors(), WithLogger(logger))
+	info := &grpc.UnaryServerInfo{FullMethod: "/svc/Get"}
+
+	internal := errors.InternalServer("DB_ERROR", "pq: relation \"users\" does not exist").
+		WithCause(errors.New(500, "SQL", "SELECT * FROM users"))
+	_, err := unary(context.Background(), nil, info, func(context.Context, interface{}) (interface{}, error) {
+		return nil, internal
+	})
+
+	st := status.Convert(err)
+	want := SanitizedMessage + " (id: " + internal.ID + ")"
+	if st.Message() != want {
+		t.Errorf("发给客户端的消息应该是 %q，实际: %q", want, st.Message())
+	}
+	got := errors.FromError(err)
+	if got.Message != want || got.Reason != "DB_ERROR" || got.ID != internal.ID || errors.Unwrap(got) != nil {
+		t.Errorf("应该只隐藏消息和cause链，实际: %+v cause=%v", got.Status, errors.Unwrap(got))
+	}
+	if logged := fmt.Sprint(logger.keyvals["error"]); !strings.Contains(logged, "does not exist") {
+		t.Errorf("日志应该记录原始消息，实际: %s", logged)
+	}
+
+	_, err = unary(context.Background(), nil, info, func(context.Context, interface{}) (interface{}, error) {
+		return nil, errors.NotFound("USER_NOT_FOUND", "用户不存在")
+	})
+	if msg := status.Convert(err).Message(); msg != "用户不存在" {
+		t.Errorf("4xx错误的消息应该保持不变，实际: %q", msg)
+	}
+
+	// 业务码 40401 以404发送，按HTTP状态码判断为客户端错误
+	_, err = unary(context.Background(), nil, info, func(context.Context, interface{}) (interface{}, error) {
+		return nil, errors.New(40401, "USER_NOT_FOUND", "用户不存在").WithHTTPStatus(404)
+	})
+	if msg := status.Convert(err).Message(); msg != "用户不存在" {
+		t.Errorf("HTTP状态码为404的错误的消息应该保持不变，实际: %q", msg)
+	}
+}
+
+func TestCauseSerializationGRPC(t *testing.T) {
+	logger := &captureLogger{}
+	chain := errors.BadRequest("CREATE_FAILED", "创建失败").WithCause(
+		errors.InternalServer("DB_ERROR", "dial tcp 10.0.0.5:5432: connection refused"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/svc/Create"}
+	handler := func(context.Context, interface{}) (interface{}, error) { return nil, chain }
+
+	_, err := UnaryServerErrorInterceptor(WithLogger(logger))(context.Background(), nil, info, handler)
+	if got := errors.FromError(err); got.Reason != "CREATE_FAILED" || got.Message != "创建失败" || errors.Unwrap(got) != nil {
+		t.Errorf("默认不应该向客户端发送cause链，实际: %+v cause=%v", got.Status, errors.Unwrap(got))
+	}
+	if len(status.Convert(err).Details()) != 1 {
+		t.Errorf("默认只应该包含错误本身的详情，实际: %v", status.Convert(err).Details())
+	}
+	if logged := fmt.Sprint(logger.keyvals["error"]); !strings.Contains(logged, "connection refused") {
+		t.Errorf("日志应该记录完整的cause链，实际: %s", logged)
+	}
+	if errors.Unwrap(chain) == nil {
+		t.Error("不应该修改处理函数返回的错误")
+	}
+
+	ss := &fakeServerStream{ctx: context.Background(), sendErr: chain}
+	_ = StreamServerErrorInterceptor(WithLogger(nopLogger))(nil, ss, &grpc.StreamServerInfo{FullMethod: "/svc/Stream"},
+		func(_ interface{}, stream grpc.ServerStream) error {
+			err = stream.SendMsg(nil)
+			return nil
+		})
+	if cause := errors.Unwrap(errors.FromError(err)); cause != nil {
+		t.Errorf("流上的错误默认也不应该包含cause链，实际: %v", cause)
+	}
+
+	_, err = UnaryServerErrorInterceptor(WithCauseSerialization(), WithLogger(nopLogger))(context.Background(), nil, info, handler)
+	if causes := errors.Causes(errors.FromError(err)); len(causes) != 1 || causes[0].Reason != "DB_ERROR" {
+		t.Errorf("WithCauseSerialization 应该发送cause链，实际: %+v", causes)
+	}
+}
+
+func TestGRPCMethodMetadata(t *testing.T) {
+	quiet := WithLogger(LoggerFunc(func(context.Context, Level, string, ...any) {}))
+
+	unary := UnaryServerErrorInterceptor(quiet)
+	_, err := unary(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/user.v1.User/Get"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, errors.NotFound("USER_NOT_FOUND", "用户不存在")
+		})
+	if got, _ := errors.MetadataValue(err, MetadataKeyGRPCMethod); got != "/user.v1.User/Get" {
+		t.Errorf("一元拦截器应该记录方法名，实际: %q", got)
+	}
+
+	// 下游服务已经记录的方法名不应该被覆盖
+	_, err = unary(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/user.v1.User/Get"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, errors.NotFound("ORDER_NOT_FOUND", "订单不存在").WithMetadataKV(MetadataKeyGRPCMethod, "/order.v1.Order/Get")
+		})
+	if got, _ := errors.MetadataValue(err, MetadataKeyGRPCMethod); got != "/order.v1.Order/Get" {
+		t.Errorf("已有的方法名应该保留，实际: %q", got)
+	}
+
+	ss := &fakeServerStream{ctx: context.Background(), sendErr: errors.ServiceUnavailable("DOWNSTREAM_DOWN", "下游不可用")}
+	var sendErr error
+	stream := StreamServerErrorInterceptor(quiet)
+	err = stream(nil, ss, &grpc.StreamServerInfo{FullMethod: "/user.v1.User/Watch"}, func(srv interface{}, stream grpc.ServerStream) error {
+		sendErr = stream.SendMsg(nil)
+		return errors.InternalServer("WATCH_FAILED", "监听失败")
+	})
+	for name, e := range map[string]error{"handler": err, "SendMsg": sendErr} {
+		if got, _ := errors.MetadataValue(e, MetadataKeyGRPCMethod); got != "/user.v1.User/Watch" {
+			t.Errorf("流拦截器应该为 %s 的错误记录方法名，实际: %q", name, got)
+		}
+	}
+}
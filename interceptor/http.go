@@ -1,6 +1,7 @@
 package interceptor
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
@@ -10,8 +11,72 @@ import (
 // ErrorResponseHandler is a custom error handler for go-zero HTTP routes.
 // It should be registered with httpx.SetErrorHandler to replace the default error handling.
 func ErrorResponseHandler(err error) (int, interface{}) {
-	// Convert any error to our structured error format
-	appErr := errors.FromError(err)
+	return newOptions(nil).errorResponse(context.Background(), errors.FromError(err), err)
+}
+
+// NewErrorHandler returns an error handler for httpx.SetErrorHandlerCtx that
+// behaves like ErrorResponseHandler and additionally applies opts using the
+// request context.
+func NewErrorHandler(opts ...Option) func(ctx context.Context, err error) (int, interface{}) {
+	o := newOptions(opts)
+	return func(ctx context.Context, err error) (int, interface{}) {
+		code, body := o.errorResponse(ctx, o.convert(ctx, err), err)
+		// 自定义格式的响应体可能引用错误本身，不能释放
+		if o.formatter == nil {
+			o.release(err)
+		}
+		return code, body
+	}
+}
+
+// ErrorResponseHandlerCtx is like ErrorResponseHandler but has access to the
+// request: the message is localized by its Accept-Language header and metrics
+// are labeled with its path. When the request went through
+// AcceptLanguageMiddleware, the error ID header is set as well. A nil r
+// behaves like ErrorResponseHandler. Register it with SetRequestErrorHandler.
+func ErrorResponseHandlerCtx(r *http.Request, err error) (int, interface{}) {
+	return NewRequestErrorHandler()(r, err)
+}
+
+// NewRequestErrorHandler returns an error handler like ErrorResponseHandlerCtx
+// that additionally applies opts.
+func NewRequestErrorHandler(opts ...Option) func(r *http.Request, err error) (int, interface{}) {
+	handler := NewErrorHandler(opts...)
+	return func(r *http.Request, err error) (int, interface{}) {
+		if r == nil {
+			return handler(context.Background(), err)
+		}
+		return handler(requestContext(r), err)
+	}
+}
+
+// SetRequestErrorHandler registers handler as the go-zero error handler.
+// go-zero only passes the context to error handlers, so handler receives the
+// request stored by AcceptLanguageMiddleware, XMLErrorMiddleware or
+// HTTPErrorMiddleware, with the context of the error handler; on routes not
+// wrapped by any of them r is nil.
+func SetRequestErrorHandler(handler func(r *http.Request, err error) (int, interface{})) {
+	httpx.SetErrorHandlerCtx(func(ctx context.Context, err error) (int, interface{}) {
+		r := requestFromContext(ctx)
+		if r != nil {
+			r = r.WithContext(ctx)
+		}
+		return handler(r, err)
+	})
+}
+
+// requestContext 返回保存了请求的上下文，经过中间件的请求直接使用其上下文
+func requestContext(r *http.Request) context.Context {
+	ctx := r.Context()
+	if exchangeFromContext(ctx) == nil {
+		ctx = context.WithValue(ctx, exchangeKey{}, &exchange{r: r})
+	}
+	return ctx
+}
+
+// errorResponse logs appErr, which was converted from err, and builds the HTTP
+// status code and JSON body for it.
+func (o *options) errorResponse(ctx context.Context, appErr *errors.Error, err error) (int, interface{}) {
 	if appErr == nil {
 		// This should not happen as FromError always returns a non-nil *Error,
 		// but handle it gracefully just in case.
@@ -23,55 +88,194 @@ func ErrorResponseHandler(err error) (int, interface{}) {
 		}
 	}
 
+	o.logError(ctx, "HTTP error", appErr, err)
+	o.observe(appErr, requestPath(ctx))
+	// go-zero 在处理器返回后才写入响应，此时设置的响应头仍然有效
+	if ex := exchangeFromContext(ctx); ex != nil && ex.w != nil {
+		setErrorIDHeader(ex.w, appErr.GetID())
+	}
+
+	localized := localize(ctx, appErr)
+	// 客户端偏好XML时由 XMLErrorMiddleware 包装的 ResponseWriter 写入XML响应体
+	if ex := exchangeFromContext(ctx); o.xmlNegotiation && ex != nil && ex.w != nil && prefersXML(ex.r) {
+		if xw := findXMLResponseWriter(ex.w); xw != nil {
+			code, body := marshalXML(localized)
+			xw.body = body
+			return code, nil
+		}
+	}
+
+	// Return the HTTP status code and the structured error response
+	return o.format(localized)
+}
+
+// ResponseFormatter builds the HTTP status code and response body for an error.
+type ResponseFormatter func(*errors.Error) (int, any)
+
+// DefaultResponseFormatter is the ResponseFormatter used unless another one is
+// configured. The status code is the StatusCode of the error and the body
+// holds its code, reason, message, metadata and id; metadata is omitted when
+// empty.
+func DefaultResponseFormatter(appErr *errors.Error) (int, any) {
+	return appErr.StatusCode(), newOptions(nil).errorBody(appErr)
+}
+
+// format 使用配置的 ResponseFormatter 构建响应，未配置时使用默认格式
+func (o *options) format(appErr *errors.Error) (int, any) {
+	if o.formatter != nil {
+		return o.formatter(appErr)
+	}
+	return appErr.StatusCode(), o.errorBody(appErr)
+}
+
+// errorBody builds the structured JSON body for appErr.
+func (o *options) errorBody(appErr *errors.Error) map[string]interface{} {
 	// 确保错误有ID
 	errorID := appErr.GetID()
 
-	// Return the HTTP status code and the structured error response
-	return int(appErr.Code), map[string]interface{}{
-		"code":     appErr.Code,
-		"reason":   appErr.Reason,
-		"message":  appErr.Message,
-		"metadata": appErr.Metadata,
-		"id":       errorID,
+	body := map[string]interface{}{
+		"code":    appErr.Code,
+		"reason":  appErr.Reason,
+		"message": appErr.Message,
+		"id":      errorID,
+	}
+	if domain := appErr.GetDomain(); domain != "" {
+		body["domain"] = domain
+	}
+	if metadata := appErr.RedactedMetadata(); len(metadata) > 0 {
+		body["metadata"] = metadata
+	}
+
+	// errors.Join 聚合的错误，逐个输出子错误
+	if children := appErr.Errors(); len(children) > 0 {
+		details := make([]map[string]interface{}, 0, len(children))
+		for _, child := range children {
+			details = append(details, o.errorBody(errors.FromError(child)))
+		}
+		body["details"] = details
+	}
+
+	if o.serializeCauses {
+		if causes := errors.Causes(appErr); len(causes) > 0 {
+			list := make([]map[string]interface{}, 0, len(causes))
+			for _, c := range causes {
+				// 非 *Error 的cause只有消息，不输出零值的 code 和 reason
+				entry := map[string]interface{}{"message": c.Message}
+				if c.Code != 0 {
+					entry["code"] = c.Code
+				}
+				if c.Reason != "" {
+					entry["reason"] = c.Reason
+				}
+				list = append(list, entry)
+			}
+			body["causes"] = list
+		}
 	}
+	return body
 }
 
 // HTTPErrorMiddleware is a middleware that automatically handles error responses
 // for go-zero HTTP handlers. It wraps the handler and converts any returned errors
-// into structured JSON responses using the coreerrors package.
+// into structured JSON responses using the coreerrors package. When the request
+// context has expired or been canceled by the time the handler fails, server
+// errors are reported as 504 DEADLINE_EXCEEDED or 499 CONTEXT_CANCELED, with
+// the original error as the cause.
 func HTTPErrorMiddleware(next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		defer func() {
-			if rec := recover(); rec != nil {
-				// Handle panics and convert them to errors
-				var err error
-				if e, ok := rec.(error); ok {
-					err = e
-				} else {
-					err = errors.New(http.StatusInternalServerError, errors.UnknownReason, "Internal server error")
+	return HTTPErrorMiddlewareWith()(next)
+}
+
+// HTTPErrorMiddlewareWith returns an HTTPErrorMiddleware that applies opts
+// using the request context.
+func HTTPErrorMiddlewareWith(opts ...Option) func(next http.HandlerFunc) http.HandlerFunc {
+	o := newOptions(opts)
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			r = withRequest(w, r)
+			defer func() {
+				if rec := recover(); rec != nil {
+					// Handle panics and convert them to errors
+					var err error
+					if e, ok := rec.(error); ok {
+						err = e
+					} else {
+						err = errors.New(http.StatusInternalServerError, errors.UnknownReason, "Internal server error")
+					}
+
+					o.writeError(w, r, "HTTP panic", err)
 				}
+			}()
 
-				appErr := errors.FromError(err)
-				errorID := appErr.GetID()
-
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(int(appErr.Code))
-				httpx.WriteJson(w, int(appErr.Code), map[string]interface{}{
-					"code":     appErr.Code,
-					"reason":   appErr.Reason,
-					"message":  appErr.Message,
-					"metadata": appErr.Metadata,
-					"id":       errorID,
-				})
+			next.ServeHTTP(w, r)
+		}
+	}
+}
+
+// HTTPErrorMiddlewareE adapts a handler that returns an error to an
+// http.HandlerFunc. A non-nil error returned by next is converted with
+// errors.FromError and written as the structured JSON response with the
+// status code of the error, exactly like the errors handled by
+// SetDefaultErrorHandler; panics and errors returned after the request
+// context expired are handled like HTTPErrorMiddleware. next must not have
+// written to w when it returns an error.
+func HTTPErrorMiddlewareE(next func(http.ResponseWriter, *http.Request) error) http.HandlerFunc {
+	return HTTPErrorMiddlewareEWith()(next)
+}
+
+// HTTPErrorMiddlewareEWith returns an HTTPErrorMiddlewareE that applies opts
+// using the request context.
+func HTTPErrorMiddlewareEWith(opts ...Option) func(next func(http.ResponseWriter, *http.Request) error) http.HandlerFunc {
+	o := newOptions(opts)
+	return func(next func(http.ResponseWriter, *http.Request) error) http.HandlerFunc {
+		return HTTPErrorMiddlewareWith(opts...)(func(w http.ResponseWriter, r *http.Request) {
+			if err := next(w, r); err != nil {
+				o.writeError(w, r, "HTTP error", err)
+				// 自定义格式的响应体可能引用错误本身，不能释放
+				if o.formatter == nil {
+					o.release(err)
+				}
 			}
-		}()
+		})
+	}
+}
 
-		next.ServeHTTP(w, r)
+// writeError 转换并记录错误，然后写入结构化的JSON响应
+func (o *options) writeError(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	appErr := o.convert(r.Context(), contextError(r.Context(), err))
+	o.logError(r.Context(), msg, appErr, err, "method", r.Method, "path", r.URL.Path)
+	o.observe(appErr, r.URL.Path)
+
+	setErrorIDHeader(w, appErr.GetID())
+	localized := localize(r.Context(), appErr)
+	if o.xmlNegotiation && prefersXML(r) {
+		writeXML(w, localized)
+		return
 	}
+	code, body := o.format(localized)
+	httpx.WriteJson(w, code, body)
+}
+
+// contextError 请求上下文已超时或被取消时，处理器返回的服务端错误多半由此导致，
+// 替换为对应的504或499错误并保留原错误作为cause；客户端错误是处理器有意返回的，保持不变
+func contextError(ctx context.Context, err error) error {
+	ctxErr := ctx.Err()
+	if ctxErr == nil || errors.Is(err, ctxErr) {
+		return err
+	}
+	if appErr := new(errors.Error); errors.As(err, &appErr) && !appErr.IsServerError() {
+		return err
+	}
+	return errors.FromError(ctxErr).WithCause(err)
 }
 
 // SetDefaultErrorHandler sets the default error handler for go-zero HTTP server.
 // Call this once during server initialization.
-func SetDefaultErrorHandler() {
-	httpx.SetErrorHandler(ErrorResponseHandler)
+func SetDefaultErrorHandler(opts ...Option) {
+	httpx.SetErrorHandlerCtx(NewErrorHandler(opts...))
+}
+
+// SetErrorHandlerWith is like SetDefaultErrorHandler but renders responses with
+// formatter, so that the response body can match an existing API contract.
+func SetErrorHandlerWith(formatter ResponseFormatter, opts ...Option) {
+	SetDefaultErrorHandler(append(opts, WithResponseFormatter(formatter))...)
 }
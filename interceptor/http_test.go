@@ -0,0 +1,248 @@
+package interceptor
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"google.golang.org/grpc"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+)
+
+func TestErrorResponseHandlerJoinDetails(t *testing.T) {
+	joined := errors.Join(
+		errors.BadRequest("INVALID_EMAIL", "邮箱格式错误"),
+		stderrors.New("name is required"),
+	)
+
+	code, body := ErrorResponseHandler(joined)
+	if code != 500 {
+		t.Errorf("HTTP状态码应该是500，实际: %d", code)
+	}
+
+	details, ok := body.(map[string]interface{})["details"].([]map[string]interface{})
+	if !ok || len(details) != 2 {
+		t.Fatalf("响应应该包含2个子错误，实际: %v", body)
+	}
+	if details[0]["reason"] != "INVALID_EMAIL" || details[0]["code"] != int32(400) {
+		t.Errorf("第一个子错误应该是INVALID_EMAIL，实际: %v", details[0])
+	}
+	if details[1]["message"] != "name is required" {
+		t.Errorf("第二个子错误应该保留普通错误的消息，实际: %v", details[1])
+	}
+
+	_, body = ErrorResponseHandler(errors.BadRequest("BAD", "无效请求"))
+	if _, ok := body.(map[string]interface{})["details"]; ok {
+		t.Error("非聚合错误不应该输出details")
+	}
+}
+
+func TestWithCauseSerializationHTTP(t *testing.T) {
+	chain := errors.BadRequest("CREATE_FAILED", "创建失败").WithCause(
+		errors.ServiceUnavailable("REPO_UNAVAILABLE", "仓储不可用").WithCause(
+			stderrors.New("connection refused")))
+
+	_, body := NewErrorHandler(WithCauseSerialization())(context.Background(), chain)
+	causes, ok := body.(map[string]interface{})["causes"].([]map[string]interface{})
+	if !ok || len(causes) != 2 {
+		t.Fatalf("响应应该包含2层cause，实际: %v", body)
+	}
+	if causes[0]["reason"] != "REPO_UNAVAILABLE" || causes[0]["code"] != int32(503) {
+		t.Errorf("第一层cause不正确，实际: %v", causes[0])
+	}
+	if causes[1]["message"] != "connection refused" {
+		t.Errorf("第二层cause应该保留消息，实际: %v", causes[1])
+	}
+
+	_, body = ErrorResponseHandler(chain)
+	if _, ok := body.(map[string]interface{})["causes"]; ok {
+		t.Error("默认不应该输出causes")
+	}
+}
+
+func TestDefaultResponseFormatterOmitsEmptyMetadata(t *testing.T) {
+	_, body := DefaultResponseFormatter(errors.BadRequest("BAD", "无效请求"))
+	if _, ok := body.(map[string]interface{})["metadata"]; ok {
+		t.Errorf("metadata为空时不应该输出，实际: %v", body)
+	}
+
+	_, body = DefaultResponseFormatter(errors.BadRequest("BAD", "无效请求").WithMetadata(map[string]string{"field": "email"}))
+	if md, _ := body.(map[string]interface{})["metadata"].(map[string]string); md["field"] != "email" {
+		t.Errorf("metadata不为空时应该输出，实际: %v", body)
+	}
+}
+
+func TestSetErrorHandlerWith(t *testing.T) {
+	envelope := func(e *errors.Error) (int, any) {
+		return int(e.Code), map[string]any{"error": map[string]any{"code": e.Reason, "msg": e.Message}}
+	}
+	SetErrorHandlerWith(envelope)
+	t.Cleanup(func() { httpx.SetErrorHandlerCtx(nil) })
+
+	w := httptest.NewRecorder()
+	httpx.ErrorCtx(context.Background(), w, errors.NotFound("USER_NOT_FOUND", "用户不存在"))
+
+	if w.Code != 404 {
+		t.Errorf("HTTP状态码应该是404，实际: %d", w.Code)
+	}
+	var got map[string]map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("响应不是有效的JSON: %v", err)
+	}
+	if got["error"]["code"] != "USER_NOT_FOUND" || got["error"]["msg"] != "用户不存在" {
+		t.Errorf("响应应该使用自定义格式，实际: %s", w.Body.String())
+	}
+}
+
+func TestErrorResponseRedactsMetadata(t *testing.T) {
+	errors.SetRedactedMetadataKeys("authorization")
+	defer errors.SetRedactedMetadataKeys()
+
+	appErr := errors.Unauthorized("TOKEN_INVALID", "令牌无效").WithMetadata(map[string]string{
+		"Authorization": "Bearer secret-token",
+		"tenant":        "acme",
+	})
+
+	_, body := ErrorResponseHandler(appErr)
+	metadata := body.(map[string]interface{})["metadata"].(map[string]string)
+	if metadata["Authorization"] != errors.RedactedValue || metadata["tenant"] != "acme" {
+		t.Errorf("HTTP响应中的敏感metadata应该被脱敏，实际: %v", metadata)
+	}
+
+	_, problem := ProblemJSONFormatter(appErr)
+	if got := problem.(ProblemDetails).Metadata["Authorization"]; got != errors.RedactedValue {
+		t.Errorf("Problem Details中的敏感metadata应该被脱敏，实际: %v", got)
+	}
+
+	if appErr.Metadata["Authorization"] != "Bearer secret-token" {
+		t.Errorf("内存中的metadata不应该被修改，实际: %v", appErr.Metadata)
+	}
+}
+
+func TestHTTPErrorMiddlewareE(t *testing.T) {
+	handler := HTTPErrorMiddlewareEWith(WithLogger(nopLogger))(func(w http.ResponseWriter, r *http.Request) error {
+		if r.URL.Query().Get("id") == "1" {
+			w.WriteHeader(http.StatusNoContent)
+			return nil
+		}
+		return fmt.Errorf("load user: %w", errors.NotFound("USER_NOT_FOUND", "用户不存在"))
+	})
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/users?id=2", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("状态码应该是404，实际: %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("Content-Type应该是JSON，实际: %s", ct)
+	}
+	var got struct {
+		Code    int32  `json:"code"`
+		Reason  string `json:"reason"`
+		Message string `json:"message"`
+		ID      string `json:"id"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("响应不是有效的JSON: %v", err)
+	}
+	if got.Code != 404 || got.Reason != "USER_NOT_FOUND" || got.Message != "用户不存在" || got.ID == "" {
+		t.Errorf("响应体应该包含结构化的错误，实际: %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/users?id=1", nil))
+	if w.Code != http.StatusNoContent || w.Body.Len() != 0 {
+		t.Errorf("没有错误时应该保留处理函数的响应，实际: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestCauseChainOrder(t *testing.T) {
+	chain := errors.InternalServer("CREATE_FAILED", "创建失败").WithCause(
+		errors.ServiceUnavailable("REPO_UNAVAILABLE", "仓储不可用").WithCause(
+			errors.GatewayTimeout("DB_TIMEOUT", "数据库超时").WithCause(
+				stderrors.New("i/o timeout"))))
+	want := []string{"仓储不可用", "数据库超时", "i/o timeout"}
+
+	_, body := NewErrorHandler(WithCauseSerialization(), WithLogger(nopLogger))(context.Background(), chain)
+	causes, _ := body.(map[string]interface{})["causes"].([]map[string]interface{})
+	if len(causes) != len(want) {
+		t.Fatalf("HTTP响应应该包含 %d 层cause，实际: %v", len(want), causes)
+	}
+	for i, msg := range want {
+		if causes[i]["message"] != msg {
+			t.Errorf("HTTP响应第 %d 层cause应该是 %q，实际: %v", i, msg, causes[i])
+		}
+	}
+	if _, ok := causes[2]["code"]; ok {
+		t.Errorf("非 *Error 的cause不应该输出code，实际: %v", causes[2])
+	}
+
+	unary := UnaryServerErrorInterceptor(WithCauseSerialization(), WithLogger(nopLogger))
+	_, err := unary(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/svc/Create"},
+		func(context.Context, interface{}) (interface{}, error) { return nil, chain })
+	got := errors.Causes(errors.FromError(err))
+	if len(got) != len(want) {
+		t.Fatalf("gRPC错误应该包含 %d 层cause，实际: %v", len(want), got)
+	}
+	for i, msg := range want {
+		if got[i].Message != msg {
+			t.Errorf("gRPC错误第 %d 层cause应该是 %q，实际: %+v", i, msg, got[i])
+		}
+	}
+	if got[1].Reason != "DB_TIMEOUT" || got[1].Code != 504 {
+		t.Errorf("*Error 类型的cause应该保留code和reason，实际: %+v", got[1])
+	}
+}
+
+func TestHTTPErrorMiddlewareContextDeadline(t *testing.T) {
+	serve := func(ctx context.Context, handlerErr error) (int, map[string]any) {
+		handler := HTTPErrorMiddlewareEWith(WithLogger(nopLogger))(func(_ http.ResponseWriter, r *http.Request) error {
+			<-r.Context().Done() // 处理器执行期间请求上下文到期
+			return handlerErr
+		})
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodGet, "/reports", nil).WithContext(ctx))
+		var body map[string]any
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("响应不是有效的JSON: %v", err)
+		}
+		return w.Code, body
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	code, body := serve(ctx, stderrors.New("query failed"))
+	if code != http.StatusGatewayTimeout || body["reason"] != errors.DeadlineExceededReason {
+		t.Errorf("请求超时后的服务端错误应该映射为504，实际: %d %v", code, body)
+	}
+
+	canceled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	code, body = serve(canceled, errors.InternalServer("DB_ERROR", "数据库错误"))
+	if code != 499 || body["reason"] != errors.ContextCanceledReason {
+		t.Errorf("请求取消后的服务端错误应该映射为499，实际: %d %v", code, body)
+	}
+
+	code, body = serve(canceled, errors.NotFound("REPORT_NOT_FOUND", "报表不存在"))
+	if code != http.StatusNotFound || body["reason"] != "REPORT_NOT_FOUND" {
+		t.Errorf("客户端错误应该保持不变，实际: %d %v", code, body)
+	}
+}
+
+func TestHTTPStatusOverride(t *testing.T) {
+	code, body := ErrorResponseHandler(errors.New(40401, "USER_NOT_FOUND", "用户不存在").WithHTTPStatus(http.StatusNotFound))
+	if code != http.StatusNotFound {
+		t.Errorf("HTTP状态码应该是404，实际: %d", code)
+	}
+	if got := body.(map[string]interface{})["code"]; got != int32(40401) {
+		t.Errorf("响应体应该保留业务码40401，实际: %v", got)
+	}
+}
@@ -0,0 +1,61 @@
+package interceptor
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+)
+
+// localize 按 Accept-Language 中的语言优先级本地化错误消息，没有匹配的翻译时保持原消息
+func localize(ctx context.Context, appErr *errors.Error) *errors.Error {
+	r := requestFromContext(ctx)
+	if r == nil || !errors.HasMessages() {
+		return appErr
+	}
+	header := r.Header.Get("Accept-Language")
+	if header == "" {
+		return appErr
+	}
+	for _, lang := range parseAcceptLanguage(header) {
+		if _, ok := errors.LookupMessage(appErr.Reason, lang); ok {
+			return errors.Localize(appErr, lang)
+		}
+	}
+	return appErr
+}
+
+// parseAcceptLanguage 解析 Accept-Language，按权重从高到低返回语言标签
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		lang string
+		q    float64
+	}
+	var langs []weighted
+	for _, part := range strings.Split(header, ",") {
+		lang, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if lang == "" || lang == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			langs = append(langs, weighted{lang, q})
+		}
+	}
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].q > langs[j].q })
+
+	result := make([]string, len(langs))
+	for i, l := range langs {
+		result[i] = l.lang
+	}
+	return result
+}
@@ -0,0 +1,79 @@
+package interceptor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	got := parseAcceptLanguage("fr;q=0.5, zh-CN, en;q=0.8, *;q=0.1, de;q=0")
+	want := []string{"zh-CN", "en", "fr"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("语言优先级应该是 %v，实际: %v", want, got)
+	}
+}
+
+func TestHTTPErrorLocalization(t *testing.T) {
+	errors.RegisterMessages("zh", map[string]string{"I18N_ORDER_NOT_FOUND": "订单 {order_id} 不存在"})
+
+	appErr := errors.NotFound("I18N_ORDER_NOT_FOUND", "order not found").
+		WithMetadata(map[string]string{"order_id": "A1"})
+
+	var ctx context.Context
+	handler := AcceptLanguageMiddleware(func(_ http.ResponseWriter, r *http.Request) { ctx = r.Context() })
+	for header, want := range map[string]string{
+		"fr, zh-CN;q=0.9": "订单 A1 不存在",
+		"fr":              "order not found",
+		"":                "order not found",
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/orders/A1", nil)
+		if header != "" {
+			r.Header.Set("Accept-Language", header)
+		}
+		handler(httptest.NewRecorder(), r)
+
+		_, body := NewErrorHandler()(ctx, appErr)
+		if got := body.(map[string]interface{})["message"]; got != want {
+			t.Errorf("Accept-Language %q 的消息应该是 %q，实际: %v", header, want, got)
+		}
+	}
+}
+
+func TestErrorResponseHandlerCtx(t *testing.T) {
+	errors.RegisterMessages("zh", map[string]string{"I18N_CART_EMPTY": "购物车为空"})
+	appErr := errors.BadRequest("I18N_CART_EMPTY", "cart is empty")
+
+	for header, want := range map[string]string{"zh-CN": "购物车为空", "en": "cart is empty"} {
+		r := httptest.NewRequest(http.MethodPost, "/checkout", nil)
+		r.Header.Set("Accept-Language", header)
+		_, body := ErrorResponseHandlerCtx(r, appErr)
+		if got := body.(map[string]interface{})["message"]; got != want {
+			t.Errorf("Accept-Language %q 的消息应该是 %q，实际: %v", header, want, got)
+		}
+	}
+	if _, body := ErrorResponseHandlerCtx(nil, appErr); body.(map[string]interface{})["message"] != "cart is empty" {
+		t.Errorf("没有请求时应该使用原始消息，实际: %v", body)
+	}
+
+	// 通过 go-zero 注册时，处理器从中间件保存的请求中取得 Accept-Language
+	SetRequestErrorHandler(ErrorResponseHandlerCtx)
+	t.Cleanup(func() { httpx.SetErrorHandlerCtx(nil) })
+
+	handler := AcceptLanguageMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		httpx.ErrorCtx(r.Context(), w, appErr)
+	})
+	r := httptest.NewRequest(http.MethodPost, "/checkout", nil)
+	r.Header.Set("Accept-Language", "zh")
+	w := httptest.NewRecorder()
+	handler(w, r)
+	if !strings.Contains(w.Body.String(), "购物车为空") || w.Header().Get(DefaultErrorIDHeader) != appErr.GetID() {
+		t.Errorf("响应应该使用本地化消息并带有错误ID响应头，实际: %s %v", w.Body.String(), w.Header())
+	}
+}
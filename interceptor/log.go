@@ -0,0 +1,188 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+)
+
+// Level is the severity at which an error is logged.
+type Level int
+
+// Log levels, from least to most severe.
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+	LevelCritical
+)
+
+// String returns the upper-case name of the level.
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	case LevelCritical:
+		return "CRITICAL"
+	default:
+		return fmt.Sprintf("LEVEL(%d)", int(l))
+	}
+}
+
+// Logger receives the errors handled by the interceptors. keyvals holds
+// alternating keys and values.
+type Logger interface {
+	Log(ctx context.Context, level Level, msg string, keyvals ...any)
+}
+
+// LoggerFunc adapts a function to a Logger.
+type LoggerFunc func(ctx context.Context, level Level, msg string, keyvals ...any)
+
+// Log calls f(ctx, level, msg, keyvals...).
+func (f LoggerFunc) Log(ctx context.Context, level Level, msg string, keyvals ...any) {
+	f(ctx, level, msg, keyvals...)
+}
+
+// stdLogger 默认的日志实现，以 key=value 形式输出到标准库log
+type stdLogger struct{}
+
+func (stdLogger) Log(_ context.Context, level Level, msg string, keyvals ...any) {
+	var b strings.Builder
+	for i := 0; i < len(keyvals); i += 2 {
+		b.WriteByte(' ')
+		if i+1 < len(keyvals) {
+			fmt.Fprintf(&b, "%v=%v", keyvals[i], keyvals[i+1])
+		} else {
+			fmt.Fprintf(&b, "%v", keyvals[i])
+		}
+	}
+	log.Printf("[%s] %s%s", level, msg, b.String())
+}
+
+var defaultLogger atomic.Pointer[Logger]
+
+// SetLogger sets the logger used by interceptors and handlers created without
+// WithLogger. Passing nil restores the default logger, which writes to the
+// standard library log package.
+func SetLogger(logger Logger) {
+	if logger == nil {
+		defaultLogger.Store(nil)
+		return
+	}
+	defaultLogger.Store(&logger)
+}
+
+// WithLogger sets the logger that receives handled errors, overriding the one
+// set with SetLogger.
+func WithLogger(logger Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// currentLogger 返回选项中的日志实现，未设置时使用全局日志实现
+func (o *options) currentLogger() Logger {
+	if o.logger != nil {
+		return o.logger
+	}
+	if l := defaultLogger.Load(); l != nil {
+		return *l
+	}
+	return stdLogger{}
+}
+
+// WithLogFilter only logs errors for which filter returns true. It affects
+// logging only; the returned error is unchanged. When several filters are
+// configured, an error is logged only if all of them return true.
+func WithLogFilter(filter func(code int, reason string) bool) Option {
+	return func(o *options) {
+		if filter != nil {
+			o.logFilters = append(o.logFilters, filter)
+		}
+	}
+}
+
+// WithSuppressCodes suppresses logging of errors with the given codes, such as
+// expected 401 and 404 responses.
+func WithSuppressCodes(codes ...int) Option {
+	suppressed := make(map[int]bool, len(codes))
+	for _, code := range codes {
+		suppressed[code] = true
+	}
+	return WithLogFilter(func(code int, _ string) bool {
+		return !suppressed[code]
+	})
+}
+
+// shouldLog 判断错误是否通过所有日志过滤器
+func (o *options) shouldLog(appErr *errors.Error) bool {
+	for _, filter := range o.logFilters {
+		if !filter(int(appErr.Code), appErr.Reason) {
+			return false
+		}
+	}
+	return true
+}
+
+// logError 记录错误，附带 error_id、code、reason 等结构化字段
+func (o *options) logError(ctx context.Context, msg string, appErr *errors.Error, err error, keyvals ...any) {
+	if !o.shouldLog(appErr) {
+		return
+	}
+	fields := make([]any, 0, 8+len(keyvals))
+	fields = append(fields, "error_id", appErr.GetID(), "code", appErr.Code, "reason", appErr.Reason)
+	fields = append(fields, keyvals...)
+	fields = append(fields, "error", err)
+	o.currentLogger().Log(ctx, o.logLevel(appErr), msg, fields...)
+}
+
+// WithLogLevelByReason chooses the log level of an error from its reason.
+// Keys are reason prefixes; when several match, the longest wins. Errors whose
+// reason matches no prefix are logged at the level matching errors.Severity.
+func WithLogLevelByReason(levels map[string]Level) Option {
+	return func(o *options) {
+		if o.levelByReason == nil {
+			o.levelByReason = make(map[string]Level, len(levels))
+		}
+		for prefix, level := range levels {
+			o.levelByReason[prefix] = level
+		}
+	}
+}
+
+// logLevel 按原因前缀选择日志级别，没有匹配时按错误的 Severity 决定
+func (o *options) logLevel(appErr *errors.Error) Level {
+	matched := -1
+	var level Level
+	for prefix, l := range o.levelByReason {
+		if len(prefix) > matched && strings.HasPrefix(appErr.Reason, prefix) {
+			matched, level = len(prefix), l
+		}
+	}
+	if matched >= 0 {
+		return level
+	}
+	switch errors.Severity(appErr) {
+	case errors.SeverityDebug:
+		return LevelDebug
+	case errors.SeverityInfo:
+		return LevelInfo
+	case errors.SeverityWarn:
+		return LevelWarn
+	case errors.SeverityCritical:
+		return LevelCritical
+	default:
+		return LevelError
+	}
+}
@@ -0,0 +1,163 @@
+package interceptor
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+)
+
+func TestWithLogLevelByReason(t *testing.T) {
+	var got Level
+	logger := LoggerFunc(func(_ context.Context, level Level, _ string, _ ...any) { got = level })
+
+	interceptor := UnaryServerErrorInterceptor(
+		WithLogger(logger),
+		WithLogLevelByReason(map[string]Level{
+			"AUTH_":            LevelWarn,
+			"STORAGE_":         LevelError,
+			"VALIDATION_":      LevelInfo,
+			"AUTH_TOKEN_DEBUG": LevelDebug,
+		}),
+	)
+
+	tests := []struct {
+		name string
+		err  error
+		want Level
+	}{
+		{"认证错误", errors.InternalServer("AUTH_PROVIDER_DOWN", "认证服务不可用"), LevelWarn},
+		{"存储错误", errors.BadRequest("STORAGE_QUOTA", "存储配额不足"), LevelError},
+		{"校验错误", errors.BadRequest("VALIDATION_EMAIL", "邮箱格式错误"), LevelInfo},
+		{"最长前缀优先", errors.Unauthorized("AUTH_TOKEN_DEBUG_ONLY", "调试"), LevelDebug},
+		{"默认4xx", errors.NotFound("USER_NOT_FOUND", "用户不存在"), LevelInfo},
+		{"默认408", errors.New(408, "REQUEST_TIMEOUT", "请求超时"), LevelWarn},
+		{"默认5xx", errors.InternalServer("PANIC", "内部错误"), LevelError},
+		{"显式Severity", errors.NotFound("CONFIG_MISSING", "配置缺失").WithSeverity(errors.SeverityCritical), LevelCritical},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _ = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/svc/Method"},
+				func(ctx context.Context, req interface{}) (interface{}, error) { return nil, tt.err })
+			if got != tt.want {
+				t.Errorf("日志级别应该是 %s，实际: %s", tt.want, got)
+			}
+		})
+	}
+}
+
+// captureLogger 记录最近一次日志调用
+type captureLogger struct {
+	ctx     context.Context
+	msg     string
+	keyvals map[string]any
+}
+
+func (l *captureLogger) Log(ctx context.Context, _ Level, msg string, keyvals ...any) {
+	l.ctx, l.msg = ctx, msg
+	l.keyvals = make(map[string]any)
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		l.keyvals[keyvals[i].(string)] = keyvals[i+1]
+	}
+}
+
+func TestSetLoggerStructuredFields(t *testing.T) {
+	logger := &captureLogger{}
+	SetLogger(logger)
+	t.Cleanup(func() { SetLogger(nil) })
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+	appErr := errors.NotFound("USER_NOT_FOUND", "用户不存在")
+
+	_, _ = UnaryServerErrorInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/user.v1.User/Get"},
+		func(ctx context.Context, req interface{}) (interface{}, error) { return nil, appErr })
+	if logger.ctx != ctx {
+		t.Error("gRPC拦截器应该把请求上下文传给日志实现")
+	}
+	if logger.keyvals["error_id"] != appErr.ID || logger.keyvals["reason"] != "USER_NOT_FOUND" ||
+		logger.keyvals["code"] != int32(404) || logger.keyvals["method"] != "/user.v1.User/Get" {
+		t.Errorf("日志应该包含结构化字段，实际: %v", logger.keyvals)
+	}
+
+	_, _ = NewErrorHandler()(ctx, appErr)
+	if logger.msg != "HTTP error" || logger.ctx != ctx || logger.keyvals["error_id"] != appErr.ID {
+		t.Errorf("HTTP错误处理器应该通过日志实现记录错误，实际: %s %v", logger.msg, logger.keyvals)
+	}
+
+	override := &captureLogger{}
+	_, _ = NewErrorHandler(WithLogger(override))(ctx, appErr)
+	if override.msg == "" {
+		t.Error("WithLogger应该覆盖SetLogger设置的日志实现")
+	}
+}
+
+func TestStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+	})
+
+	stdLogger{}.Log(context.Background(), LevelWarn, "gRPC unary error", "error_id", "abc", "code", 404)
+	if got, want := buf.String(), "[WARN] gRPC unary error error_id=abc code=404\n"; got != want {
+		t.Errorf("默认日志格式不正确\n期望: %q\n实际: %q", want, got)
+	}
+}
+
+func TestWithSuppressCodes(t *testing.T) {
+	logger := &captureLogger{}
+	opts := []Option{WithLogger(logger), WithSuppressCodes(401, 404)}
+	unary := UnaryServerErrorInterceptor(opts...)
+	stream := StreamServerErrorInterceptor(opts...)
+	info := &grpc.UnaryServerInfo{FullMethod: "/svc/Method"}
+
+	notFound := errors.NotFound("USER_NOT_FOUND", "用户不存在")
+	_, err := unary(context.Background(), nil, info,
+		func(ctx context.Context, req interface{}) (interface{}, error) { return nil, notFound })
+	if logger.msg != "" {
+		t.Errorf("404不应该被记录，实际: %s %v", logger.msg, logger.keyvals)
+	}
+	if errors.Reason(err) != "USER_NOT_FOUND" {
+		t.Errorf("被过滤的错误仍应该正常返回，实际: %v", err)
+	}
+
+	err = stream(nil, &fakeServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: "/svc/Stream"},
+		func(srv interface{}, ss grpc.ServerStream) error {
+			return errors.Unauthorized("TOKEN_EXPIRED", "令牌过期")
+		})
+	if logger.msg != "" || errors.Reason(err) != "TOKEN_EXPIRED" {
+		t.Errorf("流拦截器也应该过滤401日志，实际: %s", logger.msg)
+	}
+
+	_, _ = unary(context.Background(), nil, info,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, errors.InternalServer("DB", "数据库错误")
+		})
+	if logger.msg == "" {
+		t.Error("未被过滤的错误应该被记录")
+	}
+}
+
+func TestWithLogFilter(t *testing.T) {
+	logger := &captureLogger{}
+	handler := NewErrorHandler(WithLogger(logger), WithLogFilter(func(code int, reason string) bool {
+		return !strings.HasPrefix(reason, "VALIDATION_")
+	}))
+
+	code, _ := handler(context.Background(), errors.BadRequest("VALIDATION_EMAIL", "邮箱格式错误"))
+	if logger.msg != "" || code != 400 {
+		t.Errorf("过滤器返回false时不应该记录日志，实际: %s", logger.msg)
+	}
+	_, _ = handler(context.Background(), errors.BadRequest("BAD", "无效请求"))
+	if logger.msg == "" {
+		t.Error("过滤器返回true时应该记录日志")
+	}
+}
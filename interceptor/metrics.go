@@ -0,0 +1,62 @@
+package interceptor
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+)
+
+// MetricsObserver is called once for every error handled by the interceptors
+// and HTTP error handlers. method is the full gRPC method name, or the request
+// path for HTTP errors; it is empty when an HTTP error handler cannot find the
+// request in the context, see AcceptLanguageMiddleware. Reasons and paths are
+// passed through unchanged, so bounding the cardinality of the resulting
+// labels, e.g. by mapping path parameters to the route pattern, is the
+// observer's responsibility.
+type MetricsObserver func(code int, reason, method string)
+
+var defaultMetricsObserver atomic.Pointer[MetricsObserver]
+
+// SetMetricsObserver sets the observer notified of errors by interceptors and
+// handlers created without WithMetricsObserver, so that any metrics backend
+// can count errors by code, reason and method. See the errorsprom package for
+// a Prometheus counter. Passing nil removes the observer.
+func SetMetricsObserver(observer func(code int, reason, method string)) {
+	if observer == nil {
+		defaultMetricsObserver.Store(nil)
+		return
+	}
+	o := MetricsObserver(observer)
+	defaultMetricsObserver.Store(&o)
+}
+
+// WithMetricsObserver sets the observer notified of handled errors, overriding
+// the one set with SetMetricsObserver.
+func WithMetricsObserver(observer MetricsObserver) Option {
+	return func(o *options) {
+		o.metricsObserver = observer
+	}
+}
+
+// observe 将处理的错误通知给指标观察者，并在开启错误存储时保存脱敏前的完整错误
+func (o *options) observe(appErr *errors.Error, method string) {
+	errors.StoreError(appErr)
+	observer := o.metricsObserver
+	if observer == nil {
+		if p := defaultMetricsObserver.Load(); p != nil {
+			observer = *p
+		}
+	}
+	if observer != nil {
+		observer(int(appErr.Code), appErr.Reason, method)
+	}
+}
+
+// requestPath 返回上下文中保存的请求路径，用作HTTP错误的指标标签
+func requestPath(ctx context.Context) string {
+	if r := requestFromContext(ctx); r != nil {
+		return r.URL.Path
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+package interceptor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+)
+
+// observation 记录一次指标观察者调用
+type observation struct {
+	code   int
+	reason string
+	method string
+}
+
+func TestMetricsObserver(t *testing.T) {
+	var got []observation
+	SetMetricsObserver(func(code int, reason, method string) {
+		got = append(got, observation{code, reason, method})
+	})
+	t.Cleanup(func() { SetMetricsObserver(nil) })
+
+	unary := UnaryServerErrorInterceptor(WithLogger(nopLogger))
+	_, _ = unary(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/order.v1.Order/Get"},
+		func(context.Context, any) (any, error) {
+			return nil, errors.NotFound("ORDER_NOT_FOUND", "订单不存在")
+		})
+
+	var ctx context.Context
+	AcceptLanguageMiddleware(func(_ http.ResponseWriter, r *http.Request) { ctx = r.Context() })(
+		httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/A1", nil))
+	NewErrorHandler(WithLogger(nopLogger))(ctx, errors.Conflict("ORDER_LOCKED", "订单已锁定"))
+
+	want := []observation{
+		{404, "ORDER_NOT_FOUND", "/order.v1.Order/Get"},
+		{409, "ORDER_LOCKED", "/orders/A1"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("观察者应该被调用 %d 次，实际: %v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("第 %d 次观察应该是 %+v，实际: %+v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestWithMetricsObserver(t *testing.T) {
+	globalCalled := false
+	SetMetricsObserver(func(int, string, string) { globalCalled = true })
+	t.Cleanup(func() { SetMetricsObserver(nil) })
+
+	method := "unset"
+	handler := NewErrorHandler(WithLogger(nopLogger), WithMetricsObserver(func(_ int, _, m string) { method = m }))
+	handler(context.Background(), errors.InternalServer("DB_ERROR", "数据库错误"))
+
+	if globalCalled {
+		t.Error("WithMetricsObserver 应该覆盖全局观察者")
+	}
+	if method != "" {
+		t.Errorf("上下文中没有请求时 method 应该为空，实际: %q", method)
+	}
+}
+
+func TestErrorStoreInterceptor(t *testing.T) {
+	errors.SetErrorStoreSize(8)
+	t.Cleanup(func() { errors.SetErrorStoreSize(0) })
+
+	cause := errors.New(500, "DB_ERROR", "数据库错误")
+	unary := UnaryServerErrorInterceptor(WithLogger(nopLogger))
+	_, err := unary(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/order.v1.Order/Get"},
+		func(context.Context, any) (any, error) {
+			return nil, errors.NotFound("ORDER_NOT_FOUND", "订单不存在").WithCause(cause)
+		})
+
+	id := errors.FromError(err).ID
+	stored, ok := errors.LookupError(id)
+	if !ok {
+		t.Fatalf("拦截器处理的错误应该保存到错误存储，ID: %q", id)
+	}
+	if stored.Reason != "ORDER_NOT_FOUND" || errors.Unwrap(stored) != cause {
+		t.Errorf("保存的错误应该包含原因链，实际: %+v", stored)
+	}
+	if method, _ := errors.MetadataValue(stored, MetadataKeyGRPCMethod); method != "/order.v1.Order/Get" {
+		t.Errorf("保存的错误应该包含元数据，实际: %v", errors.Metadata(stored))
+	}
+}
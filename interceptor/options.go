@@ -0,0 +1,211 @@
+package interceptor
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/baggage"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors/errorsotel"
+)
+
+// Option configures the error interceptors and HTTP error handlers.
+type Option func(*options)
+
+type options struct {
+	baggageKeys      []string
+	logger           Logger
+	levelByReason    map[string]Level
+	logFilters       []func(code int, reason string) bool
+	serializeCauses  bool
+	recoverPanics    bool
+	formatter        ResponseFormatter
+	metadataFuncs    []func(ctx context.Context) map[string]string
+	traceCorrelation bool
+	releaseErrors    bool
+	metricsObserver  MetricsObserver
+	xmlNegotiation   bool
+	sanitizeServer   bool
+	envelopeCode     EnvelopeCodeMapper
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithBaggageKeys copies the given OpenTelemetry baggage members from the
+// request context into the error metadata, so request-scoped values such as
+// tenant or experiment flags travel with the error to clients and logs.
+// Keys already present in the error metadata are left untouched.
+func WithBaggageKeys(keys ...string) Option {
+	return func(o *options) {
+		o.baggageKeys = append(o.baggageKeys, keys...)
+	}
+}
+
+// WithCauseSerialization sends the cause chain of the error to clients. HTTP
+// response bodies get a "causes" array, from the outermost cause to the root,
+// holding the message of each layer and its code and reason when it is an
+// *errors.Error; gRPC statuses keep the chain errors.GRPCStatus attaches as
+// details, which errors.FromError rebuilds on the client. Both are capped at
+// the same depth. By default the chain, which often holds internal details
+// such as SQL errors or hostnames, is only logged and stripped from every
+// response, whatever its code. WithSanitizeServerErrors still strips it from
+// server errors.
+func WithCauseSerialization() Option {
+	return func(o *options) {
+		o.serializeCauses = true
+	}
+}
+
+// WithCauses is an alias of WithCauseSerialization.
+//
+// Deprecated: Use WithCauseSerialization, which also covers gRPC statuses.
+func WithCauses() Option {
+	return WithCauseSerialization()
+}
+
+// WithPanicRecovery makes UnaryServerErrorInterceptor recover from panics in
+// the handler. The panic is logged with its stack trace and returned to the
+// client as a 500 error with reason PanicReason. Recovery is disabled by
+// default.
+func WithPanicRecovery() Option {
+	return func(o *options) {
+		o.recoverPanics = true
+	}
+}
+
+// WithResponseFormatter renders HTTP error responses with formatter instead
+// of DefaultResponseFormatter. WithCauseSerialization has no effect on custom formatters.
+func WithResponseFormatter(formatter ResponseFormatter) Option {
+	return func(o *options) {
+		o.formatter = formatter
+	}
+}
+
+// WithMetadataFromContext merges the metadata returned by fn for the request
+// context into the error, e.g. trace, request or user IDs. Metadata already
+// present on the error wins on conflict.
+func WithMetadataFromContext(fn func(ctx context.Context) map[string]string) Option {
+	return func(o *options) {
+		if fn != nil {
+			o.metadataFuncs = append(o.metadataFuncs, fn)
+		}
+	}
+}
+
+// WithTraceCorrelation records handled errors on the OpenTelemetry span of the
+// request context and stamps the trace ID into the error metadata, see
+// errorsotel.RecordError.
+func WithTraceCorrelation() Option {
+	return func(o *options) {
+		o.traceCorrelation = true
+	}
+}
+
+// WithErrorRelease returns errors obtained from errors.AcquireError to the
+// pool with errors.ReleaseError once the gRPC status or default HTTP body has
+// been built. Only errors returned directly by the handler are released, not
+// ones wrapped by other errors. Handlers must not keep references to pooled
+// errors they return, and loggers must not retain the logged error after
+// Log returns. Responses rendered by a custom ResponseFormatter never release
+// the error, since the body may refer to it.
+func WithErrorRelease() Option {
+	return func(o *options) {
+		o.releaseErrors = true
+	}
+}
+
+// SanitizedMessage replaces the message of server errors sent to clients by
+// the gRPC server interceptors configured with WithSanitizeServerErrors.
+const SanitizedMessage = "internal error"
+
+// WithSanitizeServerErrors keeps the messages of 5xx errors, which may contain
+// SQL text or other internal details, away from clients of the gRPC server
+// interceptors. The error is logged unchanged; the status sent to the client
+// carries SanitizedMessage followed by the error ID instead of the message,
+// and no cause chain. Code, reason, ID and metadata are kept, as are the
+// messages of 4xx errors.
+func WithSanitizeServerErrors() Option {
+	return func(o *options) {
+		o.sanitizeServer = true
+	}
+}
+
+// outgoing 返回发给gRPC客户端的错误：未开启 WithCauseSerialization 时去掉cause链，
+// 开启 WithSanitizeServerErrors 时隐藏服务端错误的消息和cause链
+func (o *options) outgoing(appErr *errors.Error) *errors.Error {
+	if o.sanitizeServer && appErr.IsServerError() {
+		return appErr.WithMessage(SanitizedMessage + " (id: " + appErr.GetID() + ")").WithCause(nil)
+	}
+	if !o.serializeCauses && appErr.Unwrap() != nil {
+		return appErr.WithCause(nil)
+	}
+	return appErr
+}
+
+// release 在响应构建完成后将处理函数直接返回的池化错误归还对象池
+func (o *options) release(err error) {
+	if !o.releaseErrors {
+		return
+	}
+	// 只释放未被包装的错误，包装后的错误可能仍被其他代码引用
+	if e, ok := err.(*errors.Error); ok {
+		errors.ReleaseError(e)
+	}
+}
+
+// convert converts err into an *errors.Error and applies the configured
+// enrichments. The returned error never aliases the metadata of err.
+func (o *options) convert(ctx context.Context, err error) *errors.Error {
+	appErr := errors.FromContext(ctx, err)
+	if appErr == nil {
+		return nil
+	}
+	if ctx != nil {
+		for _, fn := range o.metadataFuncs {
+			appErr = appErr.WithDefaultMetadata(fn(ctx))
+		}
+	}
+	if md := o.baggageMetadata(ctx, appErr.Metadata); len(md) > 0 {
+		appErr = appErr.WithMetadata(md)
+	}
+	if o.traceCorrelation && ctx != nil {
+		appErr = errorsotel.RecordError(ctx, appErr)
+	}
+	return appErr
+}
+
+// baggageMetadata 将上下文中的baggage成员合并到metadata副本中，没有可合并的值时返回nil
+func (o *options) baggageMetadata(ctx context.Context, metadata map[string]string) map[string]string {
+	if len(o.baggageKeys) == 0 || ctx == nil {
+		return nil
+	}
+	bag := baggage.FromContext(ctx)
+	if bag.Len() == 0 {
+		return nil
+	}
+
+	var merged map[string]string
+	for _, key := range o.baggageKeys {
+		member := bag.Member(key)
+		if member.Key() == "" {
+			continue
+		}
+		if _, exists := metadata[key]; exists {
+			continue
+		}
+		if merged == nil {
+			merged = make(map[string]string, len(metadata)+len(o.baggageKeys))
+			for k, v := range metadata {
+				merged[k] = v
+			}
+		}
+		merged[key] = member.Value()
+	}
+	return merged
+}
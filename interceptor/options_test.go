@@ -0,0 +1,157 @@
+package interceptor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/baggage"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+)
+
+// contextWithBaggage 构造携带baggage的上下文
+func contextWithBaggage(t *testing.T, kv ...string) context.Context {
+	t.Helper()
+	var members []baggage.Member
+	for i := 0; i+1 < len(kv); i += 2 {
+		m, err := baggage.NewMember(kv[i], kv[i+1])
+		if err != nil {
+			t.Fatalf("创建baggage成员失败: %v", err)
+		}
+		members = append(members, m)
+	}
+	bag, err := baggage.New(members...)
+	if err != nil {
+		t.Fatalf("创建baggage失败: %v", err)
+	}
+	return baggage.ContextWithBaggage(context.Background(), bag)
+}
+
+func TestWithBaggageKeysUnary(t *testing.T) {
+	ctx := contextWithBaggage(t, "tenant", "acme", "experiment", "b", "ignored", "x")
+	origin := errors.NotFound("USER_NOT_FOUND", "用户不存在").
+		WithMetadata(map[string]string{"experiment": "from-error"})
+
+	interceptor := UnaryServerErrorInterceptor(WithBaggageKeys("tenant", "experiment", "missing"))
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/user.v1.User/Get"},
+		func(ctx context.Context, req interface{}) (interface{}, error) { return nil, origin })
+
+	appErr := errors.FromError(err)
+	if appErr.Metadata["tenant"] != "acme" {
+		t.Errorf("baggage中的tenant应该写入metadata，实际: %v", appErr.Metadata)
+	}
+	if appErr.Metadata["experiment"] != "from-error" {
+		t.Errorf("已有的metadata不应该被baggage覆盖，实际: %v", appErr.Metadata)
+	}
+	if _, ok := appErr.Metadata["ignored"]; ok {
+		t.Errorf("未选择的baggage不应该写入metadata，实际: %v", appErr.Metadata)
+	}
+	if _, ok := origin.Metadata["tenant"]; ok {
+		t.Error("不应该修改handler返回的原始错误")
+	}
+}
+
+func TestWithBaggageKeysHTTP(t *testing.T) {
+	handler := NewErrorHandler(WithBaggageKeys("tenant"))
+
+	code, body := handler(contextWithBaggage(t, "tenant", "acme"), errors.BadRequest("BAD", "无效请求"))
+	if code != 400 {
+		t.Errorf("HTTP状态码应该是400，实际: %d", code)
+	}
+	md, _ := body.(map[string]interface{})["metadata"].(map[string]string)
+	if md["tenant"] != "acme" {
+		t.Errorf("baggage中的tenant应该写入响应metadata，实际: %v", md)
+	}
+
+	// 没有baggage时不做任何处理
+	_, body = handler(context.Background(), errors.BadRequest("BAD", "无效请求"))
+	if md, _ := body.(map[string]interface{})["metadata"].(map[string]string); len(md) != 0 {
+		t.Errorf("没有baggage时metadata应该为空，实际: %v", md)
+	}
+}
+
+type traceIDKey struct{}
+
+// traceMetadata 从上下文中提取 trace_id
+func traceMetadata(ctx context.Context) map[string]string {
+	if id, ok := ctx.Value(traceIDKey{}).(string); ok {
+		return map[string]string{"trace_id": id, "user_id": "from-ctx"}
+	}
+	return nil
+}
+
+func TestWithMetadataFromContextGRPC(t *testing.T) {
+	ctx := context.WithValue(context.Background(), traceIDKey{}, "trace-1")
+	origin := errors.NotFound("USER_NOT_FOUND", "用户不存在").WithMetadata(map[string]string{"user_id": "42"})
+	opts := []Option{WithMetadataFromContext(traceMetadata), WithLogger(&captureLogger{})}
+
+	_, err := UnaryServerErrorInterceptor(opts...)(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/svc/Get"},
+		func(ctx context.Context, req interface{}) (interface{}, error) { return nil, origin })
+	md := errors.FromError(err).Metadata
+	if md["trace_id"] != "trace-1" || md["user_id"] != "42" {
+		t.Errorf("上下文中的值应该写入metadata且已有的值优先，实际: %v", md)
+	}
+
+	err = StreamServerErrorInterceptor(opts...)(nil, &fakeServerStream{ctx: ctx}, &grpc.StreamServerInfo{},
+		func(srv interface{}, ss grpc.ServerStream) error { return origin })
+	if md := errors.FromError(err).Metadata; md["trace_id"] != "trace-1" {
+		t.Errorf("流拦截器也应该写入上下文中的值，实际: %v", md)
+	}
+	if _, ok := origin.Metadata["trace_id"]; ok {
+		t.Error("不应该修改handler返回的原始错误")
+	}
+}
+
+func TestWithMetadataFromContextHTTP(t *testing.T) {
+	ctx := context.WithValue(context.Background(), traceIDKey{}, "trace-1")
+	opts := []Option{WithMetadataFromContext(traceMetadata), WithLogger(&captureLogger{})}
+
+	_, body := NewErrorHandler(opts...)(ctx, errors.BadRequest("BAD", "无效请求"))
+	if md, _ := body.(map[string]interface{})["metadata"].(map[string]string); md["trace_id"] != "trace-1" {
+		t.Errorf("HTTP错误处理器应该写入上下文中的值，实际: %v", body)
+	}
+
+	handler := HTTPErrorMiddlewareWith(opts...)(func(w http.ResponseWriter, r *http.Request) {
+		panic(errors.InternalServer("PANIC", "崩溃"))
+	})
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+
+	var got struct {
+		Metadata map[string]string `json:"metadata"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("响应不是有效的JSON: %v", err)
+	}
+	if got.Metadata["trace_id"] != "trace-1" {
+		t.Errorf("HTTP中间件应该写入上下文中的值，实际: %s", w.Body.String())
+	}
+}
+
+func TestWithTraceCorrelation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+	ctx, span := provider.Tracer("test").Start(context.Background(), "/svc/Get")
+
+	_, err := UnaryServerErrorInterceptor(WithTraceCorrelation(), WithLogger(&captureLogger{}))(ctx, nil,
+		&grpc.UnaryServerInfo{FullMethod: "/svc/Get"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, errors.InternalServer("DB_ERROR", "数据库错误")
+		})
+	span.End()
+
+	if got, want := errors.FromError(err).Metadata[errors.MetadataKeyTraceID], span.SpanContext().TraceID().String(); got != want {
+		t.Errorf("错误metadata应该包含追踪ID %s，实际: %s", want, got)
+	}
+	if ended := recorder.Ended(); len(ended) != 1 || ended[0].Status().Code != otelcodes.Error {
+		t.Errorf("span应该被标记为失败，实际: %v", ended)
+	}
+}
@@ -0,0 +1,116 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+)
+
+// nopLogger 丢弃所有日志，避免基准测试和并发测试输出大量日志
+var nopLogger = LoggerFunc(func(context.Context, Level, string, ...any) {})
+
+var unaryInfo = &grpc.UnaryServerInfo{FullMethod: "/svc/Pooled"}
+
+func TestUnaryServerErrorRelease(t *testing.T) {
+	interceptor := UnaryServerErrorInterceptor(WithErrorRelease(), WithLogger(nopLogger))
+
+	pooled := errors.AcquireError(404, "NOT_FOUND", "资源未找到")
+	id := pooled.ID
+	_, err := interceptor(context.Background(), nil, unaryInfo,
+		func(context.Context, interface{}) (interface{}, error) { return nil, pooled })
+
+	got := errors.FromError(err)
+	if got.Reason != "NOT_FOUND" || got.ID != id {
+		t.Errorf("释放前应该已经构建好gRPC状态，实际: %v", got)
+	}
+	if pooled.Reason != "" || pooled.ID != "" {
+		t.Errorf("处理函数直接返回的池化错误应该被释放，实际: %+v", pooled.Status)
+	}
+
+	// 被包装的错误可能仍被引用，不应该释放
+	wrapped := errors.AcquireError(404, "NOT_FOUND", "资源未找到")
+	_, _ = interceptor(context.Background(), nil, unaryInfo,
+		func(context.Context, interface{}) (interface{}, error) { return nil, fmt.Errorf("wrap: %w", wrapped) })
+	if wrapped.Reason != "NOT_FOUND" {
+		t.Errorf("被包装的池化错误不应该被释放，实际: %+v", wrapped.Status)
+	}
+
+	// 未开启选项时不释放
+	kept := errors.AcquireError(404, "NOT_FOUND", "资源未找到")
+	_, _ = UnaryServerErrorInterceptor(WithLogger(nopLogger))(context.Background(), nil, unaryInfo,
+		func(context.Context, interface{}) (interface{}, error) { return nil, kept })
+	if kept.Reason != "NOT_FOUND" {
+		t.Errorf("未开启WithErrorRelease时不应该释放，实际: %+v", kept.Status)
+	}
+}
+
+func TestHTTPErrorRelease(t *testing.T) {
+	pooled := errors.AcquireError(409, "CONFLICT", "冲突")
+	pooled.Metadata = map[string]string{"k": "v"}
+	code, body := NewErrorHandler(WithErrorRelease(), WithLogger(nopLogger))(context.Background(), pooled)
+
+	m := body.(map[string]interface{})
+	if code != 409 || m["reason"] != "CONFLICT" || m["metadata"].(map[string]string)["k"] != "v" {
+		t.Errorf("响应体应该在释放前构建完成，实际: %d %v", code, body)
+	}
+	if pooled.Reason != "" {
+		t.Errorf("默认格式下池化错误应该被释放，实际: %+v", pooled.Status)
+	}
+
+	custom := errors.AcquireError(409, "CONFLICT", "冲突")
+	NewErrorHandler(WithErrorRelease(), WithLogger(nopLogger),
+		WithResponseFormatter(func(e *errors.Error) (int, any) { return int(e.Code), e }))(context.Background(), custom)
+	if custom.Reason != "CONFLICT" {
+		t.Errorf("自定义格式的响应体可能引用错误，不应该释放，实际: %+v", custom.Status)
+	}
+}
+
+func TestUnaryServerErrorReleaseConcurrent(t *testing.T) {
+	interceptor := UnaryServerErrorInterceptor(WithErrorRelease(), WithLogger(nopLogger))
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				reason := fmt.Sprintf("R_%d_%d", g, i)
+				_, err := interceptor(context.Background(), nil, unaryInfo,
+					func(context.Context, interface{}) (interface{}, error) {
+						return nil, errors.AcquireError(400, reason, "并发")
+					})
+				if got := errors.Reason(err); got != reason {
+					t.Errorf("返回的错误应该属于当前请求 %s，实际: %s", reason, got)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+func BenchmarkUnaryServerErrorNew(b *testing.B) {
+	interceptor := UnaryServerErrorInterceptor(WithLogger(nopLogger))
+	handler := func(context.Context, interface{}) (interface{}, error) {
+		return nil, errors.New(400, "BENCH", "基准测试错误")
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = interceptor(context.Background(), nil, unaryInfo, handler)
+	}
+}
+
+func BenchmarkUnaryServerErrorPooled(b *testing.B) {
+	interceptor := UnaryServerErrorInterceptor(WithErrorRelease(), WithLogger(nopLogger))
+	handler := func(context.Context, interface{}) (interface{}, error) {
+		return nil, errors.AcquireError(400, "BENCH", "基准测试错误")
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = interceptor(context.Background(), nil, unaryInfo, handler)
+	}
+}
@@ -0,0 +1,88 @@
+package interceptor
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+)
+
+// ProblemContentType is the media type of RFC 7807 Problem Details responses.
+const ProblemContentType = "application/problem+json"
+
+// ProblemTypePrefix is prepended to the kebab-cased reason of an error to form
+// the "type" member of its Problem Details. Errors without a reason use
+// "about:blank", as recommended by RFC 7807.
+var ProblemTypePrefix = "urn:problem-type:"
+
+// ProblemDetails is the RFC 7807 representation of an error. Instance carries
+// the error ID; Reason and Metadata are extension members.
+type ProblemDetails struct {
+	Type     string            `json:"type"`
+	Title    string            `json:"title"`
+	Status   int               `json:"status"`
+	Detail   string            `json:"detail,omitempty"`
+	Instance string            `json:"instance,omitempty"`
+	Reason   string            `json:"reason,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// ProblemJSONHandler is an alternative to ErrorResponseHandler that renders
+// errors as RFC 7807 Problem Details. Use SetProblemJSONHandler together with
+// ProblemJSONMiddleware to also send the application/problem+json content type.
+func ProblemJSONHandler(err error) (int, interface{}) {
+	o := newOptions([]Option{WithResponseFormatter(ProblemJSONFormatter)})
+	return o.errorResponse(context.Background(), errors.FromError(err), err)
+}
+
+// ProblemJSONFormatter is a ResponseFormatter that renders an error as
+// ProblemDetails.
+func ProblemJSONFormatter(appErr *errors.Error) (int, any) {
+	code := appErr.StatusCode()
+	problemType := "about:blank"
+	if appErr.Reason != "" {
+		problemType = ProblemTypePrefix + strings.ToLower(strings.ReplaceAll(appErr.Reason, "_", "-"))
+	}
+	title := http.StatusText(code)
+	if title == "" {
+		title = appErr.Reason
+	}
+	return code, ProblemDetails{
+		Type:     problemType,
+		Title:    title,
+		Status:   code,
+		Detail:   appErr.Message,
+		Instance: appErr.GetID(),
+		Reason:   appErr.Reason,
+		Metadata: appErr.RedactedMetadata(),
+	}
+}
+
+// SetProblemJSONHandler sets a go-zero error handler that renders errors as
+// RFC 7807 Problem Details. go-zero always writes error bodies as
+// application/json, so register ProblemJSONMiddleware as well to send the
+// application/problem+json content type.
+func SetProblemJSONHandler(opts ...Option) {
+	SetErrorHandlerWith(ProblemJSONFormatter, opts...)
+}
+
+// ProblemJSONMiddleware rewrites the content type of JSON error responses
+// (status 400 and above) to application/problem+json.
+func ProblemJSONMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(&problemResponseWriter{ResponseWriter: w}, r)
+	}
+}
+
+// problemResponseWriter 在写入错误状态码时将JSON的Content-Type改写为 application/problem+json
+type problemResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w *problemResponseWriter) WriteHeader(code int) {
+	if code >= http.StatusBadRequest && strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		w.Header().Set("Content-Type", ProblemContentType)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
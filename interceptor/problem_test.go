@@ -0,0 +1,66 @@
+package interceptor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+)
+
+func TestProblemJSONHandler(t *testing.T) {
+	appErr := errors.NotFound("USER_NOT_FOUND", "用户不存在").WithMetadata(map[string]string{"user_id": "42"})
+
+	code, body := ProblemJSONHandler(appErr)
+	if code != 404 {
+		t.Errorf("HTTP状态码应该是404，实际: %d", code)
+	}
+	want := ProblemDetails{
+		Type:     "urn:problem-type:user-not-found",
+		Title:    "Not Found",
+		Status:   404,
+		Detail:   "用户不存在",
+		Instance: appErr.ID,
+		Reason:   "USER_NOT_FOUND",
+	}
+	got, ok := body.(ProblemDetails)
+	if !ok {
+		t.Fatalf("响应体应该是ProblemDetails，实际: %T", body)
+	}
+	if got.Type != want.Type || got.Title != want.Title || got.Status != want.Status ||
+		got.Detail != want.Detail || got.Instance != want.Instance || got.Reason != want.Reason {
+		t.Errorf("字段映射不正确\n期望: %+v\n实际: %+v", want, got)
+	}
+	if got.Metadata["user_id"] != "42" {
+		t.Errorf("metadata应该作为扩展字段输出，实际: %v", got.Metadata)
+	}
+
+	if _, body = ProblemJSONHandler(errors.New(500, "", "boom")); body.(ProblemDetails).Type != "about:blank" {
+		t.Errorf("没有原因时type应该是about:blank，实际: %v", body)
+	}
+}
+
+func TestProblemJSONContentType(t *testing.T) {
+	SetProblemJSONHandler()
+	t.Cleanup(func() { httpx.SetErrorHandlerCtx(nil) })
+
+	handler := ProblemJSONMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		httpx.ErrorCtx(r.Context(), w, errors.Forbidden("ACCESS_DENIED", "无权访问"))
+	})
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+
+	if ct := w.Header().Get("Content-Type"); ct != ProblemContentType {
+		t.Errorf("Content-Type应该是%s，实际: %s", ProblemContentType, ct)
+	}
+	var got ProblemDetails
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("响应不是有效的JSON: %v", err)
+	}
+	if got.Status != 403 || got.Type != "urn:problem-type:access-denied" || got.Instance == "" {
+		t.Errorf("响应字段不正确，实际: %+v", got)
+	}
+}
@@ -0,0 +1,83 @@
+package interceptor
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+)
+
+// exchangeKey 请求上下文中保存 HTTP 请求和响应的键
+type exchangeKey struct{}
+
+// exchange 保存到请求上下文中的 HTTP 请求和响应
+type exchange struct {
+	r *http.Request
+	w http.ResponseWriter
+}
+
+// AcceptLanguageMiddleware stores the request and response writer in the
+// request context, so that the error handlers, which only receive the
+// context, can localize errors by the Accept-Language header with the
+// messages registered via errors.RegisterMessages, label metrics with the
+// request path, see SetMetricsObserver, and set the error ID header, see
+// SetErrorIDHeader. HTTPErrorMiddleware does the same for the errors it
+// handles.
+func AcceptLanguageMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, withRequest(w, r))
+	}
+}
+
+// withRequest 将请求和响应保存到上下文中
+func withRequest(w http.ResponseWriter, r *http.Request) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), exchangeKey{}, &exchange{r: r, w: w}))
+}
+
+// exchangeFromContext 返回 withRequest 保存的请求和响应，没有时返回nil
+func exchangeFromContext(ctx context.Context) *exchange {
+	if ctx == nil {
+		return nil
+	}
+	ex, _ := ctx.Value(exchangeKey{}).(*exchange)
+	return ex
+}
+
+// requestFromContext 返回 withRequest 保存的请求，没有时返回nil
+func requestFromContext(ctx context.Context) *http.Request {
+	if ex := exchangeFromContext(ctx); ex != nil {
+		return ex.r
+	}
+	return nil
+}
+
+// DefaultErrorIDHeader is the response header that carries the error ID
+// unless changed with SetErrorIDHeader.
+const DefaultErrorIDHeader = "X-Error-Id"
+
+// errorIDHeader 写入错误ID的响应头名称，nil 表示 DefaultErrorIDHeader
+var errorIDHeader atomic.Pointer[string]
+
+// SetErrorIDHeader sets the response header in which the HTTP error handlers
+// and middlewares write the error ID, so that it shows up in browser
+// developer tools and proxy logs even when the body is not. An empty name
+// stops writing the header. Handlers registered with SetDefaultErrorHandler
+// only receive the context, so they can only set the header on routes wrapped
+// by AcceptLanguageMiddleware or HTTPErrorMiddleware.
+func SetErrorIDHeader(name string) {
+	errorIDHeader.Store(&name)
+}
+
+// currentErrorIDHeader 返回当前的错误ID响应头名称
+func currentErrorIDHeader() string {
+	if name := errorIDHeader.Load(); name != nil {
+		return *name
+	}
+	return DefaultErrorIDHeader
+}
+
+// setErrorIDHeader 在写入状态码之前将错误ID写入响应头
+func setErrorIDHeader(w http.ResponseWriter, id string) {
+	if name := currentErrorIDHeader(); name != "" && id != "" {
+		w.Header().Set(name, id)
+	}
+}
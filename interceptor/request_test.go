@@ -0,0 +1,73 @@
+package interceptor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+)
+
+// bodyID 返回JSON响应体中的错误ID
+func bodyID(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var got struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("响应不是有效的JSON: %v", err)
+	}
+	return got.ID
+}
+
+func TestErrorIDHeader(t *testing.T) {
+	SetDefaultErrorHandler(WithLogger(nopLogger))
+	t.Cleanup(func() { httpx.SetErrorHandlerCtx(nil) })
+
+	handlers := map[string]http.HandlerFunc{
+		"HTTPErrorMiddlewareE": HTTPErrorMiddlewareEWith(WithLogger(nopLogger))(func(http.ResponseWriter, *http.Request) error {
+			return errors.NotFound("USER_NOT_FOUND", "用户不存在")
+		}),
+		"HTTPErrorMiddleware": HTTPErrorMiddlewareWith(WithLogger(nopLogger))(func(http.ResponseWriter, *http.Request) {
+			panic(errors.InternalServer("PANIC", "崩溃"))
+		}),
+		"SetDefaultErrorHandler": AcceptLanguageMiddleware(func(w http.ResponseWriter, r *http.Request) {
+			httpx.ErrorCtx(r.Context(), w, errors.NotFound("USER_NOT_FOUND", "用户不存在"))
+		}),
+	}
+	for name, handler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler(w, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+
+			header := w.Header().Get(DefaultErrorIDHeader)
+			if header == "" || header != bodyID(t, w) {
+				t.Errorf("响应头中的错误ID应该与响应体一致，实际: %q, 响应体: %s", header, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestSetErrorIDHeader(t *testing.T) {
+	handler := HTTPErrorMiddlewareEWith(WithLogger(nopLogger))(func(http.ResponseWriter, *http.Request) error {
+		return errors.BadRequest("INVALID", "参数错误")
+	})
+
+	SetErrorIDHeader("X-Request-Error")
+	t.Cleanup(func() { SetErrorIDHeader(DefaultErrorIDHeader) })
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Header().Get("X-Request-Error") != bodyID(t, w) || w.Header().Get(DefaultErrorIDHeader) != "" {
+		t.Errorf("应该使用自定义的响应头，实际: %v", w.Header())
+	}
+
+	SetErrorIDHeader("")
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Header().Get("X-Request-Error") != "" || w.Header().Get(DefaultErrorIDHeader) != "" {
+		t.Errorf("响应头名称为空时不应该写入错误ID，实际: %v", w.Header())
+	}
+}
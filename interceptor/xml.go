@@ -0,0 +1,179 @@
+package interceptor
+
+import (
+	"encoding/xml"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+)
+
+// XMLContentType is the content type of XML error responses.
+const XMLContentType = "application/xml; charset=utf-8"
+
+// XMLError is the XML representation of an error:
+//
+//	<error>
+//	  <code>404</code>
+//	  <reason>USER_NOT_FOUND</reason>
+//	  <message>user not found</message>
+//	  <id>djI6...</id>
+//	  <metadata>
+//	    <entry key="user_id">42</entry>
+//	  </metadata>
+//	</error>
+//
+// Metadata entries are sorted by key and redacted metadata keys are scrubbed.
+type XMLError struct {
+	XMLName  xml.Name           `xml:"error"`
+	Code     int32              `xml:"code"`
+	Reason   string             `xml:"reason"`
+	Message  string             `xml:"message"`
+	ID       string             `xml:"id"`
+	Metadata []XMLMetadataEntry `xml:"metadata>entry,omitempty"`
+}
+
+// XMLMetadataEntry is a metadata key and value of an XMLError.
+type XMLMetadataEntry struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// XMLErrorResponse is a ResponseFormatter that renders an error as XMLError.
+// go-zero writes the bodies returned by error handlers as JSON, so use it
+// through WithXMLNegotiation rather than WithResponseFormatter.
+func XMLErrorResponse(appErr *errors.Error) (int, any) {
+	body := &XMLError{
+		Code:    appErr.Code,
+		Reason:  appErr.Reason,
+		Message: appErr.Message,
+		ID:      appErr.GetID(),
+	}
+	metadata := appErr.RedactedMetadata()
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		body.Metadata = append(body.Metadata, XMLMetadataEntry{Key: k, Value: metadata[k]})
+	}
+	return appErr.StatusCode(), body
+}
+
+// WithXMLNegotiation renders the error as XMLErrorResponse instead of JSON
+// when the Accept header of the request prefers application/xml or text/xml
+// over application/json. The configured ResponseFormatter only applies to
+// JSON responses. HTTPErrorMiddlewareWith negotiates on its own; the handlers
+// registered with SetDefaultErrorHandler only receive the context and need the
+// route to be wrapped by XMLErrorMiddleware. Note that browsers prefer XML in
+// their default Accept header.
+func WithXMLNegotiation() Option {
+	return func(o *options) {
+		o.xmlNegotiation = true
+	}
+}
+
+// SetXMLErrorHandler is like SetDefaultErrorHandler with WithXMLNegotiation,
+// so that clients asking for XML get XML error responses. Register
+// XMLErrorMiddleware as well.
+func SetXMLErrorHandler(opts ...Option) {
+	SetDefaultErrorHandler(append(opts, WithXMLNegotiation())...)
+}
+
+// XMLErrorMiddleware does what AcceptLanguageMiddleware does and, when the
+// client prefers XML, lets the handler registered with SetXMLErrorHandler
+// write the error response as XML.
+func XMLErrorMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if prefersXML(r) {
+			w = &xmlResponseWriter{ResponseWriter: w}
+		}
+		next.ServeHTTP(w, withRequest(w, r))
+	}
+}
+
+// xmlResponseWriter 在 go-zero 写入状态码时输出错误处理器准备好的XML响应体
+type xmlResponseWriter struct {
+	http.ResponseWriter
+	body []byte
+}
+
+func (w *xmlResponseWriter) WriteHeader(code int) {
+	if w.body == nil {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	body := w.body
+	w.body = nil
+	w.Header().Set("Content-Type", XMLContentType)
+	w.ResponseWriter.WriteHeader(code)
+	_, _ = w.ResponseWriter.Write(body)
+}
+
+// Unwrap 供 http.ResponseController 访问底层的 ResponseWriter
+func (w *xmlResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// findXMLResponseWriter 沿 Unwrap 链查找 XMLErrorMiddleware 包装的 ResponseWriter
+func findXMLResponseWriter(w http.ResponseWriter) *xmlResponseWriter {
+	for {
+		if xw, ok := w.(*xmlResponseWriter); ok {
+			return xw
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return nil
+		}
+		w = u.Unwrap()
+	}
+}
+
+// marshalXML 将错误渲染为带XML声明的响应体
+func marshalXML(appErr *errors.Error) (int, []byte) {
+	code, body := XMLErrorResponse(appErr)
+	data, err := xml.Marshal(body)
+	if err != nil {
+		// XMLError 只包含字符串和整数，不会序列化失败
+		return http.StatusInternalServerError, []byte(xml.Header)
+	}
+	return code, append([]byte(xml.Header), data...)
+}
+
+// writeXML 直接写入XML错误响应
+func writeXML(w http.ResponseWriter, appErr *errors.Error) {
+	code, body := marshalXML(appErr)
+	w.Header().Set("Content-Type", XMLContentType)
+	w.WriteHeader(code)
+	_, _ = w.Write(body)
+}
+
+// prefersXML 判断 Accept 请求头中XML的权重是否高于JSON
+func prefersXML(r *http.Request) bool {
+	header := r.Header.Get("Accept")
+	if header == "" {
+		return false
+	}
+	var xmlQ, jsonQ float64
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		switch strings.ToLower(strings.TrimSpace(mediaType)) {
+		case "application/xml", "text/xml":
+			xmlQ = max(xmlQ, q)
+		case "application/json":
+			jsonQ = max(jsonQ, q)
+		}
+	}
+	return xmlQ > 0 && xmlQ > jsonQ
+}
@@ -0,0 +1,81 @@
+package interceptor
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	"github.com/honeybbq/protoc-gen-go-zero-errors/errors"
+)
+
+func TestPrefersXML(t *testing.T) {
+	tests := map[string]bool{
+		"":                                  false,
+		"application/json":                  false,
+		"application/xml":                   true,
+		"text/xml":                          true,
+		"application/json, application/xml": false,
+		"application/json;q=0.5, application/xml":                         true,
+		"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8": true,
+		"application/xml;q=0": false,
+	}
+	for header, want := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", header)
+		if got := prefersXML(r); got != want {
+			t.Errorf("Accept %q 应该返回 %v，实际: %v", header, want, got)
+		}
+	}
+}
+
+func TestXMLNegotiation(t *testing.T) {
+	SetXMLErrorHandler(WithLogger(nopLogger))
+	t.Cleanup(func() { httpx.SetErrorHandlerCtx(nil) })
+
+	appErr := errors.NotFound("USER_NOT_FOUND", "用户不存在").WithMetadata(map[string]string{"user_id": "42"})
+	handlers := map[string]http.HandlerFunc{
+		"SetXMLErrorHandler": XMLErrorMiddleware(func(w http.ResponseWriter, r *http.Request) {
+			httpx.ErrorCtx(r.Context(), w, appErr)
+		}),
+		"HTTPErrorMiddlewareE": HTTPErrorMiddlewareEWith(WithXMLNegotiation(), WithLogger(nopLogger))(
+			func(http.ResponseWriter, *http.Request) error { return appErr }),
+	}
+	for name, handler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+			r.Header.Set("Accept", "application/xml")
+			w := httptest.NewRecorder()
+			handler(w, r)
+
+			if w.Code != http.StatusNotFound || w.Header().Get("Content-Type") != XMLContentType {
+				t.Fatalf("应该返回404的XML响应，实际: %d %s", w.Code, w.Header().Get("Content-Type"))
+			}
+			var got XMLError
+			if err := xml.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("响应不是有效的XML: %v\n%s", err, w.Body.String())
+			}
+			want := []XMLMetadataEntry{{Key: "user_id", Value: "42"}}
+			if got.Code != 404 || got.Reason != "USER_NOT_FOUND" || got.ID == "" ||
+				len(got.Metadata) != 1 || got.Metadata[0] != want[0] {
+				t.Errorf("XML响应体不正确: %s", w.Body.String())
+			}
+			if !strings.HasPrefix(w.Body.String(), xml.Header) {
+				t.Errorf("XML响应应该包含XML声明: %s", w.Body.String())
+			}
+
+			r = httptest.NewRequest(http.MethodGet, "/users/42", nil)
+			r.Header.Set("Accept", "application/json")
+			w = httptest.NewRecorder()
+			handler(w, r)
+			var body map[string]any
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body["reason"] != "USER_NOT_FOUND" {
+				t.Errorf("请求JSON时应该返回JSON响应，实际: %s", w.Body.String())
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"flag"
 	"fmt"
+	"go/token"
+	"strings"
 
 	"google.golang.org/protobuf/compiler/protogen"
 	"google.golang.org/protobuf/types/pluginpb"
@@ -19,19 +21,80 @@ func main() {
 		return
 	}
 
-	var flags flag.FlagSet
+	var opts options
 	protogen.Options{
-		ParamFunc: flags.Set,
+		ParamFunc: opts.paramFunc(),
 	}.Run(func(gen *protogen.Plugin) error {
-		gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
-		for _, f := range gen.Files {
-			if !f.Generate {
-				continue
+		return generate(gen, &opts)
+	})
+}
+
+// options holds the plugin parameters, e.g. --go-zero-errors_opt=strip_enum_prefix=true.
+// protogen itself handles paths, module and M parameters.
+type options struct {
+	stripEnumPrefix bool
+	errorsSuffix    string
+	packageSuffix   string
+	genWiring       bool
+}
+
+// flagSet returns the flag set used to parse the plugin parameters into o
+func (o *options) flagSet() *flag.FlagSet {
+	var flags flag.FlagSet
+	flags.BoolVar(&o.stripEnumPrefix, "strip_enum_prefix", false,
+		"strip the enum type name prefix (e.g. USER_ERROR_ for enum UserError) from value names")
+	flags.StringVar(&o.errorsSuffix, "errors_suffix", "_errors.pb.go",
+		"suffix of the generated file names, which must end with .go")
+	flags.StringVar(&o.packageSuffix, "package_suffix", "",
+		"generate into a sub-package named after the Go package plus this suffix, e.g. userv1errors")
+	flags.BoolVar(&o.genWiring, "gen_wiring", false,
+		"also generate errors_wiring.pb.go with helpers registering the error interceptors on go-zero servers")
+	return &flags
+}
+
+// paramFunc returns the protogen.Options.ParamFunc parsing the plugin
+// parameters into o, rejecting unknown names and invalid values
+func (o *options) paramFunc() func(name, value string) error {
+	flags := o.flagSet()
+	return func(name, value string) error {
+		if flags.Lookup(name) == nil {
+			var known []string
+			flags.VisitAll(func(f *flag.Flag) { known = append(known, f.Name) })
+			return fmt.Errorf("unknown parameter %q, supported parameters: %s, paths, module, M",
+				name, strings.Join(known, ", "))
+		}
+		if err := flags.Set(name, value); err != nil {
+			return fmt.Errorf("invalid value %q for parameter %s: %w", value, name, err)
+		}
+
+		switch name {
+		case "errors_suffix":
+			if !strings.HasSuffix(o.errorsSuffix, ".go") {
+				return fmt.Errorf("invalid value %q for parameter errors_suffix: must end with .go", value)
+			}
+		case "package_suffix":
+			if value != "" && !token.IsIdentifier(o.packageSuffix) {
+				return fmt.Errorf("invalid value %q for parameter package_suffix: must be a valid Go identifier", value)
 			}
-			generateFile(gen, f)
 		}
 		return nil
-	})
+	}
+}
+
+// generate generates the errors code for every file to generate
+func generate(gen *protogen.Plugin, opts *options) error {
+	gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+	if err := checkDuplicateReasons(gen.Files, opts); err != nil {
+		return err
+	}
+	registries := make(map[protogen.GoImportPath]bool)
+	for _, f := range gen.Files {
+		if !f.Generate {
+			continue
+		}
+		generateFile(gen, f, opts, registries)
+	}
+	return nil
 }
 
 const release = "v1.0.0"
@@ -0,0 +1,360 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+var update = flag.Bool("update", false, "更新testdata中的golden文件")
+
+// buildRequest 编译testdata中的proto文件，构造与protoc发送给插件相同的请求
+func buildRequest(t *testing.T, parameter string, files ...string) *pluginpb.CodeGeneratorRequest {
+	t.Helper()
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			ImportPaths: []string{"testdata", "proto"},
+		}),
+		SourceInfoMode: protocompile.SourceInfoStandard,
+	}
+	compiled, err := compiler.Compile(context.Background(), files...)
+	if err != nil {
+		t.Fatalf("编译proto失败: %v", err)
+	}
+
+	// 依赖必须排在被依赖的文件之前
+	var protoFiles []*descriptorpb.FileDescriptorProto
+	seen := make(map[string]bool)
+	var add func(fd protoreflect.FileDescriptor)
+	add = func(fd protoreflect.FileDescriptor) {
+		if seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+		for i := 0; i < fd.Imports().Len(); i++ {
+			add(fd.Imports().Get(i).FileDescriptor)
+		}
+		protoFiles = append(protoFiles, protodesc.ToFileDescriptorProto(fd))
+	}
+	for _, fd := range compiled {
+		add(fd)
+	}
+
+	req := &pluginpb.CodeGeneratorRequest{FileToGenerate: files, ProtoFile: protoFiles}
+	if parameter != "" {
+		req.Parameter = proto.String(parameter)
+	}
+
+	// 与protoc一样经过序列化，让扩展选项按已注册的类型解析
+	data, err := proto.Marshal(req)
+	if err != nil {
+		t.Fatalf("序列化请求失败: %v", err)
+	}
+	req = &pluginpb.CodeGeneratorRequest{}
+	if err := proto.Unmarshal(data, req); err != nil {
+		t.Fatalf("反序列化请求失败: %v", err)
+	}
+	return req
+}
+
+// runPlugin 对请求运行插件，返回生成的文件内容
+func runPlugin(t *testing.T, req *pluginpb.CodeGeneratorRequest) map[string]string {
+	t.Helper()
+	var opts options
+	gen, err := protogen.Options{ParamFunc: opts.paramFunc()}.New(req)
+	if err != nil {
+		t.Fatalf("创建插件失败: %v", err)
+	}
+	if err := generate(gen, &opts); err != nil {
+		t.Fatalf("生成代码失败: %v", err)
+	}
+	resp := gen.Response()
+	if resp.Error != nil {
+		t.Fatalf("插件返回错误: %s", resp.GetError())
+	}
+
+	out := make(map[string]string)
+	for _, f := range resp.File {
+		out[f.GetName()] = f.GetContent()
+	}
+	return out
+}
+
+// assertGolden 将生成的内容与testdata/golden中的文件比较，-update 时写入
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("读取golden文件失败 (使用 -update 生成): %v", err)
+	}
+	if !bytes.Equal(want, []byte(got)) {
+		t.Errorf("生成的代码与 %s 不一致 (使用 -update 更新)\n实际:\n%s", path, got)
+	}
+}
+
+func TestGenerateGolden(t *testing.T) {
+	files := runPlugin(t, buildRequest(t, "", "user.proto"))
+
+	got, ok := files["example.com/testdata/user/user_errors.pb.go"]
+	if !ok {
+		t.Fatalf("应该生成 user_errors.pb.go，实际: %v", files)
+	}
+	assertGolden(t, "user_errors.pb.go", got)
+}
+
+func TestGenerateGoldenEnumPrefix(t *testing.T) {
+	tests := []struct {
+		name      string
+		parameter string
+		golden    string
+	}{
+		{"保留前缀", "", "order_errors.pb.go"},
+		{"去掉前缀", "strip_enum_prefix=true", "order_errors_stripped.pb.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := runPlugin(t, buildRequest(t, tt.parameter, "order.proto"))
+			got, ok := files["example.com/testdata/order/order_errors.pb.go"]
+			if !ok {
+				t.Fatalf("应该生成 order_errors.pb.go，实际: %v", files)
+			}
+			assertGolden(t, tt.golden, got)
+		})
+	}
+}
+
+func TestGenerateRegistry(t *testing.T) {
+	files := runPlugin(t, buildRequest(t, "", "user.proto", "session.proto"))
+	user := files["example.com/testdata/user/user_errors.pb.go"]
+	session := files["example.com/testdata/user/session_errors.pb.go"]
+
+	// 每个值都以正确的状态码和默认消息加入 Registry
+	for got, wants := range map[*string][]string{
+		&user: {
+			`Registry[UserNotFound] = errors.Define(404, UserNotFound, "用户不存在")`,
+			`Registry[UserAlreadyExists] = errors.Define(409, UserAlreadyExists, "用户已存在")`,
+			`Registry[DatabaseUnavailable] = errors.Define(500, DatabaseUnavailable, DatabaseUnavailable)`,
+		},
+		&session: {
+			`Registry[SessionExpired] = errors.Define(401, SessionExpired, "会话已过期")`,
+			// 多行注释合并为一行，模板与 Registry 使用相同的消息
+			`Registry[SessionRevoked] = errors.Define(401, SessionRevoked, "会话已被撤销， 需要重新登录")`,
+			`Message:  "会话已被撤销， 需要重新登录",`,
+			`Category: "testdata.user.SessionError",`,
+		},
+	} {
+		for _, want := range wants {
+			if !strings.Contains(*got, want) {
+				t.Errorf("生成的代码应该包含 %q", want)
+			}
+		}
+	}
+
+	// 同一个Go包只声明一次 Registry 和 Lookup
+	declared := strings.Count(user+session, "var Registry = ")
+	lookups := strings.Count(user+session, "func Lookup(")
+	if declared != 1 || lookups != 1 {
+		t.Errorf("同一个包应该只声明一次 Registry 和 Lookup，实际: %d %d", declared, lookups)
+	}
+}
+
+func TestGenerateWiring(t *testing.T) {
+	files := runPlugin(t, buildRequest(t, "gen_wiring=true", "user.proto", "session.proto", "order.proto"))
+
+	got, ok := files["example.com/testdata/user/errors_wiring.pb.go"]
+	if !ok {
+		t.Fatalf("应该生成 errors_wiring.pb.go，实际: %v", files)
+	}
+	assertGolden(t, "errors_wiring.pb.go", got)
+
+	// 每个Go包只生成一个注册函数文件
+	var wiring []string
+	for name := range files {
+		if strings.HasSuffix(name, "/"+wiringFilename) {
+			wiring = append(wiring, name)
+		}
+	}
+	if len(wiring) != 2 {
+		t.Errorf("user 和 order 两个包应该各生成一个注册函数文件，实际: %v", wiring)
+	}
+
+	for name := range runPlugin(t, buildRequest(t, "", "user.proto")) {
+		if strings.HasSuffix(name, wiringFilename) {
+			t.Errorf("默认不应该生成注册函数文件，实际: %s", name)
+		}
+	}
+}
+
+func TestExplicitReason(t *testing.T) {
+	got := runPlugin(t, buildRequest(t, "", "order.proto"))["example.com/testdata/order/order_errors.pb.go"]
+	for _, want := range []string{
+		// 没有 (errors.reason) 时原因为枚举值名称
+		`OrderErrorNotFound += "ORDER_ERROR_NOT_FOUND"`,
+		// 显式指定的原因优先，Go标识符仍由枚举值名称生成
+		`OrderErrorCanceled += "ORDER_CANCELLED"`,
+		`func ErrorOrderErrorCanceled\(`,
+	} {
+		if !regexp.MustCompile(want).MatchString(got) {
+			t.Errorf("生成的代码应该包含 %q", want)
+		}
+	}
+}
+
+func TestDuplicateReasons(t *testing.T) {
+	// 不去掉前缀时原因各不相同
+	runPlugin(t, buildRequest(t, "", "duplicate.proto"))
+
+	var opts options
+	gen, err := protogen.Options{ParamFunc: opts.paramFunc()}.New(buildRequest(t, "strip_enum_prefix=true", "duplicate.proto"))
+	if err != nil {
+		t.Fatalf("创建插件失败: %v", err)
+	}
+	err = generate(gen, &opts)
+	want := `duplicate reason "NOT_FOUND": testdata.duplicate.ORDER_ERROR_NOT_FOUND (duplicate.proto:10:3), ` +
+		`testdata.duplicate.PAYMENT_ERROR_NOT_FOUND (duplicate.proto:16:3)`
+	if err == nil || err.Error() != want {
+		t.Errorf("重复的原因应该返回列出冲突值及位置的错误，实际: %v", err)
+	}
+}
+
+func TestDuplicateGoNames(t *testing.T) {
+	// 不去掉前缀时Go名称各不相同
+	runPlugin(t, buildRequest(t, "", "goname.proto"))
+
+	var opts options
+	gen, err := protogen.Options{ParamFunc: opts.paramFunc()}.New(buildRequest(t, "strip_enum_prefix=true", "goname.proto"))
+	if err != nil {
+		t.Fatalf("创建插件失败: %v", err)
+	}
+	err = generate(gen, &opts)
+	want := `duplicate Go name "NotFound": testdata.goname.ORDER_ERROR_NOT_FOUND (goname.proto:10:3), ` +
+		`testdata.goname.PAYMENT_ERROR_NOT_FOUND (goname.proto:15:3)`
+	if err == nil || err.Error() != want {
+		t.Errorf("原因不同但Go名称重复时应该返回列出冲突值及位置的错误，实际: %v", err)
+	}
+}
+
+func TestUpperSnakeCase(t *testing.T) {
+	for in, want := range map[string]string{
+		"UserError":  "USER_ERROR",
+		"HTTPError":  "HTTP_ERROR",
+		"Error":      "ERROR",
+		"OrderV2Err": "ORDER_V2_ERR",
+	} {
+		if got := upperSnakeCase(in); got != want {
+			t.Errorf("upperSnakeCase(%q) 应该是 %q，实际: %q", in, want, got)
+		}
+	}
+}
+
+func TestParseParameters(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string]string
+		want    options
+		wantErr string
+	}{
+		{"默认值", nil, options{errorsSuffix: "_errors.pb.go"}, ""},
+		{"自定义后缀", map[string]string{"errors_suffix": ".zerror.go", "package_suffix": "errors", "strip_enum_prefix": "true"},
+			options{stripEnumPrefix: true, errorsSuffix: ".zerror.go", packageSuffix: "errors"}, ""},
+		{"生成注册函数", map[string]string{"gen_wiring": "true"}, options{errorsSuffix: "_errors.pb.go", genWiring: true}, ""},
+		{"未知参数", map[string]string{"error_suffix": ".go"}, options{}, `unknown parameter "error_suffix"`},
+		{"后缀不是.go", map[string]string{"errors_suffix": ".txt"}, options{}, "must end with .go"},
+		{"包后缀不是标识符", map[string]string{"package_suffix": "my-errors"}, options{}, "must be a valid Go identifier"},
+		{"布尔值无效", map[string]string{"strip_enum_prefix": "yes"}, options{}, "invalid value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts options
+			set := opts.paramFunc()
+			var err error
+			for name, value := range tt.params {
+				if err = set(name, value); err != nil {
+					break
+				}
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("应该返回包含 %q 的错误，实际: %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("不应该返回错误，实际: %v", err)
+			}
+			if opts != tt.want {
+				t.Errorf("参数解析结果应该是 %+v，实际: %+v", tt.want, opts)
+			}
+		})
+	}
+}
+
+func TestGenerateFileLayout(t *testing.T) {
+	tests := []struct {
+		name      string
+		parameter string
+		filename  string
+		pkg       string
+	}{
+		{"默认", "", "example.com/testdata/user/user_errors.pb.go", "package user"},
+		{"相对路径与后缀", "paths=source_relative,errors_suffix=.zerror.go", "user.zerror.go", "package user"},
+		{"子包", "package_suffix=errors", "example.com/testdata/user/usererrors/user_errors.pb.go", "package usererrors"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := runPlugin(t, buildRequest(t, tt.parameter, "user.proto"))
+			got, ok := files[tt.filename]
+			if !ok {
+				t.Fatalf("应该生成 %s，实际: %v", tt.filename, files)
+			}
+			if !strings.Contains(got, "\n"+tt.pkg+"\n") {
+				t.Errorf("生成的代码应该声明 %q", tt.pkg)
+			}
+		})
+	}
+}
+
+func TestUnknownParameter(t *testing.T) {
+	var opts options
+	_, err := protogen.Options{ParamFunc: opts.paramFunc()}.New(buildRequest(t, "strip_prefix=true", "user.proto"))
+	if err == nil || !strings.Contains(err.Error(), `unknown parameter "strip_prefix"`) {
+		t.Errorf("未知参数应该返回明确的错误，实际: %v", err)
+	}
+}
+
+func TestGenerateDefaultMessage(t *testing.T) {
+	got := runPlugin(t, buildRequest(t, "", "user.proto"))["example.com/testdata/user/user_errors.pb.go"]
+	for _, want := range []string{
+		// 有注释的值使用注释作为默认消息
+		`return errors.New(404, UserNotFound, "用户不存在")`,
+		// 没有注释的值回退到原因字符串
+		`return errors.New(500, DatabaseUnavailable, DatabaseUnavailable)`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("生成的代码应该包含 %q", want)
+		}
+	}
+}
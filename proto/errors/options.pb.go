@@ -30,6 +30,14 @@ var file_options_proto_extTypes = []protoimpl.ExtensionInfo{
 		Tag:           "varint,1109,opt,name=code",
 		Filename:      "options.proto",
 	},
+	{
+		ExtendedType:  (*descriptorpb.EnumValueOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         1110,
+		Name:          "errors.reason",
+		Tag:           "bytes,1110,opt,name=reason",
+		Filename:      "options.proto",
+	},
 	{
 		ExtendedType:  (*descriptorpb.EnumOptions)(nil),
 		ExtensionType: (*int32)(nil),
@@ -44,12 +52,14 @@ var file_options_proto_extTypes = []protoimpl.ExtensionInfo{
 var (
 	// optional int32 code = 1109;
 	E_Code = &file_options_proto_extTypes[0]
+	// optional string reason = 1110;
+	E_Reason = &file_options_proto_extTypes[1]
 )
 
 // Extension fields to descriptorpb.EnumOptions.
 var (
 	// optional int32 default_code = 1108;
-	E_DefaultCode = &file_options_proto_extTypes[1]
+	E_DefaultCode = &file_options_proto_extTypes[2]
 )
 
 var File_options_proto protoreflect.FileDescriptor
@@ -57,7 +67,8 @@ var File_options_proto protoreflect.FileDescriptor
 const file_options_proto_rawDesc = "" +
 	"\n" +
 	"\roptions.proto\x12\x06errors\x1a google/protobuf/descriptor.proto:6\n" +
-	"\x04code\x12!.google.protobuf.EnumValueOptions\x18\xd5\b \x01(\x05R\x04code:@\n" +
+	"\x04code\x12!.google.protobuf.EnumValueOptions\x18\xd5\b \x01(\x05R\x04code::\n" +
+	"\x06reason\x12!.google.protobuf.EnumValueOptions\x18\xd6\b \x01(\tR\x06reason:@\n" +
 	"\fdefault_code\x12\x1c.google.protobuf.EnumOptions\x18\xd4\b \x01(\x05R\vdefaultCodeB:Z8github.com/honeybbq/go-zero-errors-proto/errors;errorspbb\x06proto3"
 
 var file_options_proto_goTypes = []any{
@@ -66,11 +77,12 @@ var file_options_proto_goTypes = []any{
 }
 var file_options_proto_depIdxs = []int32{
 	0, // 0: errors.code:extendee -> google.protobuf.EnumValueOptions
-	1, // 1: errors.default_code:extendee -> google.protobuf.EnumOptions
-	2, // [2:2] is the sub-list for method output_type
-	2, // [2:2] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	0, // [0:2] is the sub-list for extension extendee
+	0, // 1: errors.reason:extendee -> google.protobuf.EnumValueOptions
+	1, // 2: errors.default_code:extendee -> google.protobuf.EnumOptions
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	0, // [0:3] is the sub-list for extension extendee
 	0, // [0:0] is the sub-list for field type_name
 }
 
@@ -86,7 +98,7 @@ func file_options_proto_init() {
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_options_proto_rawDesc), len(file_options_proto_rawDesc)),
 			NumEnums:      0,
 			NumMessages:   0,
-			NumExtensions: 2,
+			NumExtensions: 3,
 			NumServices:   0,
 		},
 		GoTypes:           file_options_proto_goTypes,